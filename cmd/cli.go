@@ -0,0 +1,283 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gwenziro/bot-notify/internal/config"
+	"github.com/gwenziro/bot-notify/internal/service/whatsapp/client"
+	"github.com/gwenziro/bot-notify/internal/storage"
+	"github.com/gwenziro/bot-notify/internal/utils"
+)
+
+const (
+	// probeTimeout adalah batas waktu memeriksa instance yang sedang berjalan
+	probeTimeout = 2 * time.Second
+
+	// loginTimeout adalah batas waktu menunggu login WhatsApp dalam mode langsung
+	loginTimeout = 30 * time.Second
+)
+
+// cliOptions berisi flag yang dipakai bersama oleh subcommand CLI
+type cliOptions struct {
+	loader  *config.Loader
+	remote  string
+	token   string
+	direct  bool
+	account string
+}
+
+// newFlagSet membuat FlagSet subcommand beserta flag konfigurasi dan mode koneksi
+func newFlagSet(name string) (*flag.FlagSet, *cliOptions) {
+	fs := flag.NewFlagSet(name, flag.ExitOnError)
+	opts := &cliOptions{loader: config.NewLoader()}
+	opts.loader.BindFlags(fs)
+	fs.StringVar(&opts.remote, "remote", "", "URL instance yang sedang berjalan (default: server.host dan server.port dari konfigurasi)")
+	fs.StringVar(&opts.token, "token", "", "Token akses atau API key untuk --remote (default: auth.access_token)")
+	fs.BoolVar(&opts.direct, "direct", false, "Buka storage dan sesi WhatsApp langsung tanpa melalui instance yang berjalan")
+	return fs, opts
+}
+
+// bindAccount menambahkan flag --account untuk subcommand yang bekerja pada satu akun WhatsApp
+func (o *cliOptions) bindAccount(fs *flag.FlagSet) {
+	fs.StringVar(&o.account, "account", "", "Nama akun WhatsApp (default: akun default)")
+}
+
+// accountPath membuat path API untuk akun tertentu, atau path lama untuk akun default
+func accountPath(account, path string) string {
+	if account == "" {
+		return "/api/" + path
+	}
+	return "/api/accounts/" + account + "/" + path
+}
+
+// cliEnv adalah target subcommand: API instance yang berjalan, atau storage lokal jika api nil
+type cliEnv struct {
+	cfg *config.Config
+	api *apiClient
+}
+
+// open memuat konfigurasi lalu menentukan apakah subcommand memakai API atau storage langsung
+func (o *cliOptions) open() (*cliEnv, error) {
+	// Log aplikasi hanya ditampilkan jika ada masalah agar output CLI tetap bersih
+	if err := utils.Setup(&utils.LogConfig{Level: "warn"}); err != nil {
+		return nil, fmt.Errorf("gagal inisialisasi logger: %w", err)
+	}
+	if err := utils.EnsureProjectStructure(); err != nil {
+		return nil, fmt.Errorf("gagal membuat struktur direktori: %w", err)
+	}
+
+	cfg, err := o.loader.Load()
+	if err != nil {
+		return nil, fmt.Errorf("gagal memuat konfigurasi: %w", err)
+	}
+
+	env := &cliEnv{cfg: cfg}
+	if o.direct {
+		return env, nil
+	}
+
+	token := o.token
+	if token == "" {
+		token = cfg.Auth.AccessToken
+	}
+
+	api := newAPIClient(o.remote, token)
+	if o.remote == "" {
+		api.baseURL = localURL(cfg)
+	}
+
+	if err := api.ping(); err != nil {
+		// URL yang diberikan eksplisit harus dapat dihubungi
+		if o.remote != "" {
+			return nil, fmt.Errorf("instance di %s tidak dapat dihubungi: %w", o.remote, err)
+		}
+		return env, nil
+	}
+
+	env.api = api
+	return env, nil
+}
+
+// localURL membuat URL instance lokal dari konfigurasi server
+func localURL(cfg *config.Config) string {
+	host := cfg.Server.Host
+	if host == "" || host == "0.0.0.0" || host == "::" {
+		host = "127.0.0.1"
+	}
+	return "http://" + net.JoinHostPort(host, strconv.Itoa(cfg.Server.Port))
+}
+
+// localWhatsApp adalah klien satu akun WhatsApp yang dibuka langsung dari storage lokal
+type localWhatsApp struct {
+	*client.Client
+	store    storage.Storage
+	accounts *client.AccountManager
+}
+
+// Close menutup semua klien, device store, dan storage
+func (l *localWhatsApp) Close() {
+	l.accounts.Close()
+	l.store.Close()
+}
+
+// connectWhatsApp membuat klien WhatsApp dari sesi tersimpan dan menunggu sampai login
+func connectWhatsApp(cfg *config.Config, account string) (*localWhatsApp, error) {
+	wa, err := openWhatsApp(cfg, account)
+	if err != nil {
+		return nil, err
+	}
+
+	device, err := wa.SessionManager.GetDevice()
+	if err != nil {
+		wa.Close()
+		return nil, err
+	}
+	if device.ID == nil {
+		wa.Close()
+		return nil, errors.New("perangkat WhatsApp belum dipasangkan, jalankan subcommand pair terlebih dahulu")
+	}
+
+	if err := wa.Connect(); err != nil {
+		wa.Close()
+		return nil, err
+	}
+
+	if err := waitLoggedIn(wa.Client, loginTimeout); err != nil {
+		wa.Disconnect()
+		wa.Close()
+		return nil, err
+	}
+
+	return wa, nil
+}
+
+// openWhatsApp membuat klien akun WhatsApp dari storage dan device store lokal tanpa terhubung
+func openWhatsApp(cfg *config.Config, account string) (*localWhatsApp, error) {
+	store, err := storage.Initialize(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("gagal membuka storage: %w", err)
+	}
+
+	// Perintah CLI tidak me-reload konfigurasi, cukup bungkus konfigurasi yang dimuat
+	accounts, err := client.NewAccountManager(config.NewLive(cfg), store)
+	if err != nil {
+		store.Close()
+		return nil, fmt.Errorf("gagal inisialisasi WhatsApp client: %w", err)
+	}
+
+	whatsClient, err := accounts.Get(account)
+	if err != nil {
+		accounts.Close()
+		store.Close()
+		return nil, err
+	}
+
+	return &localWhatsApp{Client: whatsClient, store: store, accounts: accounts}, nil
+}
+
+// waitLoggedIn menunggu sampai klien WhatsApp login ke server
+func waitLoggedIn(whatsClient *client.Client, timeout time.Duration) error {
+	deadline := time.Now().Add(timeout)
+	for time.Now().Before(deadline) {
+		if wa := whatsClient.GetWhatsmeowClient(); wa != nil && wa.IsLoggedIn() {
+			return nil
+		}
+		time.Sleep(500 * time.Millisecond)
+	}
+	return fmt.Errorf("WhatsApp belum login setelah %s", timeout)
+}
+
+// apiClient memanggil API instance bot-notify yang sedang berjalan
+type apiClient struct {
+	baseURL string
+	token   string
+	http    *http.Client
+}
+
+// newAPIClient membuat instance baru apiClient
+func newAPIClient(baseURL, token string) *apiClient {
+	return &apiClient{
+		baseURL: strings.TrimRight(baseURL, "/"),
+		token:   token,
+		http:    &http.Client{Timeout: 60 * time.Second},
+	}
+}
+
+// ping memeriksa apakah instance dapat dihubungi
+func (c *apiClient) ping() error {
+	probe := &http.Client{Timeout: probeTimeout}
+	resp, err := probe.Get(c.baseURL + "/ping")
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("status HTTP %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// do mengirim request ke API dan men-decode respons JSON ke out
+func (c *apiClient) do(method, path string, body, out interface{}) error {
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return fmt.Errorf("gagal encode request: %w", err)
+		}
+		reader = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequest(method, c.baseURL+path, reader)
+	if err != nil {
+		return fmt.Errorf("gagal membuat request: %w", err)
+	}
+	req.Header.Set("X-Access-Token", c.token)
+	req.Header.Set("Accept", "application/json")
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return fmt.Errorf("gagal menghubungi %s: %w", c.baseURL, err)
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("gagal membaca respons: %w", err)
+	}
+
+	if resp.StatusCode >= http.StatusBadRequest {
+		var apiErr struct {
+			Message string `json:"pesan"`
+			Error   string `json:"error"`
+		}
+		if json.Unmarshal(data, &apiErr) == nil && apiErr.Message != "" {
+			if apiErr.Error != "" {
+				return fmt.Errorf("%s: %s (HTTP %d)", apiErr.Message, apiErr.Error, resp.StatusCode)
+			}
+			return fmt.Errorf("%s (HTTP %d)", apiErr.Message, resp.StatusCode)
+		}
+		return fmt.Errorf("request gagal dengan status HTTP %d", resp.StatusCode)
+	}
+
+	if out != nil {
+		if err := json.Unmarshal(data, out); err != nil {
+			return fmt.Errorf("gagal membaca respons: %w", err)
+		}
+	}
+	return nil
+}
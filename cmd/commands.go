@@ -0,0 +1,476 @@
+package main
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/gwenziro/bot-notify/internal/api/model"
+	"github.com/gwenziro/bot-notify/internal/service/whatsapp/client"
+	"github.com/gwenziro/bot-notify/internal/service/whatsapp/session"
+	"github.com/gwenziro/bot-notify/internal/storage"
+)
+
+// runSend mengirim pesan ke nomor personal atau grup
+func runSend(args []string) error {
+	fs, opts := newFlagSet("send")
+	to := fs.String("to", "", "Nomor tujuan, misal 08123456789")
+	group := fs.String("group", "", "ID grup tujuan")
+	message := fs.String("message", "", "Isi pesan")
+	opts.bindAccount(fs)
+	fs.Parse(args)
+
+	if (*to == "") == (*group == "") {
+		return errors.New("isi salah satu dari --to atau --group")
+	}
+	if strings.TrimSpace(*message) == "" {
+		return errors.New("--message harus diisi")
+	}
+
+	env, err := opts.open()
+	if err != nil {
+		return err
+	}
+
+	if env.api != nil {
+		var resp model.QueuedMessageResponse
+		if *to != "" {
+			err = env.api.do("POST", "/api/send/personal", model.PersonalMessageRequest{
+				PhoneNumber: *to,
+				Message:     *message,
+				Account:     opts.account,
+			}, &resp)
+		} else {
+			err = env.api.do("POST", "/api/send/group", model.GroupMessageRequest{
+				GroupID: *group,
+				Message: *message,
+				Account: opts.account,
+			}, &resp)
+		}
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Pesan ke %s masuk antrean (id %s, status %s)\n", resp.Recipient, resp.ID, resp.Status)
+		return nil
+	}
+
+	whatsClient, err := connectWhatsApp(env.cfg, opts.account)
+	if err != nil {
+		return err
+	}
+	defer whatsClient.Close()
+	defer whatsClient.Disconnect()
+
+	recipient := client.ParsePhoneNumber(*to)
+	if *group != "" {
+		recipient = client.ParseGroupID(*group)
+	}
+
+	id, err := whatsClient.SendMessage(recipient, *message)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Pesan terkirim ke %s (id %s)\n", recipient, id)
+	return nil
+}
+
+// runPair memasangkan perangkat WhatsApp dengan QR code di terminal, atau dengan
+// kode pairing jika --phone diisi
+func runPair(args []string) error {
+	fs, opts := newFlagSet("pair")
+	phone := fs.String("phone", "", "Nomor WhatsApp yang ditautkan dengan kode pairing, bukan QR code")
+	timeout := fs.Duration("timeout", 2*time.Minute, "Batas waktu menunggu QR code dipindai atau kode dimasukkan")
+	opts.bindAccount(fs)
+	fs.Parse(args)
+
+	env, err := opts.open()
+	if err != nil {
+		return err
+	}
+
+	if env.api != nil {
+		if *phone != "" {
+			return pairPhoneRemote(env.api, opts.account, *phone, *timeout)
+		}
+		return pairRemote(env.api, opts.account, *timeout)
+	}
+
+	whatsClient, err := openWhatsApp(env.cfg, opts.account)
+	if err != nil {
+		return err
+	}
+	defer whatsClient.Close()
+
+	device, err := whatsClient.SessionManager.GetDevice()
+	if err != nil {
+		return err
+	}
+	if device.ID != nil {
+		fmt.Printf("Perangkat sudah dipasangkan sebagai %s. Jalankan logout untuk memasangkan ulang.\n", device.ID)
+		return nil
+	}
+
+	if *phone != "" {
+		code, err := whatsClient.PairPhone(*phone)
+		if err != nil {
+			return err
+		}
+		defer whatsClient.Disconnect()
+		printPairingCode(code)
+	} else {
+		// QR handler menampilkan QR code di terminal setiap kali diterima
+		fmt.Println("Pindai QR code berikut dari WhatsApp > Perangkat tertaut > Tautkan perangkat")
+
+		if err := whatsClient.Connect(); err != nil {
+			return err
+		}
+		defer whatsClient.Disconnect()
+	}
+
+	if err := waitLoggedIn(whatsClient.Client, *timeout); err != nil {
+		return err
+	}
+
+	fmt.Printf("Berhasil dipasangkan sebagai %s\n", whatsClient.GetWhatsmeowClient().Store.ID)
+	return nil
+}
+
+// qrStatusResponse adalah respons endpoint /api/qr/status dan /api/accounts/:name/qr/status
+type qrStatusResponse struct {
+	Available bool   `json:"available"`
+	LoggedIn  bool   `json:"loggedIn"`
+	Code      string `json:"code"`
+}
+
+// pairRemote menampilkan QR code dari instance yang berjalan sampai perangkat dipasangkan
+func pairRemote(api *apiClient, account string, timeout time.Duration) error {
+	var status qrStatusResponse
+	if err := api.do("GET", accountPath(account, "qr/status"), nil, &status); err != nil {
+		return err
+	}
+	if status.LoggedIn {
+		fmt.Println("Perangkat sudah dipasangkan. Jalankan logout untuk memasangkan ulang.")
+		return nil
+	}
+
+	// Minta instance membuat QR code baru jika belum ada yang berlaku
+	if !status.Available {
+		if err := api.do("POST", accountPath(account, "reconnect"), nil, nil); err != nil {
+			return err
+		}
+	}
+
+	fmt.Println("Pindai QR code berikut dari WhatsApp > Perangkat tertaut > Tautkan perangkat")
+
+	lastCode := ""
+	deadline := time.Now().Add(timeout)
+	for time.Now().Before(deadline) {
+		if err := api.do("GET", accountPath(account, "qr/status"), nil, &status); err != nil {
+			return err
+		}
+		if status.LoggedIn {
+			fmt.Println("Berhasil dipasangkan")
+			return nil
+		}
+		if status.Code != "" && status.Code != lastCode {
+			session.PrintQRToTerminal(status.Code)
+			lastCode = status.Code
+		}
+		time.Sleep(2 * time.Second)
+	}
+
+	return fmt.Errorf("QR code tidak dipindai dalam %s", timeout)
+}
+
+// pairPhoneRemote meminta kode pairing dari instance yang berjalan dan menunggu sampai dipasangkan
+func pairPhoneRemote(api *apiClient, account, phone string, timeout time.Duration) error {
+	var resp model.PairPhoneResponse
+	if err := api.do("POST", accountPath(account, "pair/phone"), model.PairPhoneRequest{PhoneNumber: phone}, &resp); err != nil {
+		return err
+	}
+	printPairingCode(resp.Code)
+
+	deadline := time.Now().Add(timeout)
+	for time.Now().Before(deadline) {
+		var status qrStatusResponse
+		if err := api.do("GET", accountPath(account, "qr/status"), nil, &status); err != nil {
+			return err
+		}
+		if status.LoggedIn {
+			fmt.Println("Berhasil dipasangkan")
+			return nil
+		}
+		time.Sleep(2 * time.Second)
+	}
+
+	return fmt.Errorf("kode pairing tidak dimasukkan dalam %s", timeout)
+}
+
+// printPairingCode menampilkan kode pairing beserta cara memasukkannya
+func printPairingCode(code string) {
+	fmt.Printf("\n    Kode pairing: %s\n\n", code)
+	fmt.Println("Masukkan kode di WhatsApp > Perangkat tertaut > Tautkan perangkat > Tautkan dengan nomor telepon")
+}
+
+// runLogout memutus perangkat WhatsApp dan menghapus sesi yang tersimpan
+func runLogout(args []string) error {
+	fs, opts := newFlagSet("logout")
+	opts.bindAccount(fs)
+	fs.Parse(args)
+
+	env, err := opts.open()
+	if err != nil {
+		return err
+	}
+
+	if env.api != nil {
+		var resp model.ConnectionResponse
+		if err := env.api.do("POST", accountPath(opts.account, "disconnect"), nil, &resp); err != nil {
+			return err
+		}
+		fmt.Println(resp.Message)
+		return nil
+	}
+
+	whatsClient, err := openWhatsApp(env.cfg, opts.account)
+	if err != nil {
+		return err
+	}
+	defer whatsClient.Close()
+
+	if err := whatsClient.SessionManager.ClearSessions(); err != nil {
+		return err
+	}
+	fmt.Println("Sesi WhatsApp dihapus")
+	return nil
+}
+
+// runStatus menampilkan status koneksi dan sesi WhatsApp
+func runStatus(args []string) error {
+	fs, opts := newFlagSet("status")
+	opts.bindAccount(fs)
+	fs.Parse(args)
+
+	env, err := opts.open()
+	if err != nil {
+		return err
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	defer w.Flush()
+
+	if env.api != nil {
+		var resp model.StatusResponse
+		if err := env.api.do("GET", accountPath(opts.account, "status"), nil, &resp); err != nil {
+			return err
+		}
+		fmt.Fprintf(w, "Instance:\tberjalan di %s\n", env.api.baseURL)
+		fmt.Fprintf(w, "Akun:\t%s\n", resp.Account)
+		fmt.Fprintf(w, "Status:\t%s\n", resp.Status)
+		fmt.Fprintf(w, "Terhubung:\t%t\n", resp.Details.IsConnected)
+		fmt.Fprintf(w, "Percobaan koneksi:\t%d\n", resp.Details.ConnectionRetries)
+		fmt.Fprintf(w, "Aktivitas terakhir:\t%s\n", resp.Details.LastActivity.Format(time.RFC3339))
+		return nil
+	}
+
+	whatsClient, err := openWhatsApp(env.cfg, opts.account)
+	if err != nil {
+		return err
+	}
+	defer whatsClient.Close()
+
+	device, err := whatsClient.SessionManager.GetDevice()
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(w, "Instance:\ttidak berjalan\n")
+	fmt.Fprintf(w, "Akun:\t%s\n", whatsClient.Name())
+	fmt.Fprintf(w, "Direktori sesi:\t%s\n", env.cfg.WhatsApp.StoreDir)
+	if device.ID == nil {
+		fmt.Fprintf(w, "Sesi:\tbelum dipasangkan\n")
+		return nil
+	}
+	fmt.Fprintf(w, "Sesi:\tdipasangkan\n")
+	fmt.Fprintf(w, "Perangkat:\t%s\n", device.ID)
+	if device.PushName != "" {
+		fmt.Fprintf(w, "Nama:\t%s\n", device.PushName)
+	}
+	return nil
+}
+
+// runGroups menjalankan subcommand grup
+func runGroups(args []string) error {
+	if len(args) == 0 || args[0] != "list" {
+		return errors.New("penggunaan: groups list [flag]")
+	}
+
+	fs, opts := newFlagSet("groups list")
+	opts.bindAccount(fs)
+	fs.Parse(args[1:])
+
+	env, err := opts.open()
+	if err != nil {
+		return err
+	}
+
+	var groups []model.GroupInfo
+	if env.api != nil {
+		var resp model.GroupListResponse
+		if err := env.api.do("GET", accountPath(opts.account, "groups"), nil, &resp); err != nil {
+			return err
+		}
+		groups = resp.Groups
+	} else {
+		whatsClient, err := connectWhatsApp(env.cfg, opts.account)
+		if err != nil {
+			return err
+		}
+		defer whatsClient.Close()
+		defer whatsClient.Disconnect()
+
+		infos, err := whatsClient.GetGroups()
+		if err != nil {
+			return err
+		}
+		for _, info := range infos {
+			groups = append(groups, model.GroupInfo{
+				ID:          info.JID.String(),
+				Name:        info.Name,
+				MemberCount: len(info.Participants),
+			})
+		}
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	defer w.Flush()
+
+	fmt.Fprintln(w, "ID\tNAMA\tANGGOTA")
+	for _, group := range groups {
+		fmt.Fprintf(w, "%s\t%s\t%d\n", group.ID, group.Name, group.MemberCount)
+	}
+	return nil
+}
+
+// runBackup menulis backup storage dan sesi WhatsApp ke file tar.gz
+func runBackup(args []string) error {
+	fs, opts := newFlagSet("backup")
+	out := fs.String("out", "", "File tujuan backup (default: bot-notify-backup-<waktu>.tar.gz)")
+	fs.Parse(args)
+
+	env, err := opts.open()
+	if err != nil {
+		return err
+	}
+
+	// Storage dikunci oleh instance yang berjalan, backup hanya bisa dilakukan secara langsung
+	if env.api != nil {
+		return fmt.Errorf("instance sedang berjalan di %s, hentikan server sebelum membuat backup", env.api.baseURL)
+	}
+
+	path := *out
+	if path == "" {
+		path = fmt.Sprintf("bot-notify-backup-%s.tar.gz", time.Now().Format("20060102-150405"))
+	}
+
+	if err := writeBackup(env, path); err != nil {
+		os.Remove(path)
+		return err
+	}
+
+	fmt.Printf("Backup ditulis ke %s\n", path)
+	return nil
+}
+
+// backupper diimplementasikan storage yang mendukung backup
+type backupper interface {
+	Backup(w io.Writer) error
+}
+
+// writeBackup menulis backup storage dan database sesi WhatsApp ke arsip tar.gz
+func writeBackup(env *cliEnv, path string) error {
+	store, err := storage.Initialize(env.cfg)
+	if err != nil {
+		return fmt.Errorf("gagal membuka storage: %w", err)
+	}
+	defer store.Close()
+
+	b, ok := store.(backupper)
+	if !ok {
+		return fmt.Errorf("storage %s tidak mendukung backup", env.cfg.Storage.Type)
+	}
+
+	f, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("gagal membuat file backup: %w", err)
+	}
+	defer f.Close()
+
+	gz := gzip.NewWriter(f)
+	tw := tar.NewWriter(gz)
+
+	// Backup Badger ditulis ke file sementara karena header tar membutuhkan ukuran
+	tmp, err := os.CreateTemp("", "bot-notify-storage-*.bak")
+	if err != nil {
+		return fmt.Errorf("gagal membuat file sementara: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	defer tmp.Close()
+
+	if err := b.Backup(tmp); err != nil {
+		return err
+	}
+	if err := addFileToTar(tw, tmp.Name(), "storage.bak"); err != nil {
+		return err
+	}
+
+	sessionDB := filepath.Join(env.cfg.WhatsApp.StoreDir, "store.db")
+	if _, err := os.Stat(sessionDB); err == nil {
+		if err := addFileToTar(tw, sessionDB, "whatsapp/store.db"); err != nil {
+			return err
+		}
+	}
+
+	if err := tw.Close(); err != nil {
+		return fmt.Errorf("gagal menutup arsip backup: %w", err)
+	}
+	if err := gz.Close(); err != nil {
+		return fmt.Errorf("gagal menutup arsip backup: %w", err)
+	}
+	return f.Close()
+}
+
+// addFileToTar menambahkan file ke arsip tar dengan nama yang diberikan
+func addFileToTar(tw *tar.Writer, src, name string) error {
+	f, err := os.Open(src)
+	if err != nil {
+		return fmt.Errorf("gagal membuka %s: %w", src, err)
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return fmt.Errorf("gagal membaca %s: %w", src, err)
+	}
+
+	header, err := tar.FileInfoHeader(info, "")
+	if err != nil {
+		return fmt.Errorf("gagal membuat header tar: %w", err)
+	}
+	header.Name = name
+
+	if err := tw.WriteHeader(header); err != nil {
+		return fmt.Errorf("gagal menulis header tar: %w", err)
+	}
+	if _, err := io.Copy(tw, f); err != nil {
+		return fmt.Errorf("gagal menyalin %s ke backup: %w", src, err)
+	}
+	return nil
+}
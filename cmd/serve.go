@@ -0,0 +1,311 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/gwenziro/bot-notify/internal/api"
+	"github.com/gwenziro/bot-notify/internal/config"
+	"github.com/gwenziro/bot-notify/internal/metrics"
+	"github.com/gwenziro/bot-notify/internal/repository"
+	"github.com/gwenziro/bot-notify/internal/server"
+	"github.com/gwenziro/bot-notify/internal/service/apikey"
+	logsvc "github.com/gwenziro/bot-notify/internal/service/log"
+	"github.com/gwenziro/bot-notify/internal/service/queue"
+	"github.com/gwenziro/bot-notify/internal/service/receipt"
+	"github.com/gwenziro/bot-notify/internal/service/recurring"
+	"github.com/gwenziro/bot-notify/internal/service/webhook"
+	"github.com/gwenziro/bot-notify/internal/service/whatsapp/client"
+	"github.com/gwenziro/bot-notify/internal/storage"
+	"github.com/gwenziro/bot-notify/internal/utils"
+	"github.com/gwenziro/bot-notify/internal/web"
+)
+
+// runServe menjalankan web server, API, dan koneksi WhatsApp sampai menerima sinyal berhenti
+func runServe(args []string) error {
+	// Setup dasar logger
+	if err := utils.Setup(&utils.LogConfig{Level: "info"}); err != nil {
+		fmt.Printf("Error saat inisialisasi logger: %v\n", err)
+		os.Exit(1)
+	}
+
+	// Log root project dan pastikan struktur direktori
+	sysLogger := utils.ForModule("system")
+	sysLogger.Info("Detected project root", utils.Fields{"path": utils.ProjectRoot})
+	if err := utils.EnsureProjectStructure(); err != nil {
+		sysLogger.Error("Gagal membuat struktur direktori", utils.Fields{"error": err.Error()})
+		os.Exit(1)
+	}
+
+	// Load konfigurasi berlapis: default → YAML → env BOTNOTIFY_* → flag
+	fs := flag.NewFlagSet("serve", flag.ExitOnError)
+	loader := config.NewLoader()
+	loader.BindFlags(fs)
+	fs.Parse(args)
+
+	cfg, err := loader.Load()
+	if err != nil {
+		sysLogger.Error("Gagal memuat konfigurasi", utils.Fields{"error": err.Error()})
+		os.Exit(1)
+	}
+	if overridden := cfg.Overridden(); len(overridden) > 0 {
+		sysLogger.Info("Pengaturan dari environment variable atau flag", utils.Fields{"keys": overridden})
+	}
+
+	// Validasi konfigurasi, tolak start jika ada masalah kecuali dalam mode development
+	if err := cfg.Validate(); err != nil {
+		reportConfigProblems(err, cfg.Server.DevMode)
+		if !cfg.Server.DevMode {
+			os.Exit(1)
+		}
+	}
+
+	// Validasi hanya memeriksa izin tulis, direktori data dibuat di sini sekali saat start
+	if err := cfg.EnsureDirs(); err != nil {
+		sysLogger.Error("Gagal membuat direktori data", utils.Fields{"error": err.Error()})
+		os.Exit(1)
+	}
+
+	// Setup ulang logger dengan level, file, dan rotasi dari konfigurasi yang dimuat
+	if err := utils.Setup(&utils.LogConfig{
+		Level:      cfg.Logging.Level,
+		File:       cfg.Logging.File,
+		MaxSize:    cfg.Logging.MaxSize,
+		MaxAge:     cfg.Logging.MaxAge,
+		MaxBackups: cfg.Logging.MaxBackups,
+		Compress:   cfg.Logging.Compress,
+	}); err != nil {
+		sysLogger.Error("Gagal menerapkan konfigurasi logger", utils.Fields{"error": err.Error()})
+		os.Exit(1)
+	}
+
+	// Semua komponen membaca konfigurasi yang berjalan melalui live, yang diperbarui
+	// saat file konfigurasi di-reload atau pengaturan diubah dari dashboard
+	live := config.NewLive(cfg)
+
+	// Tutup logger dan simpan sisa log saat aplikasi berhenti
+	defer utils.Close()
+
+	// Inisialisasi storage
+	store, err := storage.Initialize(cfg)
+	if err != nil {
+		utils.Fatal("Gagal inisialisasi storage", utils.Fields{"error": err.Error()})
+	}
+	defer store.Close()
+
+	// Simpan log aplikasi ke storage agar tampil di halaman log dan /api/logs, sekaligus
+	// diteruskan ke /api/logs/stream. Dihentikan sebelum storage ditutup supaya sisa
+	// antrean log sempat tersimpan.
+	logRepo := repository.NewLogRepository(store, utils.ForModule("log"))
+	logService := logsvc.NewLogService(logRepo, live, utils.ForModule("log-service"))
+	if err := utils.EnablePersistence(logService, cfg.Logging.PersistLevel); err != nil {
+		utils.Warn("Gagal mengaktifkan penyimpanan log", utils.Fields{"error": err.Error()})
+	}
+	defer utils.DisablePersistence()
+
+	// Hapus log lama secara berkala sesuai logging.retention
+	logService.StartRetention()
+	defer logService.StopRetention()
+
+	// Inisialisasi akun WhatsApp, setiap akun memiliki klien dan sesi sendiri
+	accounts, err := client.NewAccountManager(live, store)
+	if err != nil {
+		utils.Fatal("Gagal inisialisasi WhatsApp client", utils.Fields{"error": err.Error()})
+	}
+	defer accounts.Close()
+
+	// Laporkan status koneksi akun default ke Prometheus
+	metrics.RegisterClientStatus(client.Statuses(), func() string {
+		return string(accounts.Default().GetConnectionState().Status)
+	})
+
+	// Catat receipt (delivered/read) untuk pesan yang dikirim
+	receipts := receipt.NewTracker(store)
+	accounts.SetReceiptRecorder(receipts)
+
+	// Teruskan pesan masuk ke webhook yang terdaftar
+	webhooks := webhook.NewDispatcher(cfg)
+	webhooks.Start()
+	defer webhooks.Stop()
+	accounts.RegisterCallback("*events.Message", webhooks.HandleEvent)
+
+	// Jalankan antrean pesan keluar
+	outbox := queue.NewQueue(store, accounts, cfg)
+	outbox.Start()
+	defer outbox.Stop()
+
+	// Jalankan scheduler pesan berulang
+	scheduler := recurring.NewScheduler(store, outbox)
+	scheduler.Start()
+	defer scheduler.Stop()
+
+	// API key bernama dengan scope untuk akses API
+	apiKeys := apikey.NewStore(store)
+
+	// Setup handlers
+	webHandler := web.NewWebHandler(live, accounts, nil, scheduler, logService)
+	apiHandler := api.NewAPIHandler(live, accounts, nil, outbox, receipts, scheduler, apiKeys, logService)
+
+	// Template di-embed ke binary kecuali server.views_dir diisi
+	viewsDir := cfg.Server.ViewsDir
+
+	// Log tambahan untuk memantau loading template
+	utils.Info("Memulai inisialisasi web server dengan template", utils.Fields{
+		"views_dir":  viewsDir,
+		"static_dir": cfg.Server.StaticDir,
+	})
+
+	serverOpts := server.ServerOptions{
+		Config:               cfg,
+		EnableTemplateEngine: true,
+		ViewsDir:             viewsDir,
+		WebHandler:           webHandler,
+		APIHandler:           apiHandler,
+	}
+
+	srv, err := server.NewServer(serverOpts)
+	if err != nil {
+		utils.Fatal("Gagal inisialisasi server", utils.Fields{"error": err.Error()})
+	}
+
+	// Terapkan perubahan konfigurasi dari reload file, SIGHUP, atau halaman pengaturan
+	live.Subscribe(func(cfg *config.Config, changes config.Changes) {
+		applyConfigChanges(cfg, changes, outbox, srv)
+	})
+
+	// Reload konfigurasi saat config.yaml berubah atau menerima SIGHUP
+	watcher := config.NewWatcher(live, loader)
+	if err := watcher.Start(); err != nil {
+		utils.Warn("Gagal memantau file konfigurasi, hot reload tidak aktif", utils.Fields{"error": err.Error()})
+	} else {
+		defer watcher.Stop()
+	}
+
+	// Jalankan server di background
+	listenAddr := fmt.Sprintf("%s:%d", cfg.Server.Host, cfg.Server.Port)
+	go func() {
+		utils.Info("Server berjalan", utils.Fields{
+			"address": listenAddr,
+			"port":    cfg.Server.Port,
+			"pid":     os.Getpid(),
+		})
+		if err := srv.App.Listen(listenAddr); err != nil {
+			utils.Error("Error saat menjalankan server", utils.Fields{"error": err.Error()})
+		}
+	}()
+
+	// Connect semua akun WhatsApp dengan sedikit delay untuk memastikan server siap
+	go func() {
+		time.Sleep(3 * time.Second)
+		accounts.ConnectAll()
+	}()
+
+	// Tunggu sinyal shutdown dan tangani graceful shutdown
+	quit := make(chan os.Signal, 1)
+	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
+
+	<-quit // Tunggu sinyal shutdown
+
+	utils.Info("Memulai graceful shutdown...")
+
+	// Tutup koneksi WhatsApp dengan bersih
+	accounts.DisconnectAll()
+
+	// Shutdown HTTP server
+	shutdownTimeout := live.Get().Server.ShutdownTimeout
+	if shutdownTimeout == 0 {
+		shutdownTimeout = 10 * time.Second
+	}
+
+	// Akhiri stream log dan QR code agar koneksinya tidak menahan shutdown server
+	logService.CloseStream()
+	accounts.CloseQRStreams()
+
+	// Untuk shutdown handler, ganti fiberApp.App dengan fiberApp:
+	if err := srv.App.ShutdownWithTimeout(shutdownTimeout); err != nil {
+		utils.Error("Error saat shutdown server", utils.Fields{"error": err.Error()})
+	} else {
+		utils.Info("Server berhasil dimatikan")
+	}
+
+	return nil
+}
+
+// applyConfigChanges menerapkan perubahan konfigurasi ke komponen yang menyimpan
+// salinan nilainya sendiri. Komponen lain membaca config.Live setiap kali dibutuhkan
+// sehingga perubahan sudah berlaku tanpa perlu diberi tahu.
+func applyConfigChanges(cfg *config.Config, changes config.Changes, outbox *queue.Queue, srv *server.Server) {
+	if changes.Has("logging.level") {
+		if err := utils.SetLevel(cfg.Logging.Level); err != nil {
+			utils.Warn("Gagal menerapkan level log", utils.Fields{"error": err.Error()})
+		}
+	}
+
+	if changes.Has("logging.persist_level") {
+		if err := utils.SetPersistLevel(cfg.Logging.PersistLevel); err != nil {
+			utils.Warn("Gagal menerapkan level penyimpanan log", utils.Fields{"error": err.Error()})
+		}
+	}
+
+	if changes.Has("whatsapp.send_retry") {
+		if err := outbox.SetDefaultRetryPolicy(cfg.WhatsApp.SendRetry); err != nil {
+			utils.Warn("Gagal menerapkan kebijakan retry baru", utils.Fields{"error": err.Error()})
+		}
+	}
+
+	if changes.Has("whatsapp.queue_retention") {
+		outbox.SetRetention(cfg.WhatsApp.QueueRetention)
+	}
+
+	// Sesi web menyimpan access token lama, jadi harus diakhiri saat token berganti
+	if changes.Has("auth.access_token") {
+		if err := srv.SessionStore.Reset(); err != nil {
+			utils.Warn("Gagal mengakhiri sesi web setelah token berubah", utils.Fields{"error": err.Error()})
+		} else {
+			utils.Info("Token akses berubah, semua sesi web diakhiri")
+		}
+	}
+
+	var restart []string
+	for _, key := range changes.Keys() {
+		if config.RequiresRestart(key) {
+			restart = append(restart, key)
+		}
+	}
+	if len(restart) > 0 {
+		utils.Warn("Sebagian perubahan konfigurasi baru berlaku setelah restart", utils.Fields{"keys": restart})
+	}
+}
+
+// reportConfigProblems menampilkan semua masalah validasi konfigurasi
+func reportConfigProblems(err error, devMode bool) {
+	problems := []string{err.Error()}
+	var verr *config.ValidationError
+	if errors.As(err, &verr) {
+		problems = verr.Problems
+	}
+
+	if devMode {
+		fmt.Fprintf(os.Stderr, "\n!!! PERINGATAN: konfigurasi memiliki %d masalah, tetap berjalan karena dev_mode aktif !!!\n", len(problems))
+	} else {
+		fmt.Fprintf(os.Stderr, "\nKonfigurasi tidak valid, aplikasi tidak dijalankan. Ditemukan %d masalah:\n", len(problems))
+	}
+	for _, problem := range problems {
+		fmt.Fprintf(os.Stderr, "  - %s\n", problem)
+	}
+	if !devMode {
+		fmt.Fprintf(os.Stderr, "\nPerbaiki file konfigurasi atau environment variable %s*, atau jalankan dengan --dev untuk development.\n", config.EnvPrefix)
+	}
+	fmt.Fprintln(os.Stderr)
+
+	if devMode {
+		utils.Warn("Konfigurasi tidak valid", utils.Fields{"problems": problems})
+	} else {
+		utils.Error("Konfigurasi tidak valid", utils.Fields{"problems": problems})
+	}
+}
@@ -6,6 +6,11 @@ import (
 	"github.com/gwenziro/bot-notify/internal/api/handler"
 	"github.com/gwenziro/bot-notify/internal/api/middleware"
 	"github.com/gwenziro/bot-notify/internal/config"
+	"github.com/gwenziro/bot-notify/internal/service/apikey"
+	"github.com/gwenziro/bot-notify/internal/service/log"
+	"github.com/gwenziro/bot-notify/internal/service/queue"
+	"github.com/gwenziro/bot-notify/internal/service/receipt"
+	"github.com/gwenziro/bot-notify/internal/service/recurring"
 	"github.com/gwenziro/bot-notify/internal/service/whatsapp/client"
 	"github.com/gwenziro/bot-notify/internal/utils"
 )
@@ -16,42 +21,59 @@ type APIHandler struct {
 	statusHandler *handler.StatusHandler
 	connHandler   *handler.ConnectionHandler
 	msgHandler    *handler.MessageHandler
+	deadHandler   *handler.DeadLetterHandler
+	schedHandler  *handler.ScheduledHandler
+	cronHandler   *handler.RecurringHandler
 	groupHandler  *handler.GroupHandler
 	qrHandler     *handler.QRCodeHandler
+	accHandler    *handler.AccountHandler
 	logsHandler   *handler.LogsHandler
+	keyHandler    *handler.APIKeyHandler
+	auth          *middleware.APIAuthMiddleware
 	authMw        fiber.Handler
-	config        *config.Config
-	whatsApp      *client.Client
+	config        *config.Live
+	accounts      *client.AccountManager
 	sessionStore  *session.Store
 	logger        utils.LogrusEntry
 }
 
 // NewAPIHandler membuat instance baru APIHandler
-func NewAPIHandler(cfg *config.Config, whatsClient *client.Client, sessionStore *session.Store) *APIHandler {
+func NewAPIHandler(cfg *config.Live, accounts *client.AccountManager, sessionStore *session.Store, outbox *queue.Queue, receipts *receipt.Tracker, scheduler *recurring.Scheduler, keys *apikey.Store, logService *log.LogService) *APIHandler {
 	logger := utils.ForModule("api")
 
 	// Initialize API auth middleware (berbeda dengan web auth middleware)
-	apiAuthMw := middleware.NewAPIAuthMiddleware(cfg, sessionStore)
+	apiAuthMw := middleware.NewAPIAuthMiddleware(cfg, sessionStore, keys)
 
 	// Inisialisasi handler-handler untuk setiap domain
-	statusHandler := handler.NewStatusHandler(whatsClient)
-	connHandler := handler.NewConnectionHandler(whatsClient)
-	msgHandler := handler.NewMessageHandler(whatsClient)
-	groupHandler := handler.NewGroupHandler(whatsClient)
-	qrHandler := handler.NewQRCodeHandler(whatsClient)
-	// logsHandler := handler.NewLogsHandler(logService, utils.ForModule("api-logs"))
+	statusHandler := handler.NewStatusHandler(accounts)
+	connHandler := handler.NewConnectionHandler(accounts)
+	msgHandler := handler.NewMessageHandler(accounts, outbox, receipts, cfg.Get())
+	deadHandler := handler.NewDeadLetterHandler(outbox)
+	schedHandler := handler.NewScheduledHandler(outbox)
+	cronHandler := handler.NewRecurringHandler(scheduler, accounts)
+	groupHandler := handler.NewGroupHandler(accounts)
+	qrHandler := handler.NewQRCodeHandler(accounts)
+	accHandler := handler.NewAccountHandler(accounts)
+	keyHandler := handler.NewAPIKeyHandler(keys)
+	logsHandler := handler.NewLogsHandler(logService, utils.ForModule("api-logs"))
 
 	return &APIHandler{
 		statusHandler: statusHandler,
 		connHandler:   connHandler,
 		msgHandler:    msgHandler,
+		deadHandler:   deadHandler,
+		schedHandler:  schedHandler,
+		cronHandler:   cronHandler,
 		groupHandler:  groupHandler,
 		qrHandler:     qrHandler,
-		// logsHandler:   logsHandler,
-		authMw:       apiAuthMw.RequireAuth(),
-		config:       cfg,
-		whatsApp:     whatsClient,
-		sessionStore: sessionStore,
-		logger:       logger,
+		accHandler:    accHandler,
+		logsHandler:   logsHandler,
+		keyHandler:    keyHandler,
+		auth:          apiAuthMw,
+		authMw:        apiAuthMw.RequireAuth(),
+		config:        cfg,
+		accounts:      accounts,
+		sessionStore:  sessionStore,
+		logger:        logger,
 	}
 }
@@ -2,6 +2,7 @@ package api
 
 import (
 	"github.com/gofiber/fiber/v2"
+	"github.com/gwenziro/bot-notify/internal/service/apikey"
 )
 
 // RegisterRoutes mendaftarkan semua endpoint API ke Fiber app
@@ -13,27 +14,85 @@ func (h *APIHandler) RegisterEndpoints(app *fiber.App) {
 	api := app.Group("/api")
 	api.Use(h.authMw)
 
-	// Status API
+	// Middleware scope per route
+	admin := h.auth.RequireScope(apikey.ScopeAdmin)
+	sendAny := h.auth.RequireScope(apikey.ScopeSendPersonal, apikey.ScopeSendGroup)
+	sendPersonal := h.auth.RequireScope(apikey.ScopeSendPersonal)
+	sendGroup := h.auth.RequireScope(apikey.ScopeSendGroup)
+	groupsRead := h.auth.RequireScope(apikey.ScopeGroupsRead)
+	logsRead := h.auth.RequireScope(apikey.ScopeLogsRead)
+
+	// Status API, dapat diakses semua API key yang valid
 	api.Get("/status", h.statusHandler.GetStatus)
 
-	// WhatsApp Connection
-	api.Post("/reconnect", h.connHandler.Reconnect)
-	api.Get("/reconnect", h.connHandler.Reconnect)
-	api.Post("/disconnect", h.connHandler.Disconnect)
+	// WhatsApp Connection. Endpoint koneksi, pairing, grup, dan QR code memakai
+	// akun default kecuali akun dipilih dengan ?account= atau route /accounts/:name
+	api.Post("/reconnect", admin, h.connHandler.Reconnect)
+	api.Get("/reconnect", admin, h.connHandler.Reconnect)
+	api.Post("/disconnect", admin, h.connHandler.Disconnect)
+
+	// Pairing dengan kode nomor telepon, alternatif dari QR code
+	api.Post("/pair/phone", admin, h.connHandler.PairPhone)
+
+	// Message API. Scope untuk image, document, dan data pesan diperiksa lagi
+	// di handler setelah tipe penerima diketahui.
+	api.Post("/send/personal", sendPersonal, h.msgHandler.SendPersonal)
+	api.Post("/send/group", sendGroup, h.msgHandler.SendGroup)
+	api.Post("/send/image", sendAny, h.msgHandler.SendImage)
+	api.Post("/send/document", sendAny, h.msgHandler.SendDocument)
+	api.Get("/messages/:id", sendAny, h.msgHandler.GetMessage)
+	api.Get("/messages/:id/status", sendAny, h.msgHandler.GetMessageStatus)
+
+	// Scheduled messages API, hanya pesan dengan tipe penerima yang sesuai scope
+	api.Get("/scheduled", sendAny, h.schedHandler.List)
+	api.Put("/scheduled/:id", sendAny, h.schedHandler.Reschedule)
+	api.Delete("/scheduled/:id", sendAny, h.schedHandler.Cancel)
 
-	// Message API
-	api.Post("/send/personal", h.msgHandler.SendPersonal)
-	api.Post("/send/group", h.msgHandler.SendGroup)
+	// Recurring messages API
+	api.Get("/recurring", admin, h.cronHandler.List)
+	api.Post("/recurring", admin, h.cronHandler.Create)
+	api.Get("/recurring/:id", admin, h.cronHandler.Get)
+	api.Put("/recurring/:id", admin, h.cronHandler.Update)
+	api.Delete("/recurring/:id", admin, h.cronHandler.Delete)
+
+	// Dead-letter queue API
+	api.Get("/deadletter", admin, h.deadHandler.List)
+	api.Delete("/deadletter", admin, h.deadHandler.PurgeAll)
+	api.Get("/deadletter/:id", admin, h.deadHandler.Get)
+	api.Post("/deadletter/:id/requeue", admin, h.deadHandler.Requeue)
+	api.Delete("/deadletter/:id", admin, h.deadHandler.Purge)
 
 	// Groups API
-	api.Get("/groups", h.groupHandler.ListGroups)
+	api.Get("/groups", groupsRead, h.groupHandler.ListGroups)
 
 	// QR Code API
-	api.Get("/qr/status", h.qrHandler.GetStatus)
-	api.Get("/qr/image", h.qrHandler.GetImage)
+	api.Get("/qr/status", admin, h.qrHandler.GetStatus)
+	api.Get("/qr/image", admin, h.qrHandler.GetImage)
+	api.Get("/qr/events", admin, h.qrHandler.Events)
 
 	// Logs API
-	api.Get("/logs", h.logsHandler.GetLogs)
-	api.Post("/logs/clear", h.logsHandler.ClearLogs)
-	api.Get("/logs/export", h.logsHandler.ExportLogs)
+	api.Get("/logs", logsRead, h.logsHandler.GetLogs)
+	api.Post("/logs/clear", admin, h.logsHandler.ClearLogs)
+	api.Get("/logs/export", logsRead, h.logsHandler.ExportLogs)
+	api.Get("/logs/stats", logsRead, h.logsHandler.GetLogStats)
+	api.Get("/logs/summary", logsRead, h.logsHandler.GetLogSummary)
+	api.Get("/logs/stream", logsRead, h.logsHandler.StreamLogs)
+
+	// Akun WhatsApp
+	api.Get("/accounts", admin, h.accHandler.List)
+	api.Post("/accounts", admin, h.accHandler.Create)
+	api.Delete("/accounts/:name", admin, h.accHandler.Delete)
+	api.Post("/accounts/:name/reconnect", admin, h.connHandler.Reconnect)
+	api.Post("/accounts/:name/disconnect", admin, h.connHandler.Disconnect)
+	api.Post("/accounts/:name/pair/phone", admin, h.connHandler.PairPhone)
+	api.Get("/accounts/:name/status", admin, h.statusHandler.GetStatus)
+	api.Get("/accounts/:name/groups", groupsRead, h.groupHandler.ListGroups)
+	api.Get("/accounts/:name/qr/status", admin, h.qrHandler.GetStatus)
+	api.Get("/accounts/:name/qr/image", admin, h.qrHandler.GetImage)
+	api.Get("/accounts/:name/qr/events", admin, h.qrHandler.Events)
+
+	// API key management
+	api.Get("/keys", admin, h.keyHandler.List)
+	api.Post("/keys", admin, h.keyHandler.Create)
+	api.Delete("/keys/:id", admin, h.keyHandler.Revoke)
 }
@@ -0,0 +1,109 @@
+package handler
+
+import (
+	"errors"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/gwenziro/bot-notify/internal/api/model"
+	"github.com/gwenziro/bot-notify/internal/service/whatsapp/client"
+	"github.com/gwenziro/bot-notify/internal/utils"
+)
+
+// AccountHandler menangani endpoint pengelolaan akun WhatsApp
+type AccountHandler struct {
+	accounts *client.AccountManager
+	logger   utils.LogrusEntry
+}
+
+// NewAccountHandler membuat instance baru AccountHandler
+func NewAccountHandler(accounts *client.AccountManager) *AccountHandler {
+	return &AccountHandler{
+		accounts: accounts,
+		logger:   utils.ForModule("handler-account"),
+	}
+}
+
+// List mengembalikan semua akun WhatsApp beserta status koneksinya
+func (h *AccountHandler) List(c *fiber.Ctx) error {
+	accounts := h.accounts.List()
+
+	return c.JSON(fiber.Map{
+		"sukses": true,
+		"total":  len(accounts),
+		"data":   accounts,
+	})
+}
+
+// Create menambah akun WhatsApp baru lalu mulai menghubungkannya agar QR code tersedia
+func (h *AccountHandler) Create(c *fiber.Ctx) error {
+	var req model.AccountRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(
+			model.NewErrorMessageResponse("Format request tidak valid", err, fiber.StatusBadRequest))
+	}
+
+	wa, err := h.accounts.Add(req.Name)
+	switch {
+	case errors.Is(err, client.ErrInvalidAccountName):
+		return c.Status(fiber.StatusBadRequest).JSON(
+			model.NewErrorMessageResponse("Nama akun tidak valid", err, fiber.StatusBadRequest))
+	case errors.Is(err, client.ErrAccountExists):
+		return c.Status(fiber.StatusConflict).JSON(
+			model.NewErrorMessageResponse("Nama akun sudah dipakai", err, fiber.StatusConflict))
+	case err != nil:
+		h.logger.WithError(err).WithField("account", req.Name).Error("Gagal menambah akun WhatsApp")
+		return c.Status(fiber.StatusInternalServerError).JSON(
+			model.NewErrorMessageResponse("Gagal menambah akun", err, fiber.StatusInternalServerError))
+	}
+
+	// Mulai koneksi di background, QR code dikirim melalui /api/accounts/:name/qr/events
+	go func() {
+		if err := wa.Connect(); err != nil {
+			h.logger.WithError(err).WithField("account", wa.Name()).Error("Gagal menghubungkan akun baru")
+		}
+	}()
+
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"sukses": true,
+		"pesan":  "Akun ditambahkan, pindai QR code atau minta kode pairing untuk menautkan perangkat",
+		"data":   wa.Name(),
+	})
+}
+
+// Delete memutus perangkat akun dari WhatsApp lalu menghapus akun
+func (h *AccountHandler) Delete(c *fiber.Ctx) error {
+	name := c.Params("name")
+
+	err := h.accounts.Remove(name)
+	switch {
+	case errors.Is(err, client.ErrAccountNotFound):
+		return accountNotFound(c, err)
+	case errors.Is(err, client.ErrDefaultAccount):
+		return c.Status(fiber.StatusConflict).JSON(
+			model.NewErrorMessageResponse("Akun default tidak dapat dihapus", err, fiber.StatusConflict))
+	case err != nil:
+		h.logger.WithError(err).WithField("account", name).Error("Gagal menghapus akun WhatsApp")
+		return c.Status(fiber.StatusInternalServerError).JSON(
+			model.NewErrorMessageResponse("Gagal menghapus akun", err, fiber.StatusInternalServerError))
+	}
+
+	return c.JSON(fiber.Map{
+		"sukses": true,
+		"pesan":  "Akun WhatsApp berhasil dihapus",
+	})
+}
+
+// accountName mengembalikan nama akun dari route /accounts/:name atau query ?account=.
+// Nama kosong berarti akun default.
+func accountName(c *fiber.Ctx) string {
+	if name := c.Params("name"); name != "" {
+		return name
+	}
+	return c.Query("account")
+}
+
+// accountNotFound mengirim respons untuk akun yang tidak terdaftar
+func accountNotFound(c *fiber.Ctx, err error) error {
+	return c.Status(fiber.StatusNotFound).JSON(
+		model.NewErrorMessageResponse("Akun WhatsApp tidak ditemukan", err, fiber.StatusNotFound))
+}
@@ -0,0 +1,96 @@
+package handler
+
+import (
+	"errors"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/gwenziro/bot-notify/internal/api/model"
+	"github.com/gwenziro/bot-notify/internal/service/apikey"
+	"github.com/gwenziro/bot-notify/internal/utils"
+)
+
+// APIKeyHandler menangani endpoint pengelolaan API key
+type APIKeyHandler struct {
+	keys   *apikey.Store
+	logger utils.LogrusEntry
+}
+
+// NewAPIKeyHandler membuat instance baru APIKeyHandler
+func NewAPIKeyHandler(keys *apikey.Store) *APIKeyHandler {
+	return &APIKeyHandler{
+		keys:   keys,
+		logger: utils.ForModule("handler-apikey"),
+	}
+}
+
+// List mengembalikan semua API key tanpa token
+func (h *APIKeyHandler) List(c *fiber.Ctx) error {
+	keys, err := h.keys.List()
+	if err != nil {
+		h.logger.WithError(err).Error("Gagal mengambil daftar API key")
+		return c.Status(fiber.StatusInternalServerError).JSON(
+			model.NewErrorMessageResponse("Gagal mengambil daftar API key", err, fiber.StatusInternalServerError))
+	}
+
+	return c.JSON(fiber.Map{
+		"sukses": true,
+		"total":  len(keys),
+		"data":   keys,
+	})
+}
+
+// Create membuat API key baru. Token hanya ditampilkan sekali pada respons ini.
+func (h *APIKeyHandler) Create(c *fiber.Ctx) error {
+	var req model.CreateAPIKeyRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(
+			model.NewErrorMessageResponse("Format request tidak valid", err, fiber.StatusBadRequest))
+	}
+
+	scopes, err := apikey.ParseScopes(req.Scopes)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(
+			model.NewErrorMessageResponse("Scope tidak valid", err, fiber.StatusBadRequest))
+	}
+
+	if req.ExpiresAt != nil && !req.ExpiresAt.After(time.Now()) {
+		return c.Status(fiber.StatusBadRequest).JSON(
+			model.NewErrorMessageResponse("Waktu kedaluwarsa harus di masa depan", nil, fiber.StatusBadRequest))
+	}
+
+	token, key, err := h.keys.Create(req.Name, scopes, req.ExpiresAt)
+	if err != nil {
+		h.logger.WithError(err).Error("Gagal membuat API key")
+		return c.Status(fiber.StatusBadRequest).JSON(
+			model.NewErrorMessageResponse("Gagal membuat API key", err, fiber.StatusBadRequest))
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"sukses": true,
+		"pesan":  "API key berhasil dibuat. Simpan token ini, token tidak akan ditampilkan lagi.",
+		"token":  token,
+		"data":   key,
+	})
+}
+
+// Revoke mencabut API key
+func (h *APIKeyHandler) Revoke(c *fiber.Ctx) error {
+	id := c.Params("id")
+
+	if err := h.keys.Revoke(id); err != nil {
+		if errors.Is(err, apikey.ErrKeyNotFound) {
+			return c.Status(fiber.StatusNotFound).JSON(
+				model.NewErrorMessageResponse("API key tidak ditemukan", nil, fiber.StatusNotFound))
+		}
+
+		h.logger.WithError(err).WithField("id", id).Error("Gagal mencabut API key")
+		return c.Status(fiber.StatusInternalServerError).JSON(
+			model.NewErrorMessageResponse("Gagal mencabut API key", err, fiber.StatusInternalServerError))
+	}
+
+	return c.JSON(fiber.Map{
+		"sukses": true,
+		"pesan":  "API key berhasil dicabut",
+	})
+}
@@ -1,6 +1,9 @@
 package handler
 
 import (
+	"errors"
+	"time"
+
 	"github.com/gofiber/fiber/v2"
 	"github.com/gwenziro/bot-notify/internal/api/model"
 	"github.com/gwenziro/bot-notify/internal/service/whatsapp/client"
@@ -9,14 +12,14 @@ import (
 
 // ConnectionHandler menangani endpoint koneksi WhatsApp
 type ConnectionHandler struct {
-	whatsApp *client.Client
+	accounts *client.AccountManager
 	logger   utils.LogrusEntry
 }
 
 // NewConnectionHandler membuat instance baru ConnectionHandler
-func NewConnectionHandler(whatsClient *client.Client) *ConnectionHandler {
+func NewConnectionHandler(accounts *client.AccountManager) *ConnectionHandler {
 	return &ConnectionHandler{
-		whatsApp: whatsClient,
+		accounts: accounts,
 		logger:   utils.ForModule("handler-connection"),
 	}
 }
@@ -28,10 +31,15 @@ func (h *ConnectionHandler) Reconnect(c *fiber.Ctx) error {
 	// Parse request jika ada
 	c.BodyParser(&req)
 
+	wa, err := h.accounts.Get(accountName(c))
+	if err != nil {
+		return accountNotFound(c, err)
+	}
+
 	// Connect whatsapp langsung
-	err := h.whatsApp.Connect()
+	err = wa.Connect()
 	if err != nil {
-		h.logger.WithError(err).Error("Gagal menghubungkan ulang WhatsApp")
+		h.logger.WithError(err).WithField("account", wa.Name()).Error("Gagal menghubungkan ulang WhatsApp")
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"sukses": false,
 			"pesan":  "Gagal menghubungkan WhatsApp: " + err.Error(),
@@ -39,7 +47,7 @@ func (h *ConnectionHandler) Reconnect(c *fiber.Ctx) error {
 		})
 	}
 
-	h.logger.Info("Permintaan menghubungkan ulang WhatsApp berhasil diproses")
+	h.logger.WithField("account", wa.Name()).Info("Permintaan menghubungkan ulang WhatsApp berhasil diproses")
 
 	return c.JSON(model.NewConnectionResponse(
 		true,
@@ -49,13 +57,18 @@ func (h *ConnectionHandler) Reconnect(c *fiber.Ctx) error {
 
 // Disconnect memutuskan koneksi WhatsApp
 func (h *ConnectionHandler) Disconnect(c *fiber.Ctx) error {
+	wa, err := h.accounts.Get(accountName(c))
+	if err != nil {
+		return accountNotFound(c, err)
+	}
+
 	// Putuskan koneksi WhatsApp
-	h.whatsApp.Disconnect()
+	wa.Disconnect()
 
 	// Hapus sesi
-	err := h.whatsApp.SessionManager.ClearSessions()
+	err = wa.SessionManager.ClearSessions()
 	if err != nil {
-		h.logger.WithError(err).Error("Gagal menghapus sesi WhatsApp")
+		h.logger.WithError(err).WithField("account", wa.Name()).Error("Gagal menghapus sesi WhatsApp")
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"sukses": false,
 			"pesan":  "Koneksi diputus tetapi gagal menghapus sesi: " + err.Error(),
@@ -63,10 +76,53 @@ func (h *ConnectionHandler) Disconnect(c *fiber.Ctx) error {
 		})
 	}
 
-	h.logger.Info("WhatsApp berhasil diputuskan melalui API")
+	h.logger.WithField("account", wa.Name()).Info("WhatsApp berhasil diputuskan melalui API")
 
 	return c.JSON(model.NewConnectionResponse(
 		true,
 		"WhatsApp berhasil diputuskan dan sesi dibersihkan",
 		"disconnected"))
 }
+
+// PairPhone membuat kode pairing untuk menautkan perangkat dengan nomor telepon
+func (h *ConnectionHandler) PairPhone(c *fiber.Ctx) error {
+	var req model.PairPhoneRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(
+			model.NewErrorMessageResponse("Format request tidak valid", err, fiber.StatusBadRequest))
+	}
+
+	if req.PhoneNumber == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(
+			model.NewErrorMessageResponse("Nomor telepon harus disediakan", nil, fiber.StatusBadRequest))
+	}
+
+	wa, err := h.accounts.Get(accountName(c))
+	if err != nil {
+		return accountNotFound(c, err)
+	}
+
+	code, err := wa.PairPhone(req.PhoneNumber)
+	if err != nil {
+		switch {
+		case errors.Is(err, client.ErrInvalidPhoneNumber):
+			return c.Status(fiber.StatusBadRequest).JSON(
+				model.NewErrorMessageResponse("Nomor telepon tidak valid", err, fiber.StatusBadRequest))
+		case errors.Is(err, client.ErrAlreadyPaired):
+			return c.Status(fiber.StatusConflict).JSON(
+				model.NewErrorMessageResponse("Perangkat WhatsApp sudah dipasangkan, logout terlebih dahulu", err, fiber.StatusConflict))
+		}
+
+		h.logger.WithError(err).WithField("account", wa.Name()).Error("Gagal membuat kode pairing")
+		return c.Status(fiber.StatusInternalServerError).JSON(
+			model.NewErrorMessageResponse("Gagal membuat kode pairing", err, fiber.StatusInternalServerError))
+	}
+
+	return c.JSON(model.PairPhoneResponse{
+		Success:     true,
+		Message:     "Masukkan kode di WhatsApp: Perangkat tertaut > Tautkan perangkat > Tautkan dengan nomor telepon",
+		Code:        code,
+		PhoneNumber: client.ParsePhoneNumber(req.PhoneNumber).User,
+		Timestamp:   time.Now(),
+	})
+}
@@ -0,0 +1,114 @@
+package handler
+
+import (
+	"errors"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/gwenziro/bot-notify/internal/api/model"
+	"github.com/gwenziro/bot-notify/internal/service/queue"
+	"github.com/gwenziro/bot-notify/internal/utils"
+)
+
+// DeadLetterHandler menangani endpoint dead-letter queue API
+type DeadLetterHandler struct {
+	outbox *queue.Queue
+	logger utils.LogrusEntry
+}
+
+// NewDeadLetterHandler membuat instance baru DeadLetterHandler
+func NewDeadLetterHandler(outbox *queue.Queue) *DeadLetterHandler {
+	return &DeadLetterHandler{
+		outbox: outbox,
+		logger: utils.ForModule("handler-deadletter"),
+	}
+}
+
+// List mengembalikan semua pesan yang gagal permanen
+func (h *DeadLetterHandler) List(c *fiber.Ctx) error {
+	messages, err := h.outbox.ListDeadLetters()
+	if err != nil {
+		h.logger.WithError(err).Error("Gagal mengambil daftar dead-letter")
+		return c.Status(fiber.StatusInternalServerError).JSON(
+			model.NewErrorMessageResponse("Gagal mengambil daftar dead-letter", err, fiber.StatusInternalServerError))
+	}
+
+	return c.JSON(fiber.Map{
+		"sukses": true,
+		"total":  len(messages),
+		"data":   messages,
+	})
+}
+
+// Get mengembalikan detail satu pesan dead-letter
+func (h *DeadLetterHandler) Get(c *fiber.Ctx) error {
+	id := c.Params("id")
+
+	msg, err := h.outbox.GetDeadLetter(id)
+	if err != nil {
+		return h.handleError(c, id, "Gagal mengambil pesan dead-letter", err)
+	}
+
+	return c.JSON(fiber.Map{
+		"sukses": true,
+		"data":   msg,
+	})
+}
+
+// Requeue memasukkan kembali pesan dead-letter ke antrean pengiriman
+func (h *DeadLetterHandler) Requeue(c *fiber.Ctx) error {
+	id := c.Params("id")
+
+	msg, err := h.outbox.RequeueDeadLetter(id)
+	if err != nil {
+		return h.handleError(c, id, "Gagal memasukkan kembali pesan ke antrean", err)
+	}
+
+	return c.Status(fiber.StatusAccepted).JSON(model.NewQueuedMessageResponse(
+		"Pesan dimasukkan kembali ke antrean",
+		msg.ID,
+		string(msg.Status),
+		msg.Recipient,
+		string(msg.Type)))
+}
+
+// Purge menghapus satu pesan dari dead-letter queue
+func (h *DeadLetterHandler) Purge(c *fiber.Ctx) error {
+	id := c.Params("id")
+
+	if err := h.outbox.PurgeDeadLetter(id); err != nil {
+		return h.handleError(c, id, "Gagal menghapus pesan dead-letter", err)
+	}
+
+	return c.JSON(fiber.Map{
+		"sukses": true,
+		"pesan":  "Pesan dead-letter berhasil dihapus",
+	})
+}
+
+// PurgeAll mengosongkan dead-letter queue
+func (h *DeadLetterHandler) PurgeAll(c *fiber.Ctx) error {
+	count, err := h.outbox.PurgeDeadLetters()
+	if err != nil {
+		h.logger.WithError(err).Error("Gagal mengosongkan dead-letter queue")
+		return c.Status(fiber.StatusInternalServerError).JSON(
+			model.NewErrorMessageResponse("Gagal mengosongkan dead-letter queue", err, fiber.StatusInternalServerError))
+	}
+
+	return c.JSON(fiber.Map{
+		"sukses": true,
+		"pesan":  "Dead-letter queue berhasil dikosongkan",
+		"total":  count,
+	})
+}
+
+// handleError mengirim respons error sesuai jenis error dari antrean
+func (h *DeadLetterHandler) handleError(c *fiber.Ctx, id, message string, err error) error {
+	if errors.Is(err, queue.ErrMessageNotFound) {
+		return c.Status(fiber.StatusNotFound).JSON(
+			model.NewErrorMessageResponse("Pesan dead-letter tidak ditemukan", nil, fiber.StatusNotFound))
+	}
+
+	h.logger.WithError(err).WithField("id", id).Error(message)
+	return c.Status(fiber.StatusInternalServerError).JSON(
+		model.NewErrorMessageResponse(message, err, fiber.StatusInternalServerError))
+}
@@ -9,22 +9,27 @@ import (
 
 // GroupHandler menangani endpoint grup API
 type GroupHandler struct {
-	whatsApp *client.Client
+	accounts *client.AccountManager
 	logger   utils.LogrusEntry
 }
 
 // NewGroupHandler membuat instance baru GroupHandler
-func NewGroupHandler(whatsClient *client.Client) *GroupHandler {
+func NewGroupHandler(accounts *client.AccountManager) *GroupHandler {
 	return &GroupHandler{
-		whatsApp: whatsClient,
+		accounts: accounts,
 		logger:   utils.ForModule("handler-group"),
 	}
 }
 
-// ListGroups mengembalikan daftar grup yang tersedia
+// ListGroups mengembalikan daftar grup yang diikuti akun default, atau akun dari ?account=
 func (h *GroupHandler) ListGroups(c *fiber.Ctx) error {
+	wa, err := h.accounts.Get(accountName(c))
+	if err != nil {
+		return accountNotFound(c, err)
+	}
+
 	// Gunakan WhatsApp client langsung untuk mendapatkan daftar grup
-	groups, err := h.whatsApp.GetGroups()
+	groups, err := wa.GetGroups()
 	if err != nil {
 		h.logger.WithError(err).Error("Gagal mendapatkan daftar grup")
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
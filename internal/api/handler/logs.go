@@ -1,8 +1,14 @@
 package handler
 
 import (
+	"errors"
+	"math"
+
 	"github.com/gofiber/fiber/v2"
+	"github.com/gwenziro/bot-notify/internal/api/model"
+	"github.com/gwenziro/bot-notify/internal/repository"
 	"github.com/gwenziro/bot-notify/internal/service/log"
+	"github.com/gwenziro/bot-notify/internal/sse"
 	"github.com/gwenziro/bot-notify/internal/utils"
 )
 
@@ -20,30 +26,51 @@ func NewLogsHandler(logService *log.LogService, logger utils.LogrusEntry) *LogsH
 	}
 }
 
-// GetLogs mengambil data logs dengan paginasi dan filter
+// GetLogs mengambil data logs dengan filter, dari yang terbaru. Paginasi memakai
+// cursor opaque (cursor atau before untuk log lebih lama, after untuk log lebih baru)
+// dari nextCursor/prevCursor, atau nomor halaman jika tidak ada cursor.
 func (h *LogsHandler) GetLogs(c *fiber.Ctx) error {
 	// Parameter paginasi dan filter
 	page := c.QueryInt("page", 1)
 	limit := c.QueryInt("limit", 25)
-	level := c.Query("level", "")
-	source := c.Query("source", "")
-	search := c.Query("search", "")
-	from := c.Query("from", "")
-	to := c.Query("to", "")
+	if limit < 1 || limit > 100 {
+		limit = 25 // Default limit
+	}
+	before := c.Query("before", c.Query("cursor"))
+	after := c.Query("after", "")
+
+	q := log.NewLogQuery(c.Query("level"), c.Query("source"), c.Query("search"), c.Query("from"), c.Query("to"))
+	q.Before = before
+	q.After = after
+	q.Limit = limit
+
+	// Nomor halaman hanya dipakai jika tidak ada cursor
+	if before == "" && after == "" {
+		if page < 1 {
+			page = 1
+		}
+		q.Offset = (page - 1) * limit
+	}
 
 	// Log request dengan fields yang dipakai
 	h.logger.WithFields(utils.Fields{
 		"page":   page,
 		"limit":  limit,
-		"level":  level,
-		"source": source,
-		"search": search,
-		"from":   from,
-		"to":     to,
+		"before": before,
+		"after":  after,
+		"level":  q.Level,
+		"source": q.Source,
+		"search": q.Search,
 	}).Debug("API request: GetLogs")
 
 	// Ambil logs dari service
-	logs, totalLogs, totalPages, err := h.logService.GetPaginatedLogs(page, limit, level, source, search, from, to)
+	result, totalLogs, err := h.logService.QueryLogs(q)
+	if errors.Is(err, repository.ErrInvalidCursor) {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error":   err.Error(),
+		})
+	}
 	if err != nil {
 		h.logger.WithError(err).Error("Gagal mendapatkan logs")
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
@@ -52,15 +79,60 @@ func (h *LogsHandler) GetLogs(c *fiber.Ctx) error {
 		})
 	}
 
-	// Return response
-	return c.JSON(fiber.Map{
-		"logs":       logs,
-		"totalLogs":  totalLogs,
-		"totalPages": totalPages,
+	resp := fiber.Map{
+		"logs":       result.Logs,
+		"nextCursor": result.NextCursor,
+		"prevCursor": result.PrevCursor,
 		"page":       page,
 		"limit":      limit,
 		"success":    true,
-	})
+	}
+
+	// Total hanya dihitung pada halaman tanpa cursor
+	if totalLogs >= 0 {
+		resp["totalLogs"] = totalLogs
+		resp["totalPages"] = int(math.Ceil(float64(totalLogs) / float64(limit)))
+	}
+
+	return c.JSON(resp)
+}
+
+// StreamLogs mengirim log baru yang cocok dengan filter level, source, dan search
+// melalui Server-Sent Events. Setiap log dikirim dengan ID berupa cursor, sehingga
+// klien yang menyambung ulang dengan Last-Event-ID menerima log yang terlewat lebih dulu.
+// Event dropped menandakan ada log yang terlewat karena klien tidak mengimbangi laju
+// log, klien dapat memuat ulang dari GetLogs.
+func (h *LogsHandler) StreamLogs(c *fiber.Ctx) error {
+	q := log.NewLogQuery(c.Query("level"), c.Query("source"), c.Query("search"), "", "")
+
+	h.logger.WithFields(utils.Fields{
+		"level":  q.Level,
+		"source": q.Source,
+		"search": q.Search,
+	}).Debug("API request: StreamLogs")
+
+	// Klien yang menyambung ulang menerima log yang terlewat sejak event terakhirnya
+	var (
+		events      <-chan model.LogStreamEvent
+		unsubscribe func()
+		err         error
+	)
+	if lastID := sse.LastEventID(c); lastID != "" {
+		events, unsubscribe, err = h.logService.SubscribeFrom(q, lastID)
+		switch {
+		case errors.Is(err, repository.ErrInvalidCursor):
+			h.logger.WithField("last_event_id", lastID).Debug("Last-Event-ID tidak valid, stream dimulai dari log baru")
+		case err != nil:
+			h.logger.WithError(err).Warn("Gagal membaca log yang terlewat, stream dimulai dari log baru")
+		}
+	}
+	if events == nil {
+		events, unsubscribe = h.logService.Subscribe(q)
+	}
+
+	return sse.Stream(c, events, func(evt model.LogStreamEvent) string {
+		return evt.Event
+	}, unsubscribe)
 }
 
 // ClearLogs menghapus semua logs
@@ -114,24 +186,54 @@ func (h *LogsHandler) ExportLogs(c *fiber.Ctx) error {
 	return c.Send(fileBytes)
 }
 
-// GetLogSummary mendapatkan ringkasan log untuk dashboard
+// GetLogStats mengembalikan statistik penyimpanan log beserta kebijakan retensi yang berlaku
+func (h *LogsHandler) GetLogStats(c *fiber.Ctx) error {
+	h.logger.Debug("API request: GetLogStats")
+
+	stats, err := h.logService.RetentionStats()
+	if err != nil {
+		h.logger.WithError(err).Error("Gagal mendapatkan statistik log")
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"error":   "Gagal mengambil statistik log",
+		})
+	}
+
+	policy := h.logService.RetentionPolicy()
+	maxAge := make(fiber.Map, len(policy.MaxAge))
+	for level, age := range policy.MaxAge {
+		maxAge[level] = age.String()
+	}
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"stats":   stats,
+		"policy": fiber.Map{
+			"maxAge":     maxAge,
+			"maxEntries": policy.MaxEntries,
+			"maxBytes":   policy.MaxBytes,
+			"interval":   h.logService.RetentionInterval().String(),
+		},
+	})
+}
+
+// GetLogSummary mendapatkan ringkasan log untuk dashboard. Parameter latest menentukan
+// jumlah log terbaru yang disertakan (default 10, maksimal 100).
 func (h *LogsHandler) GetLogSummary(c *fiber.Ctx) error {
 	h.logger.Debug("API request: GetLogSummary")
 
-	// Implementasi sederhana untuk demonstrasi
-	// Di produksi, Anda harus mengambil data aktual
-	summary := fiber.Map{
-		"total_logs":    0,
-		"error_count":   0,
-		"warning_count": 0,
-		"today_logs":    0,
-		"latest": []fiber.Map{
-			{
-				"timestamp": "2023-09-10T08:45:23Z",
-				"level":     "INFO",
-				"message":   "Sistem dimulai",
-			},
-		},
+	latest := c.QueryInt("latest", 10)
+	if latest < 0 || latest > 100 {
+		latest = 10
+	}
+
+	summary, err := h.logService.GetSummary(latest)
+	if err != nil {
+		h.logger.WithError(err).Error("Gagal mendapatkan ringkasan log")
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"error":   "Gagal mengambil ringkasan log",
+		})
 	}
 
 	return c.JSON(fiber.Map{
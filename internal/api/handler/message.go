@@ -1,23 +1,42 @@
 package handler
 
 import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
 	"github.com/gofiber/fiber/v2"
+	"github.com/gwenziro/bot-notify/internal/api/middleware"
 	"github.com/gwenziro/bot-notify/internal/api/model"
+	"github.com/gwenziro/bot-notify/internal/config"
+	"github.com/gwenziro/bot-notify/internal/service/apikey"
+	"github.com/gwenziro/bot-notify/internal/service/queue"
+	"github.com/gwenziro/bot-notify/internal/service/receipt"
 	"github.com/gwenziro/bot-notify/internal/service/whatsapp/client"
 	"github.com/gwenziro/bot-notify/internal/utils"
+	"go.mau.fi/whatsmeow/types"
 )
 
 // MessageHandler menangani endpoint pesan API
 type MessageHandler struct {
-	whatsApp *client.Client
-	logger   utils.LogrusEntry
+	accounts        *client.AccountManager
+	outbox          *queue.Queue
+	receipts        *receipt.Tracker
+	logger          utils.LogrusEntry
+	maxDocumentSize int64
 }
 
 // NewMessageHandler membuat instance baru MessageHandler
-func NewMessageHandler(whatsClient *client.Client) *MessageHandler {
+func NewMessageHandler(accounts *client.AccountManager, outbox *queue.Queue, receipts *receipt.Tracker, cfg *config.Config) *MessageHandler {
 	return &MessageHandler{
-		whatsApp: whatsClient,
-		logger:   utils.ForModule("handler-message"),
+		accounts:        accounts,
+		outbox:          outbox,
+		receipts:        receipts,
+		logger:          utils.ForModule("handler-message"),
+		maxDocumentSize: cfg.WhatsApp.MaxDocumentBytes(),
 	}
 }
 
@@ -38,25 +57,49 @@ func (h *MessageHandler) SendPersonal(c *fiber.Ctx) error {
 			model.NewErrorMessageResponse("Nomor tujuan dan pesan notifikasi harus disediakan", nil, fiber.StatusBadRequest))
 	}
 
-	// Konversi nomor telepon ke JID dan kirim pesan
+	wa, err := h.accounts.Get(req.Account)
+	if err != nil {
+		return accountNotFound(c, err)
+	}
+
+	policy, err := buildRetryPolicy(h.outbox.DefaultRetryPolicy(), req.Retry)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(
+			model.NewErrorMessageResponse("Pengaturan retry tidak valid", err, fiber.StatusBadRequest))
+	}
+
+	// Konversi nomor telepon ke JID dan masukkan pesan ke antrean
 	jid := client.ParsePhoneNumber(req.PhoneNumber)
-	if err := h.whatsApp.SendMessage(jid, req.Message); err != nil {
+	msg, err := h.outbox.Enqueue(queue.TypePersonal, jid, req.Message, queue.EnqueueOptions{
+		RetryPolicy: policy,
+		SendAt:      sendAt(req.SendAt),
+		Account:     wa.Name(),
+	})
+	if err != nil {
 		h.logger.WithFields(utils.Fields{
 			"nomor": req.PhoneNumber,
 			"error": err,
-		}).Error("Gagal mengirim pesan personal")
+		}).Error("Gagal memasukkan pesan personal ke antrean")
 
 		return c.Status(fiber.StatusInternalServerError).JSON(
 			model.NewErrorMessageResponse("Gagal mengirim pesan", err, fiber.StatusInternalServerError))
 	}
 
-	h.logger.WithField("nomor", req.PhoneNumber).Info("Pesan personal berhasil dikirim")
+	h.logger.WithFields(utils.Fields{
+		"nomor": req.PhoneNumber,
+		"id":    msg.ID,
+	}).Info("Pesan personal masuk antrean")
 
-	// Kirim response sukses
-	return c.JSON(model.NewMessageResponse(
-		"Notifikasi WhatsApp terkirim!",
+	// Kirim response bahwa pesan sudah diterima dan menunggu dikirim
+	resp := model.NewQueuedMessageResponse(
+		"Notifikasi WhatsApp masuk antrean",
+		msg.ID,
+		string(msg.Status),
 		jid.String(),
-		"personal"))
+		"personal")
+	resp.Account = msg.Account
+	resp.Schedule = msg.ScheduledAt
+	return c.Status(fiber.StatusAccepted).JSON(resp)
 }
 
 // SendGroup mengirim pesan ke grup
@@ -76,23 +119,421 @@ func (h *MessageHandler) SendGroup(c *fiber.Ctx) error {
 			model.NewErrorMessageResponse("ID grup dan pesan notifikasi harus disediakan", nil, fiber.StatusBadRequest))
 	}
 
-	// Konversi ID grup ke JID dan kirim pesan
+	wa, err := h.accounts.Get(req.Account)
+	if err != nil {
+		return accountNotFound(c, err)
+	}
+
+	policy, err := buildRetryPolicy(h.outbox.DefaultRetryPolicy(), req.Retry)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(
+			model.NewErrorMessageResponse("Pengaturan retry tidak valid", err, fiber.StatusBadRequest))
+	}
+
+	// Konversi ID grup ke JID dan masukkan pesan ke antrean
 	jid := client.ParseGroupID(req.GroupID)
-	if err := h.whatsApp.SendMessage(jid, req.Message); err != nil {
+	msg, err := h.outbox.Enqueue(queue.TypeGroup, jid, req.Message, queue.EnqueueOptions{
+		RetryPolicy: policy,
+		SendAt:      sendAt(req.SendAt),
+		Account:     wa.Name(),
+	})
+	if err != nil {
 		h.logger.WithFields(utils.Fields{
 			"group": req.GroupID,
 			"error": err,
-		}).Error("Gagal mengirim pesan grup")
+		}).Error("Gagal memasukkan pesan grup ke antrean")
 
 		return c.Status(fiber.StatusInternalServerError).JSON(
 			model.NewErrorMessageResponse("Gagal mengirim pesan", err, fiber.StatusInternalServerError))
 	}
 
-	h.logger.WithField("group", req.GroupID).Info("Pesan grup berhasil dikirim")
+	h.logger.WithFields(utils.Fields{
+		"group": req.GroupID,
+		"id":    msg.ID,
+	}).Info("Pesan grup masuk antrean")
 
-	// Kirim response sukses
-	return c.JSON(model.NewMessageResponse(
-		"Notifikasi WhatsApp terkirim ke grup!",
+	// Kirim response bahwa pesan sudah diterima dan menunggu dikirim
+	resp := model.NewQueuedMessageResponse(
+		"Notifikasi WhatsApp ke grup masuk antrean",
+		msg.ID,
+		string(msg.Status),
 		jid.String(),
-		"group"))
+		"group")
+	resp.Account = msg.Account
+	resp.Schedule = msg.ScheduledAt
+	return c.Status(fiber.StatusAccepted).JSON(resp)
+}
+
+// sendAt mengembalikan waktu kirim terjadwal dari request, atau waktu kosong jika tidak diisi
+func sendAt(t *time.Time) time.Time {
+	if t == nil {
+		return time.Time{}
+	}
+	return *t
+}
+
+// buildRetryPolicy menggabungkan pengaturan retry dari request dengan kebijakan default.
+// Mengembalikan nil jika request tidak menyertakan pengaturan retry.
+func buildRetryPolicy(base queue.RetryPolicy, opts *model.RetryOptions) (*queue.RetryPolicy, error) {
+	if opts == nil {
+		return nil, nil
+	}
+
+	policy := base
+	if opts.MaxAttempts < 0 {
+		return nil, errors.New("maxAttempts tidak boleh negatif")
+	}
+	if opts.MaxAttempts > 0 {
+		policy.MaxAttempts = opts.MaxAttempts
+	}
+
+	if opts.InitialBackoff != "" {
+		d, err := time.ParseDuration(opts.InitialBackoff)
+		if err != nil || d <= 0 {
+			return nil, fmt.Errorf("initialBackoff tidak valid: %s", opts.InitialBackoff)
+		}
+		policy.InitialBackoff = d
+	}
+
+	if opts.MaxBackoff != "" {
+		d, err := time.ParseDuration(opts.MaxBackoff)
+		if err != nil || d <= 0 {
+			return nil, fmt.Errorf("maxBackoff tidak valid: %s", opts.MaxBackoff)
+		}
+		policy.MaxBackoff = d
+	}
+
+	if opts.RetryOn != nil {
+		classes, err := queue.ParseErrorClasses(opts.RetryOn)
+		if err != nil {
+			return nil, err
+		}
+		policy.RetryOn = classes
+	}
+
+	return &policy, nil
+}
+
+// GetMessage mengembalikan data dan status pesan di antrean berdasarkan ID
+func (h *MessageHandler) GetMessage(c *fiber.Ctx) error {
+	id := c.Params("id")
+
+	msg, err := h.outbox.Get(id)
+	if errors.Is(err, queue.ErrMessageNotFound) {
+		return c.Status(fiber.StatusNotFound).JSON(
+			model.NewErrorMessageResponse("Pesan tidak ditemukan", nil, fiber.StatusNotFound))
+	}
+	if err != nil {
+		h.logger.WithError(err).WithField("id", id).Error("Gagal mengambil data pesan")
+		return c.Status(fiber.StatusInternalServerError).JSON(
+			model.NewErrorMessageResponse("Gagal mengambil data pesan", err, fiber.StatusInternalServerError))
+	}
+	if scope := sendScope(string(msg.Type)); !middleware.HasScope(c, scope) {
+		return middleware.Forbidden(c, scope)
+	}
+
+	return c.JSON(fiber.Map{
+		"sukses": true,
+		"data":   msg,
+	})
+}
+
+// GetMessageStatus mengembalikan status pengiriman beserta waktu terkirim, diterima, dan dibaca.
+// ID dapat berupa ID antrean maupun ID pesan WhatsApp.
+func (h *MessageHandler) GetMessageStatus(c *fiber.Ctx) error {
+	id := c.Params("id")
+	resp := model.MessageStatusResponse{
+		Success:   true,
+		ID:        id,
+		Timestamp: time.Now(),
+	}
+
+	// Cari di antrean terlebih dahulu
+	waID := id
+	msg, err := h.outbox.Get(id)
+	switch {
+	case err == nil:
+		if scope := sendScope(string(msg.Type)); !middleware.HasScope(c, scope) {
+			return middleware.Forbidden(c, scope)
+		}
+		resp.QueueStatus = string(msg.Status)
+		resp.Status = string(msg.Status)
+		resp.Recipient = msg.Recipient
+		resp.WhatsAppID = msg.WhatsAppID
+		resp.SentAt = msg.SentAt
+		waID = msg.WhatsAppID
+	case !errors.Is(err, queue.ErrMessageNotFound):
+		h.logger.WithError(err).WithField("id", id).Error("Gagal mengambil data pesan")
+		return c.Status(fiber.StatusInternalServerError).JSON(
+			model.NewErrorMessageResponse("Gagal mengambil status pesan", err, fiber.StatusInternalServerError))
+	}
+
+	// Lengkapi dengan receipt jika pesan sudah dikirim ke WhatsApp
+	if waID != "" {
+		rec, err := h.receipts.Get(waID)
+		switch {
+		case err == nil:
+			// Pesan yang tidak melalui antrean hanya memiliki receipt, tipe dibaca dari JID penerima
+			if scope := sendScope(recipientType(rec.Recipient)); msg == nil && !middleware.HasScope(c, scope) {
+				return middleware.Forbidden(c, scope)
+			}
+			resp.WhatsAppID = rec.MessageID
+			resp.Status = string(rec.Status)
+			resp.Recipient = rec.Recipient
+			resp.SentAt = &rec.SentAt
+			resp.DeliveredAt = rec.DeliveredAt
+			resp.ReadAt = rec.ReadAt
+		case !errors.Is(err, receipt.ErrReceiptNotFound):
+			h.logger.WithError(err).WithField("id", waID).Error("Gagal mengambil receipt pesan")
+			return c.Status(fiber.StatusInternalServerError).JSON(
+				model.NewErrorMessageResponse("Gagal mengambil status pesan", err, fiber.StatusInternalServerError))
+		case msg == nil:
+			return c.Status(fiber.StatusNotFound).JSON(
+				model.NewErrorMessageResponse("Pesan tidak ditemukan", nil, fiber.StatusNotFound))
+		}
+	}
+
+	return c.JSON(resp)
+}
+
+// SendImage mengirim gambar dengan caption opsional ke nomor personal atau grup.
+// Mendukung upload multipart (field "image") maupun JSON dengan konten base64.
+func (h *MessageHandler) SendImage(c *fiber.Ctx) error {
+	var req model.ImageMessageRequest
+
+	// Parse request body (form multipart atau JSON)
+	if err := c.BodyParser(&req); err != nil {
+		h.logger.WithError(err).Error("Gagal parsing request body")
+		return c.Status(fiber.StatusBadRequest).JSON(
+			model.NewErrorMessageResponse("Format request tidak valid", err, fiber.StatusBadRequest))
+	}
+
+	// Tentukan penerima
+	jid, msgType, err := resolveRecipient(req.PhoneNumber, req.GroupID)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(
+			model.NewErrorMessageResponse(err.Error(), nil, fiber.StatusBadRequest))
+	}
+	if scope := sendScope(msgType); !middleware.HasScope(c, scope) {
+		return middleware.Forbidden(c, scope)
+	}
+
+	wa, err := h.accounts.Get(req.Account)
+	if err != nil {
+		return accountNotFound(c, err)
+	}
+
+	// Ambil konten gambar
+	payload, err := readMediaPayload(c, "image", req.Image, 0)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(
+			model.NewErrorMessageResponse("Gambar tidak valid", err, fiber.StatusBadRequest))
+	}
+	if req.MimeType != "" {
+		payload.MimeType = req.MimeType
+	}
+
+	waID, err := wa.SendImage(jid, payload.Data, payload.MimeType, req.Caption)
+	if err != nil {
+		h.logger.WithFields(utils.Fields{
+			"to":    jid.String(),
+			"error": err,
+		}).Error("Gagal mengirim gambar")
+
+		return c.Status(fiber.StatusInternalServerError).JSON(
+			model.NewErrorMessageResponse("Gagal mengirim gambar", err, fiber.StatusInternalServerError))
+	}
+
+	h.logger.WithFields(utils.Fields{
+		"to":   jid.String(),
+		"tipe": msgType,
+		"id":   waID,
+	}).Info("Gambar berhasil dikirim")
+
+	// Kirim response sukses beserta ID pesan untuk pelacakan receipt
+	resp := model.NewMessageResponse(
+		"Gambar WhatsApp terkirim!",
+		jid.String(),
+		msgType)
+	resp.ID = waID
+	resp.Account = wa.Name()
+	return c.JSON(resp)
+}
+
+// SendDocument mengirim dokumen (PDF, CSV, XLSX, dll) ke nomor personal atau grup.
+// Mendukung upload multipart (field "document") maupun JSON dengan konten base64.
+func (h *MessageHandler) SendDocument(c *fiber.Ctx) error {
+	var req model.DocumentMessageRequest
+
+	// Parse request body (form multipart atau JSON)
+	if err := c.BodyParser(&req); err != nil {
+		h.logger.WithError(err).Error("Gagal parsing request body")
+		return c.Status(fiber.StatusBadRequest).JSON(
+			model.NewErrorMessageResponse("Format request tidak valid", err, fiber.StatusBadRequest))
+	}
+
+	// Tentukan penerima
+	jid, msgType, err := resolveRecipient(req.PhoneNumber, req.GroupID)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(
+			model.NewErrorMessageResponse(err.Error(), nil, fiber.StatusBadRequest))
+	}
+	if scope := sendScope(msgType); !middleware.HasScope(c, scope) {
+		return middleware.Forbidden(c, scope)
+	}
+
+	wa, err := h.accounts.Get(req.Account)
+	if err != nil {
+		return accountNotFound(c, err)
+	}
+
+	// Ambil konten dokumen dengan batas ukuran dari konfigurasi
+	payload, err := readMediaPayload(c, "document", req.Document, h.maxDocumentSize)
+	if errors.Is(err, errMediaTooLarge) {
+		return c.Status(fiber.StatusRequestEntityTooLarge).JSON(
+			model.NewErrorMessageResponse(
+				fmt.Sprintf("Ukuran dokumen melebihi batas %d MB", h.maxDocumentSize>>20),
+				err, fiber.StatusRequestEntityTooLarge))
+	}
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(
+			model.NewErrorMessageResponse("Dokumen tidak valid", err, fiber.StatusBadRequest))
+	}
+
+	// Nilai eksplisit dari request mengesampingkan metadata upload
+	fileName := payload.FileName
+	if req.FileName != "" {
+		fileName = req.FileName
+	}
+	mimeType := payload.MimeType
+	if req.MimeType != "" {
+		mimeType = req.MimeType
+	}
+
+	waID, err := wa.SendDocument(jid, payload.Data, fileName, mimeType, req.Caption)
+	if err != nil {
+		h.logger.WithFields(utils.Fields{
+			"to":        jid.String(),
+			"file_name": fileName,
+			"error":     err,
+		}).Error("Gagal mengirim dokumen")
+
+		return c.Status(fiber.StatusInternalServerError).JSON(
+			model.NewErrorMessageResponse("Gagal mengirim dokumen", err, fiber.StatusInternalServerError))
+	}
+
+	h.logger.WithFields(utils.Fields{
+		"to":        jid.String(),
+		"tipe":      msgType,
+		"file_name": fileName,
+		"id":        waID,
+	}).Info("Dokumen berhasil dikirim")
+
+	// Kirim response sukses beserta ID pesan untuk pelacakan receipt
+	resp := model.NewMessageResponse(
+		"Dokumen WhatsApp terkirim!",
+		jid.String(),
+		msgType)
+	resp.ID = waID
+	resp.Account = wa.Name()
+	return c.JSON(resp)
+}
+
+// resolveRecipient mengkonversi nomor telepon atau ID grup menjadi JID tujuan
+func resolveRecipient(phoneNumber, groupID string) (types.JID, string, error) {
+	switch {
+	case phoneNumber != "" && groupID != "":
+		return types.JID{}, "", errors.New("Pilih salah satu: nomor tujuan atau ID grup")
+	case phoneNumber != "":
+		return client.ParsePhoneNumber(phoneNumber), "personal", nil
+	case groupID != "":
+		return client.ParseGroupID(groupID), "group", nil
+	default:
+		return types.JID{}, "", errors.New("Nomor tujuan atau ID grup harus disediakan")
+	}
+}
+
+// sendScope mengembalikan scope API key yang dibutuhkan untuk mengirim ke tipe penerima tertentu
+func sendScope(msgType string) apikey.Scope {
+	if msgType == "group" {
+		return apikey.ScopeSendGroup
+	}
+	return apikey.ScopeSendPersonal
+}
+
+// recipientType mengembalikan tipe penerima dari JID, "group" untuk JID grup
+func recipientType(jid string) string {
+	if strings.HasSuffix(jid, "@"+types.GroupServer) {
+		return string(queue.TypeGroup)
+	}
+	return string(queue.TypePersonal)
+}
+
+// errMediaTooLarge dikembalikan ketika ukuran media melebihi batas yang diizinkan
+var errMediaTooLarge = errors.New("ukuran media melebihi batas maksimum")
+
+// mediaPayload berisi konten media yang dibaca dari request
+type mediaPayload struct {
+	Data     []byte
+	MimeType string
+	FileName string
+}
+
+// readMediaPayload membaca konten media dari file multipart atau string base64.
+// MIME type dan nama file berasal dari header upload atau data URL, bisa kosong.
+// maxSize <= 0 berarti tanpa batas ukuran.
+func readMediaPayload(c *fiber.Ctx, field string, encoded string, maxSize int64) (*mediaPayload, error) {
+	// Prioritaskan file multipart jika ada
+	if fileHeader, err := c.FormFile(field); err == nil {
+		if maxSize > 0 && fileHeader.Size > maxSize {
+			return nil, errMediaTooLarge
+		}
+
+		file, err := fileHeader.Open()
+		if err != nil {
+			return nil, err
+		}
+		defer file.Close()
+
+		data, err := io.ReadAll(file)
+		if err != nil {
+			return nil, err
+		}
+
+		return &mediaPayload{
+			Data:     data,
+			MimeType: fileHeader.Header.Get("Content-Type"),
+			FileName: fileHeader.Filename,
+		}, nil
+	}
+
+	if encoded == "" {
+		return nil, errors.New("konten media harus disediakan")
+	}
+
+	// Dukung format data URL: data:image/png;base64,xxxx
+	var mimeType string
+	if strings.HasPrefix(encoded, "data:") {
+		if idx := strings.Index(encoded, ","); idx != -1 {
+			meta := strings.TrimPrefix(encoded[:idx], "data:")
+			mimeType = strings.TrimSuffix(meta, ";base64")
+			encoded = encoded[idx+1:]
+		}
+	}
+
+	// Tolak lebih awal berdasarkan perkiraan ukuran hasil decode
+	if maxSize > 0 && int64(base64.StdEncoding.DecodedLen(len(encoded))) > maxSize+2 {
+		return nil, errMediaTooLarge
+	}
+
+	data, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, errors.New("konten base64 tidak valid")
+	}
+
+	if maxSize > 0 && int64(len(data)) > maxSize {
+		return nil, errMediaTooLarge
+	}
+
+	return &mediaPayload{Data: data, MimeType: mimeType}, nil
 }
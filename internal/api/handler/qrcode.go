@@ -1,71 +1,105 @@
 package handler
 
 import (
+	"errors"
+	"time"
+
 	"github.com/gofiber/fiber/v2"
 	"github.com/gwenziro/bot-notify/internal/service/whatsapp/client"
 	"github.com/gwenziro/bot-notify/internal/service/whatsapp/session"
+	"github.com/gwenziro/bot-notify/internal/sse"
 	"github.com/gwenziro/bot-notify/internal/utils"
 )
 
 // QRCodeHandler menangani endpoint QR code API
 type QRCodeHandler struct {
-	whatsApp   *client.Client
-	sessionMgr *session.Manager
-	logger     utils.LogrusEntry
-	maxAgeMins int
+	accounts *client.AccountManager
+	logger   utils.LogrusEntry
 }
 
 // NewQRCodeHandler membuat instance baru QRCodeHandler
-func NewQRCodeHandler(whatsClient *client.Client) *QRCodeHandler {
+func NewQRCodeHandler(accounts *client.AccountManager) *QRCodeHandler {
 	return &QRCodeHandler{
-		whatsApp:   whatsClient,
-		sessionMgr: whatsClient.SessionManager,
-		logger:     utils.ForModule("handler-qrcode"),
-		maxAgeMins: 5, // QR code kedaluwarsa setelah 5 menit
+		accounts: accounts,
+		logger:   utils.ForModule("handler-qrcode"),
 	}
 }
 
 // GetStatus mengembalikan status QR code
 func (h *QRCodeHandler) GetStatus(c *fiber.Ctx) error {
-	// Dapatkan QR handler dari session manager
-	qrHandler := h.sessionMgr.GetQRHandler()
-	if qrHandler == nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"sukses":    false,
-			"pesan":     "QR handler tidak tersedia",
-			"available": false,
-		})
+	wa, err := h.accounts.Get(accountName(c))
+	if err != nil {
+		return accountNotFound(c, err)
 	}
 
-	// Dapatkan data QR code
-	data, timestamp := qrHandler.GetQRCodeData()
-	hasQR := data != ""
-	isExpired := qrHandler.IsQRCodeExpired(h.maxAgeMins)
+	state := wa.SessionManager.GetQRHandler().Current()
+	available := state.Available(time.Now())
 
-	return c.JSON(fiber.Map{
+	response := fiber.Map{
 		"sukses":    true,
-		"available": hasQR && !isExpired,
-		"expired":   isExpired,
-		"timestamp": timestamp,
-	})
+		"akun":      wa.Name(),
+		"event":     state.Event,
+		"available": available,
+		"expired":   state.Event == session.QREventCode && !available,
+		"loggedIn":  wa.IsLoggedIn(),
+		"timestamp": state.CreatedAt,
+	}
+
+	// Sertakan isi QR code agar klien seperti CLI dapat menampilkannya sendiri
+	if available {
+		response["code"] = state.Code
+		response["expiresAt"] = state.ExpiresAt
+	}
+
+	return c.JSON(response)
 }
 
-// GetImage mengembalikan gambar QR code
+// GetImage mengembalikan gambar QR code yang berlaku, PNG secara default atau SVG dengan ?format=svg
 func (h *QRCodeHandler) GetImage(c *fiber.Ctx) error {
-	// Dapatkan QR handler dari session manager
-	qrHandler := h.sessionMgr.GetQRHandler()
-	if qrHandler == nil {
-		return c.Status(fiber.StatusInternalServerError).SendString("QR handler tidak tersedia")
+	wa, err := h.accounts.Get(accountName(c))
+	if err != nil {
+		return accountNotFound(c, err)
 	}
+	qrHandler := wa.SessionManager.GetQRHandler()
+
+	var (
+		image       []byte
+		contentType string
+	)
 
-	// Dapatkan path QR code
-	qrPath := qrHandler.GetQRCodePath()
+	switch c.Query("format", "png") {
+	case "svg":
+		image, err = qrHandler.SVG()
+		contentType = "image/svg+xml"
+	case "png":
+		image, err = qrHandler.PNG(256)
+		contentType = "image/png"
+	default:
+		return c.Status(fiber.StatusBadRequest).SendString("Format gambar harus png atau svg")
+	}
 
-	// Jika QR code kedaluwarsa atau tidak ada, return 404
-	if qrHandler.IsQRCodeExpired(h.maxAgeMins) {
+	if errors.Is(err, session.ErrNoQRCode) {
 		return c.Status(fiber.StatusNotFound).SendString("QR code kedaluwarsa atau tidak tersedia")
 	}
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).SendString(err.Error())
+	}
+
+	// QR code berganti sekitar setiap 20 detik, jangan disimpan di cache
+	c.Set(fiber.HeaderCacheControl, "no-store")
+	c.Set(fiber.HeaderContentType, contentType)
+	return c.Send(image)
+}
+
+// Events mengirim QR code baru, pairing berhasil, dan timeout melalui Server-Sent Events
+func (h *QRCodeHandler) Events(c *fiber.Ctx) error {
+	wa, err := h.accounts.Get(accountName(c))
+	if err != nil {
+		return accountNotFound(c, err)
+	}
 
-	// Send QR code sebagai file
-	return c.SendFile(qrPath)
+	events, unsubscribe := wa.SessionManager.GetQRHandler().Subscribe()
+	return sse.Stream(c, events, func(state session.QRState) string {
+		return state.Event
+	}, unsubscribe)
 }
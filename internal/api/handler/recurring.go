@@ -0,0 +1,159 @@
+package handler
+
+import (
+	"errors"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/gwenziro/bot-notify/internal/api/model"
+	"github.com/gwenziro/bot-notify/internal/service/queue"
+	"github.com/gwenziro/bot-notify/internal/service/recurring"
+	"github.com/gwenziro/bot-notify/internal/service/whatsapp/client"
+	"github.com/gwenziro/bot-notify/internal/utils"
+)
+
+// RecurringHandler menangani endpoint job pesan berulang API
+type RecurringHandler struct {
+	scheduler *recurring.Scheduler
+	accounts  *client.AccountManager
+	logger    utils.LogrusEntry
+}
+
+// NewRecurringHandler membuat instance baru RecurringHandler
+func NewRecurringHandler(scheduler *recurring.Scheduler, accounts *client.AccountManager) *RecurringHandler {
+	return &RecurringHandler{
+		scheduler: scheduler,
+		accounts:  accounts,
+		logger:    utils.ForModule("handler-recurring"),
+	}
+}
+
+// List mengembalikan semua job pesan berulang
+func (h *RecurringHandler) List(c *fiber.Ctx) error {
+	jobs, err := h.scheduler.List()
+	if err != nil {
+		h.logger.WithError(err).Error("Gagal mengambil daftar job pesan berulang")
+		return c.Status(fiber.StatusInternalServerError).JSON(
+			model.NewErrorMessageResponse("Gagal mengambil daftar job", err, fiber.StatusInternalServerError))
+	}
+
+	return c.JSON(fiber.Map{
+		"sukses": true,
+		"total":  len(jobs),
+		"data":   jobs,
+	})
+}
+
+// Get mengembalikan detail satu job
+func (h *RecurringHandler) Get(c *fiber.Ctx) error {
+	id := c.Params("id")
+
+	job, err := h.scheduler.Get(id)
+	if err != nil {
+		return h.handleError(c, id, "Gagal mengambil job", err)
+	}
+
+	return c.JSON(fiber.Map{
+		"sukses": true,
+		"data":   job,
+	})
+}
+
+// Create membuat job pesan berulang baru
+func (h *RecurringHandler) Create(c *fiber.Ctx) error {
+	spec, err := h.parseSpec(c)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(
+			model.NewErrorMessageResponse("Data job tidak valid", err, fiber.StatusBadRequest))
+	}
+
+	job, err := h.scheduler.Create(spec)
+	if err != nil {
+		return h.handleError(c, "", "Gagal membuat job", err)
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"sukses": true,
+		"pesan":  "Job pesan berulang berhasil dibuat",
+		"data":   job,
+	})
+}
+
+// Update mengubah job pesan berulang
+func (h *RecurringHandler) Update(c *fiber.Ctx) error {
+	id := c.Params("id")
+
+	spec, err := h.parseSpec(c)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(
+			model.NewErrorMessageResponse("Data job tidak valid", err, fiber.StatusBadRequest))
+	}
+
+	job, err := h.scheduler.Update(id, spec)
+	if err != nil {
+		return h.handleError(c, id, "Gagal mengubah job", err)
+	}
+
+	return c.JSON(fiber.Map{
+		"sukses": true,
+		"pesan":  "Job pesan berulang berhasil diubah",
+		"data":   job,
+	})
+}
+
+// Delete menghapus job pesan berulang
+func (h *RecurringHandler) Delete(c *fiber.Ctx) error {
+	id := c.Params("id")
+
+	if err := h.scheduler.Delete(id); err != nil {
+		return h.handleError(c, id, "Gagal menghapus job", err)
+	}
+
+	return c.JSON(fiber.Map{
+		"sukses": true,
+		"pesan":  "Job pesan berulang berhasil dihapus",
+	})
+}
+
+// parseSpec membaca request body menjadi spesifikasi job
+func (h *RecurringHandler) parseSpec(c *fiber.Ctx) (recurring.JobSpec, error) {
+	var req model.RecurringJobRequest
+	if err := c.BodyParser(&req); err != nil {
+		return recurring.JobSpec{}, err
+	}
+
+	jid, msgType, err := resolveRecipient(req.PhoneNumber, req.GroupID)
+	if err != nil {
+		return recurring.JobSpec{}, err
+	}
+
+	if req.Account != "" {
+		if _, err := h.accounts.Get(req.Account); err != nil {
+			return recurring.JobSpec{}, err
+		}
+	}
+
+	spec := recurring.JobSpec{
+		Name:      req.Name,
+		Schedule:  req.Schedule,
+		Timezone:  req.Timezone,
+		Account:   req.Account,
+		Type:      queue.MessageType(msgType),
+		Recipient: jid,
+		Message:   req.Message,
+		Enabled:   req.Enabled == nil || *req.Enabled,
+	}
+
+	return spec, spec.Validate()
+}
+
+// handleError mengirim respons error sesuai jenis error dari scheduler
+func (h *RecurringHandler) handleError(c *fiber.Ctx, id, message string, err error) error {
+	if errors.Is(err, recurring.ErrJobNotFound) {
+		return c.Status(fiber.StatusNotFound).JSON(
+			model.NewErrorMessageResponse("Job tidak ditemukan", nil, fiber.StatusNotFound))
+	}
+
+	h.logger.WithError(err).WithField("id", id).Error(message)
+	return c.Status(fiber.StatusInternalServerError).JSON(
+		model.NewErrorMessageResponse(message, err, fiber.StatusInternalServerError))
+}
@@ -0,0 +1,133 @@
+package handler
+
+import (
+	"errors"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/gwenziro/bot-notify/internal/api/middleware"
+	"github.com/gwenziro/bot-notify/internal/api/model"
+	"github.com/gwenziro/bot-notify/internal/service/queue"
+	"github.com/gwenziro/bot-notify/internal/utils"
+)
+
+// ScheduledHandler menangani endpoint pesan terjadwal API
+type ScheduledHandler struct {
+	outbox *queue.Queue
+	logger utils.LogrusEntry
+}
+
+// NewScheduledHandler membuat instance baru ScheduledHandler
+func NewScheduledHandler(outbox *queue.Queue) *ScheduledHandler {
+	return &ScheduledHandler{
+		outbox: outbox,
+		logger: utils.ForModule("handler-scheduled"),
+	}
+}
+
+// List mengembalikan pesan terjadwal yang belum dikirim, terbatas pada tipe penerima
+// yang boleh dikirimi oleh API key
+func (h *ScheduledHandler) List(c *fiber.Ctx) error {
+	all, err := h.outbox.ListScheduled()
+	if err != nil {
+		h.logger.WithError(err).Error("Gagal mengambil daftar pesan terjadwal")
+		return c.Status(fiber.StatusInternalServerError).JSON(
+			model.NewErrorMessageResponse("Gagal mengambil daftar pesan terjadwal", err, fiber.StatusInternalServerError))
+	}
+
+	messages := make([]*queue.OutboundMessage, 0, len(all))
+	for _, msg := range all {
+		if middleware.HasScope(c, sendScope(string(msg.Type))) {
+			messages = append(messages, msg)
+		}
+	}
+
+	return c.JSON(fiber.Map{
+		"sukses": true,
+		"total":  len(messages),
+		"data":   messages,
+	})
+}
+
+// Reschedule mengubah waktu kirim pesan terjadwal
+func (h *ScheduledHandler) Reschedule(c *fiber.Ctx) error {
+	id := c.Params("id")
+
+	var req model.RescheduleRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(
+			model.NewErrorMessageResponse("Format request tidak valid", err, fiber.StatusBadRequest))
+	}
+
+	if !req.SendAt.After(time.Now()) {
+		return c.Status(fiber.StatusBadRequest).JSON(
+			model.NewErrorMessageResponse("Waktu kirim harus di masa depan", nil, fiber.StatusBadRequest))
+	}
+
+	if ok, err := h.checkScope(c, id); !ok {
+		return err
+	}
+
+	msg, err := h.outbox.Reschedule(id, req.SendAt)
+	if err != nil {
+		return h.handleError(c, id, "Gagal mengubah jadwal pesan", err)
+	}
+
+	resp := model.NewQueuedMessageResponse(
+		"Jadwal pesan berhasil diubah",
+		msg.ID,
+		string(msg.Status),
+		msg.Recipient,
+		string(msg.Type))
+	resp.Schedule = msg.ScheduledAt
+	return c.JSON(resp)
+}
+
+// Cancel membatalkan pesan terjadwal
+func (h *ScheduledHandler) Cancel(c *fiber.Ctx) error {
+	id := c.Params("id")
+	if ok, err := h.checkScope(c, id); !ok {
+		return err
+	}
+
+	msg, err := h.outbox.Cancel(id)
+	if err != nil {
+		return h.handleError(c, id, "Gagal membatalkan pesan terjadwal", err)
+	}
+
+	return c.JSON(model.NewQueuedMessageResponse(
+		"Pesan terjadwal berhasil dibatalkan",
+		msg.ID,
+		string(msg.Status),
+		msg.Recipient,
+		string(msg.Type)))
+}
+
+// checkScope memastikan API key boleh mengirim ke tipe penerima pesan. Jika tidak, respons
+// error sudah dikirim dan ok bernilai false.
+func (h *ScheduledHandler) checkScope(c *fiber.Ctx, id string) (ok bool, err error) {
+	msg, err := h.outbox.Get(id)
+	if err != nil {
+		return false, h.handleError(c, id, "Gagal mengambil data pesan", err)
+	}
+	if scope := sendScope(string(msg.Type)); !middleware.HasScope(c, scope) {
+		return false, middleware.Forbidden(c, scope)
+	}
+	return true, nil
+}
+
+// handleError mengirim respons error sesuai jenis error dari antrean
+func (h *ScheduledHandler) handleError(c *fiber.Ctx, id, message string, err error) error {
+	switch {
+	case errors.Is(err, queue.ErrMessageNotFound):
+		return c.Status(fiber.StatusNotFound).JSON(
+			model.NewErrorMessageResponse("Pesan tidak ditemukan", nil, fiber.StatusNotFound))
+	case errors.Is(err, queue.ErrNotScheduled), errors.Is(err, queue.ErrMessageBusy):
+		return c.Status(fiber.StatusConflict).JSON(
+			model.NewErrorMessageResponse(message, err, fiber.StatusConflict))
+	}
+
+	h.logger.WithError(err).WithField("id", id).Error(message)
+	return c.Status(fiber.StatusInternalServerError).JSON(
+		model.NewErrorMessageResponse(message, err, fiber.StatusInternalServerError))
+}
@@ -11,23 +11,28 @@ import (
 
 // StatusHandler menangani endpoint status API
 type StatusHandler struct {
-	whatsApp *client.Client
+	accounts *client.AccountManager
 	logger   utils.LogrusEntry
 	version  string
 }
 
 // NewStatusHandler membuat instance baru StatusHandler
-func NewStatusHandler(whatsClient *client.Client) *StatusHandler {
+func NewStatusHandler(accounts *client.AccountManager) *StatusHandler {
 	return &StatusHandler{
-		whatsApp: whatsClient,
+		accounts: accounts,
 		logger:   utils.ForModule("handler-status"),
 		version:  "1.0.0", // Versi API
 	}
 }
 
-// GetStatus mengembalikan status koneksi WhatsApp
+// GetStatus mengembalikan status koneksi WhatsApp akun default, atau akun dari ?account=
 func (h *StatusHandler) GetStatus(c *fiber.Ctx) error {
-	state := h.whatsApp.GetConnectionState()
+	wa, err := h.accounts.Get(accountName(c))
+	if err != nil {
+		return accountNotFound(c, err)
+	}
+
+	state := wa.GetConnectionState()
 
 	// Konversi ke model
 	status := model.ConnectionStatus{
@@ -40,6 +45,7 @@ func (h *StatusHandler) GetStatus(c *fiber.Ctx) error {
 
 	response := model.StatusResponse{
 		Success: true,
+		Account: wa.Name(),
 		Status:  string(state.Status),
 		Details: status,
 		Time:    time.Now(),
@@ -1,30 +1,91 @@
 package middleware
 
 import (
+	"errors"
 	"strings"
+	"time"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/gofiber/fiber/v2/middleware/session"
 	"github.com/gwenziro/bot-notify/internal/config"
+	"github.com/gwenziro/bot-notify/internal/service/apikey"
 	"github.com/gwenziro/bot-notify/internal/utils"
 )
 
+// apiKeyLocal adalah key c.Locals untuk API key yang terautentikasi
+const apiKeyLocal = "api_key"
+
+// legacyKey mewakili akses via access token utama, session, atau cookie auto_login.
+// Akses ini setara dengan scope admin.
+var legacyKey = &apikey.Key{
+	Name:   "access-token",
+	Scopes: []apikey.Scope{apikey.ScopeAdmin},
+}
+
 // APIAuthMiddleware mengelola autentikasi untuk API endpoints
 type APIAuthMiddleware struct {
-	config       *config.Config
+	config       *config.Live
 	sessionStore *session.Store
+	keys         *apikey.Store
 	logger       utils.LogrusEntry
 }
 
 // NewAPIAuthMiddleware membuat instance baru APIAuthMiddleware
-func NewAPIAuthMiddleware(cfg *config.Config, sessionStore *session.Store) *APIAuthMiddleware {
+func NewAPIAuthMiddleware(cfg *config.Live, sessionStore *session.Store, keys *apikey.Store) *APIAuthMiddleware {
 	return &APIAuthMiddleware{
 		config:       cfg,
 		sessionStore: sessionStore,
+		keys:         keys,
 		logger:       utils.ForModule("api-auth-middleware"),
 	}
 }
 
+// KeyFromContext mengembalikan API key yang terautentikasi pada request, atau nil
+func KeyFromContext(c *fiber.Ctx) *apikey.Key {
+	key, _ := c.Locals(apiKeyLocal).(*apikey.Key)
+	return key
+}
+
+// HasScope memeriksa apakah request terautentikasi dengan scope tertentu
+func HasScope(c *fiber.Ctx, scope apikey.Scope) bool {
+	key := KeyFromContext(c)
+	return key != nil && key.HasScope(scope)
+}
+
+// RequireScope middleware yang mewajibkan API key memiliki minimal satu dari scope yang diberikan.
+// Harus dipasang setelah RequireAuth.
+func (m *APIAuthMiddleware) RequireScope(scopes ...apikey.Scope) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		for _, scope := range scopes {
+			if HasScope(c, scope) {
+				return c.Next()
+			}
+		}
+
+		fields := utils.Fields{
+			"path":     c.Path(),
+			"ip":       c.IP(),
+			"required": scopes,
+		}
+		if key := KeyFromContext(c); key != nil {
+			fields["key"] = key.Name
+		}
+		m.logger.Warn("API access denied: Insufficient scope", fields)
+
+		return Forbidden(c, scopes...)
+	}
+}
+
+// Forbidden mengirim respons 403 untuk request yang tidak memiliki scope yang dibutuhkan
+func Forbidden(c *fiber.Ctx, scopes ...apikey.Scope) error {
+	return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+		"success":        false,
+		"error":          "Insufficient scope",
+		"required_scope": scopes,
+		"code":           fiber.StatusForbidden,
+	})
+}
+
 // RequireAuth middleware untuk API endpoints yang mendukung multiple authentication methods
 func (m *APIAuthMiddleware) RequireAuth() fiber.Handler {
 	return func(c *fiber.Ctx) error {
@@ -39,11 +100,13 @@ func (m *APIAuthMiddleware) RequireAuth() fiber.Handler {
 		if authHeader != "" {
 			if strings.HasPrefix(authHeader, "Bearer ") {
 				token := strings.TrimPrefix(authHeader, "Bearer ")
-				if m.validateToken(token) {
+				if key := m.authenticate(c, token); key != nil {
 					m.logger.Debug("Authentication successful via Bearer token", utils.Fields{
 						"path": c.Path(),
 						"ip":   c.IP(),
+						"key":  key.Name,
 					})
+					c.Locals(apiKeyLocal, key)
 					return c.Next()
 				}
 			}
@@ -52,29 +115,35 @@ func (m *APIAuthMiddleware) RequireAuth() fiber.Handler {
 		// Method 2: Check X-Access-Token header
 		accessToken := c.Get("X-Access-Token")
 		if accessToken != "" {
-			if m.validateToken(accessToken) {
+			if key := m.authenticate(c, accessToken); key != nil {
 				m.logger.Debug("Authentication successful via X-Access-Token", utils.Fields{
 					"path": c.Path(),
 					"ip":   c.IP(),
+					"key":  key.Name,
 				})
+				c.Locals(apiKeyLocal, key)
 				return c.Next()
 			}
 		}
 
 		// Method 3: Check session (for web-based API calls)
-		sess, err := m.sessionStore.Get(c)
-		if err == nil {
-			authenticated := sess.Get("authenticated")
-			authToken := sess.Get("auth_token")
-
-			if authenticated == true && authToken != nil {
-				token, ok := authToken.(string)
-				if ok && m.validateToken(token) {
-					m.logger.Debug("Authentication successful via session", utils.Fields{
-						"path": c.Path(),
-						"ip":   c.IP(),
-					})
-					return c.Next()
+		hasSession := false
+		if m.sessionStore != nil {
+			if sess, err := m.sessionStore.Get(c); err == nil {
+				hasSession = true
+				authenticated := sess.Get("authenticated")
+				authToken := sess.Get("auth_token")
+
+				if authenticated == true && authToken != nil {
+					token, ok := authToken.(string)
+					if ok && m.validateToken(token) {
+						m.logger.Debug("Authentication successful via session", utils.Fields{
+							"path": c.Path(),
+							"ip":   c.IP(),
+						})
+						c.Locals(apiKeyLocal, legacyKey)
+						return c.Next()
+					}
 				}
 			}
 		}
@@ -87,6 +156,7 @@ func (m *APIAuthMiddleware) RequireAuth() fiber.Handler {
 					"path": c.Path(),
 					"ip":   c.IP(),
 				})
+				c.Locals(apiKeyLocal, legacyKey)
 				return c.Next()
 			}
 		}
@@ -97,7 +167,7 @@ func (m *APIAuthMiddleware) RequireAuth() fiber.Handler {
 			"path":             c.Path(),
 			"has_auth_header":  authHeader != "",
 			"has_access_token": accessToken != "",
-			"has_session":      err == nil,
+			"has_session":      hasSession,
 			"has_cookie":       autoLoginToken != "",
 		})
 
@@ -109,14 +179,48 @@ func (m *APIAuthMiddleware) RequireAuth() fiber.Handler {
 	}
 }
 
+// authenticate memvalidasi token dari header sebagai access token utama atau API key bernama.
+// Token lama hasil rotasi masih diterima selama masa tenggang.
+func (m *APIAuthMiddleware) authenticate(c *fiber.Ctx, token string) *apikey.Key {
+	if m.validateToken(token) {
+		return legacyKey
+	}
+	if auth := m.config.Get().Auth; auth.AcceptsToken(token, time.Now()) {
+		m.logger.Warn("API diakses dengan token lama yang masih dalam masa tenggang rotasi", utils.Fields{
+			"path":       c.Path(),
+			"ip":         c.IP(),
+			"expires_at": auth.PreviousTokenExpiresAt,
+		})
+		return legacyKey
+	}
+	if m.keys == nil {
+		return nil
+	}
+
+	key, err := m.keys.Authenticate(token)
+	if err != nil {
+		if errors.Is(err, apikey.ErrKeyExpired) {
+			m.logger.Warn("API access denied: API key expired", utils.Fields{
+				"path": c.Path(),
+				"ip":   c.IP(),
+			})
+		} else if !errors.Is(err, apikey.ErrKeyNotFound) {
+			m.logger.WithError(err).Error("Gagal memvalidasi API key")
+		}
+		return nil
+	}
+	return key
+}
+
 // validateToken memvalidasi token dengan secure comparison
 func (m *APIAuthMiddleware) validateToken(token string) bool {
-	if token == "" || m.config.Auth.AccessToken == "" {
+	accessToken := m.config.Get().Auth.AccessToken
+	if token == "" || accessToken == "" {
 		return false
 	}
 
 	// Secure string comparison untuk mencegah timing attacks
-	return m.secureCompare(token, m.config.Auth.AccessToken)
+	return m.secureCompare(token, accessToken)
 }
 
 // secureCompare membandingkan dua string dengan waktu konstan
@@ -0,0 +1,109 @@
+package middleware
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/gwenziro/bot-notify/internal/config"
+)
+
+// TestReloadDuringRequests me-reload konfigurasi berulang kali sambil request API
+// berjalan. Jalankan dengan -race untuk memastikan pembaca tidak berlomba dengan reload.
+func TestReloadDuringRequests(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "config.yaml")
+
+	token := func(i int) string {
+		return fmt.Sprintf("token-%02d-abcdefghijklmnopqrstuvwxyz0123456789", i)
+	}
+	writeConfig := func(accessToken string) {
+		t.Helper()
+		data := fmt.Sprintf(`server:
+  dev_mode: true
+whatsapp:
+  store_dir: %[1]s/whatsapp
+  max_retry: %[3]d
+auth:
+  access_token: %[2]s
+  token_secret: zyxwvutsrqponmlkjihgfedcba9876543210
+  session_dir: %[1]s/sessions
+storage:
+  path: %[1]s/storage
+logging:
+  file: %[1]s/logs/app.log
+`, dir, accessToken, len(accessToken)%7)
+		if err := os.WriteFile(path, []byte(data), 0644); err != nil {
+			t.Fatalf("gagal menulis konfigurasi: %v", err)
+		}
+	}
+
+	writeConfig(token(0))
+	loader := config.NewLoader()
+	loader.Path = path
+	loader.LookupEnv = func(string) (string, bool) { return "", false }
+
+	cfg, err := loader.Load()
+	if err != nil {
+		t.Fatalf("gagal memuat konfigurasi: %v", err)
+	}
+	live := config.NewLive(cfg)
+	watcher := config.NewWatcher(live, loader)
+
+	auth := NewAPIAuthMiddleware(live, nil, nil)
+	app := fiber.New()
+	app.Get("/ping", auth.RequireAuth(), func(c *fiber.Ctx) error {
+		return c.SendStatus(fiber.StatusNoContent)
+	})
+
+	request := func(accessToken string) int {
+		req := httptest.NewRequest(http.MethodGet, "/ping", nil)
+		req.Header.Set("X-Access-Token", accessToken)
+		resp, err := app.Test(req, -1)
+		if err != nil {
+			t.Errorf("request gagal: %v", err)
+			return 0
+		}
+		resp.Body.Close()
+		return resp.StatusCode
+	}
+
+	const reloads = 30
+	stop := make(chan struct{})
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for n := 0; ; n++ {
+				select {
+				case <-stop:
+					return
+				default:
+				}
+				request(token(n % reloads))
+			}
+		}()
+	}
+
+	for i := 1; i < reloads; i++ {
+		writeConfig(token(i))
+		if err := watcher.Reload("test"); err != nil {
+			t.Fatalf("reload gagal: %v", err)
+		}
+	}
+	close(stop)
+	wg.Wait()
+
+	if got := request(token(reloads - 1)); got != fiber.StatusNoContent {
+		t.Errorf("token terbaru ditolak: status %d", got)
+	}
+	if got := request(token(0)); got != fiber.StatusUnauthorized {
+		t.Errorf("token lama masih diterima: status %d", got)
+	}
+}
@@ -0,0 +1,6 @@
+package model
+
+// AccountRequest untuk request API menambah akun WhatsApp
+type AccountRequest struct {
+	Name string `json:"name" validate:"required"` // Huruf kecil, angka, - dan _, maksimal 32 karakter
+}
@@ -0,0 +1,10 @@
+package model
+
+import "time"
+
+// CreateAPIKeyRequest untuk request API membuat API key baru
+type CreateAPIKeyRequest struct {
+	Name      string     `json:"name" validate:"required"`
+	Scopes    []string   `json:"scopes" validate:"required"` // send:personal, send:group, groups:read, logs:read, admin
+	ExpiresAt *time.Time `json:"expiresAt,omitempty"`        // Kosong berarti tidak pernah kedaluwarsa
+}
@@ -24,3 +24,17 @@ func NewConnectionResponse(success bool, message string, status string) Connecti
 		Status:    status,
 	}
 }
+
+// PairPhoneRequest untuk request pairing dengan kode nomor telepon
+type PairPhoneRequest struct {
+	PhoneNumber string `json:"phoneNumber" validate:"required"`
+}
+
+// PairPhoneResponse berisi kode pairing yang dimasukkan di WhatsApp
+type PairPhoneResponse struct {
+	Success     bool      `json:"sukses"`
+	Message     string    `json:"pesan"`
+	Code        string    `json:"kode"`
+	PhoneNumber string    `json:"nomor"`
+	Timestamp   time.Time `json:"waktu"`
+}
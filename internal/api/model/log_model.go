@@ -36,6 +36,53 @@ const (
 	LogSourceDatabase LogSource = "DATABASE"
 )
 
+// LogQuery adalah filter dan posisi paginasi untuk membaca log, diurutkan dari yang terbaru
+type LogQuery struct {
+	Level  string
+	Source string
+	Search string
+	From   time.Time // Zero berarti tanpa batas bawah
+	To     time.Time // Eksklusif, zero berarti tanpa batas atas
+	// Before mengambil log yang lebih lama dari cursor (halaman berikutnya)
+	Before string
+	// After mengambil log yang lebih baru dari cursor (halaman sebelumnya)
+	After string
+	// Offset melewati sejumlah log yang cocok, dipakai untuk paginasi berbasis nomor halaman
+	Offset int
+	Limit  int
+}
+
+// LogPage adalah satu halaman hasil LogQuery beserta cursor halaman sebelum dan sesudahnya
+type LogPage struct {
+	Logs []*Log `json:"logs"`
+	// NextCursor diisi ke before untuk mengambil log yang lebih lama
+	NextCursor string `json:"nextCursor,omitempty"`
+	// PrevCursor diisi ke after untuk mengambil log yang lebih baru
+	PrevCursor string `json:"prevCursor,omitempty"`
+}
+
+// LogRetentionPolicy adalah batas penyimpanan log, nilai 0 berarti tanpa batas
+type LogRetentionPolicy struct {
+	// MaxAge adalah usia maksimum log per level, dengan key LogLevel
+	MaxAge     map[string]time.Duration
+	MaxEntries int
+	MaxBytes   int64
+}
+
+// LogRetentionStats adalah statistik log yang tersimpan dan hasil pruning terakhir
+type LogRetentionStats struct {
+	TotalEntries int            `json:"totalEntries"`
+	TotalBytes   int64          `json:"totalBytes"`
+	ByLevel      map[string]int `json:"byLevel"`
+	OldestAt     *time.Time     `json:"oldestAt,omitempty"`
+	NewestAt     *time.Time     `json:"newestAt,omitempty"`
+	// Pruned adalah jumlah log yang dihapus pada pruning yang menghasilkan statistik ini
+	Pruned      int        `json:"pruned"`
+	TotalPruned int        `json:"totalPruned"`
+	LastPruneAt *time.Time `json:"lastPruneAt,omitempty"`
+	UpdatedAt   time.Time  `json:"updatedAt"`
+}
+
 // LogListResponse adalah respons untuk request list logs
 type LogListResponse struct {
 	Logs       []*Log `json:"logs"`
@@ -63,3 +110,50 @@ func NewLog(level, source, message string, data map[string]interface{}) *Log {
 		Data:      data,
 	}
 }
+
+// LogSummary adalah ringkasan log untuk dashboard, dihitung dari counter log tanpa membaca seluruh log
+type LogSummary struct {
+	TotalLogs int `json:"total_logs"`
+	// ErrorCount adalah jumlah log ERROR dan FATAL yang tersimpan
+	ErrorCount   int `json:"error_count"`
+	WarningCount int `json:"warning_count"`
+	// TodayLogs dan Last24Hours menghitung log yang masuk, termasuk yang sudah dihapus retensi
+	TodayLogs   int                  `json:"today_logs"`
+	Last24Hours int                  `json:"last_24h_logs"`
+	ByLevel     map[string]int       `json:"by_level"`
+	BySource    map[string]int       `json:"by_source"`
+	HourlyRate  []LogHourlyErrorRate `json:"hourly_error_rate"`
+	Latest      []*Log               `json:"latest"`
+	GeneratedAt time.Time            `json:"generated_at"`
+}
+
+// LogHourlyErrorRate adalah jumlah log dan error yang masuk dalam satu jam
+type LogHourlyErrorRate struct {
+	Hour   time.Time `json:"hour"`
+	Total  int       `json:"total"`
+	Errors int       `json:"errors"`
+	// Rate adalah perbandingan error terhadap total log, 0 jika tidak ada log
+	Rate float64 `json:"rate"`
+}
+
+// Jenis event pada stream log
+const (
+	LogStreamEventLog     = "log"     // Log baru yang cocok dengan filter
+	LogStreamEventDropped = "dropped" // Sejumlah log dilewati karena klien terlalu lambat
+)
+
+// LogStreamEvent adalah satu event yang dikirim ke klien stream log
+type LogStreamEvent struct {
+	Event string `json:"event"`
+	Log   *Log   `json:"log,omitempty"`
+	// Cursor adalah posisi log, dapat dipakai sebagai before/after pada GetLogs atau
+	// dikirim kembali lewat Last-Event-ID untuk melanjutkan stream
+	Cursor string `json:"cursor,omitempty"`
+	// Dropped adalah jumlah log yang dilewati sejak event terakhir terkirim, 0 jika tidak diketahui
+	Dropped int `json:"dropped,omitempty"`
+}
+
+// EventID mengembalikan cursor log sebagai ID event SSE
+func (e LogStreamEvent) EventID() string {
+	return e.Cursor
+}
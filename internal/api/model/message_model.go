@@ -4,20 +4,58 @@ import "time"
 
 // PersonalMessageRequest untuk request API kirim pesan personal
 type PersonalMessageRequest struct {
-	PhoneNumber string `json:"phoneNumber" validate:"required"`
-	Message     string `json:"message" validate:"required"`
+	PhoneNumber string        `json:"phoneNumber" validate:"required"`
+	Message     string        `json:"message" validate:"required"`
+	Account     string        `json:"account,omitempty"` // Akun WhatsApp pengirim, kosong berarti akun default
+	Retry       *RetryOptions `json:"retry,omitempty"`
+	SendAt      *time.Time    `json:"sendAt,omitempty"` // Waktu kirim terjadwal (RFC3339)
 }
 
 // GroupMessageRequest untuk request API kirim pesan grup
 type GroupMessageRequest struct {
-	GroupID string `json:"groupID" validate:"required"`
-	Message string `json:"message" validate:"required"`
+	GroupID string        `json:"groupID" validate:"required"`
+	Message string        `json:"message" validate:"required"`
+	Account string        `json:"account,omitempty"` // Akun WhatsApp pengirim, kosong berarti akun default
+	Retry   *RetryOptions `json:"retry,omitempty"`
+	SendAt  *time.Time    `json:"sendAt,omitempty"` // Waktu kirim terjadwal (RFC3339)
+}
+
+// RetryOptions untuk mengatur kebijakan retry per pesan.
+// Field yang kosong memakai nilai default dari konfigurasi.
+type RetryOptions struct {
+	MaxAttempts    int      `json:"maxAttempts"`
+	InitialBackoff string   `json:"initialBackoff"` // Format durasi Go, misal "10s"
+	MaxBackoff     string   `json:"maxBackoff"`     // Format durasi Go, misal "5m"
+	RetryOn        []string `json:"retryOn"`
+}
+
+// ImageMessageRequest untuk request API kirim gambar (JSON dengan base64)
+type ImageMessageRequest struct {
+	PhoneNumber string `json:"phoneNumber" form:"phoneNumber"`
+	GroupID     string `json:"groupID" form:"groupID"`
+	Image       string `json:"image"` // Konten gambar dalam base64, boleh berupa data URL
+	MimeType    string `json:"mimeType" form:"mimeType"`
+	Caption     string `json:"caption" form:"caption"`
+	Account     string `json:"account" form:"account"` // Akun WhatsApp pengirim, kosong berarti akun default
+}
+
+// DocumentMessageRequest untuk request API kirim dokumen (JSON dengan base64)
+type DocumentMessageRequest struct {
+	PhoneNumber string `json:"phoneNumber" form:"phoneNumber"`
+	GroupID     string `json:"groupID" form:"groupID"`
+	Document    string `json:"document"` // Konten dokumen dalam base64, boleh berupa data URL
+	FileName    string `json:"fileName" form:"fileName"`
+	MimeType    string `json:"mimeType" form:"mimeType"`
+	Caption     string `json:"caption" form:"caption"`
+	Account     string `json:"account" form:"account"` // Akun WhatsApp pengirim, kosong berarti akun default
 }
 
 // MessageResponse untuk hasil operasi kirim pesan
 type MessageResponse struct {
 	Success   bool      `json:"sukses"`
 	Message   string    `json:"pesan"`
+	ID        string    `json:"id,omitempty"` // ID pesan WhatsApp untuk melacak receipt
+	Account   string    `json:"akun,omitempty"`
 	Recipient string    `json:"penerima"`
 	Type      string    `json:"tipe"`
 	Timestamp time.Time `json:"waktu"`
@@ -34,6 +72,51 @@ func NewMessageResponse(message string, recipient string, messageType string) Me
 	}
 }
 
+// QueuedMessageResponse untuk hasil operasi kirim pesan yang masuk antrean
+type QueuedMessageResponse struct {
+	Success   bool       `json:"sukses"`
+	Message   string     `json:"pesan"`
+	ID        string     `json:"id"`
+	Status    string     `json:"status"`
+	Account   string     `json:"akun,omitempty"`
+	Recipient string     `json:"penerima"`
+	Type      string     `json:"tipe"`
+	Schedule  *time.Time `json:"jadwal,omitempty"`
+	Timestamp time.Time  `json:"waktu"`
+}
+
+// NewQueuedMessageResponse membuat respons pesan yang masuk antrean
+func NewQueuedMessageResponse(message, id, status, recipient, messageType string) QueuedMessageResponse {
+	return QueuedMessageResponse{
+		Success:   true,
+		Message:   message,
+		ID:        id,
+		Status:    status,
+		Recipient: recipient,
+		Type:      messageType,
+		Timestamp: time.Now(),
+	}
+}
+
+// RescheduleRequest untuk request API mengubah waktu kirim pesan terjadwal
+type RescheduleRequest struct {
+	SendAt time.Time `json:"sendAt" validate:"required"`
+}
+
+// MessageStatusResponse untuk hasil query status pengiriman dan receipt pesan
+type MessageStatusResponse struct {
+	Success     bool       `json:"sukses"`
+	ID          string     `json:"id"`
+	WhatsAppID  string     `json:"whatsappId,omitempty"`
+	Status      string     `json:"status"`
+	QueueStatus string     `json:"queueStatus,omitempty"`
+	Recipient   string     `json:"penerima"`
+	SentAt      *time.Time `json:"sentAt,omitempty"`
+	DeliveredAt *time.Time `json:"deliveredAt,omitempty"`
+	ReadAt      *time.Time `json:"readAt,omitempty"`
+	Timestamp   time.Time  `json:"waktu"`
+}
+
 // ErrorMessageResponse untuk respons error
 type ErrorMessageResponse struct {
 	Success   bool      `json:"sukses"`
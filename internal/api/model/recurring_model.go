@@ -0,0 +1,13 @@
+package model
+
+// RecurringJobRequest untuk request API membuat atau mengubah job pesan berulang
+type RecurringJobRequest struct {
+	Name        string `json:"name" validate:"required"`
+	Schedule    string `json:"schedule" validate:"required"` // Ekspresi cron 5 field, misal "0 9 * * 1-5"
+	Timezone    string `json:"timezone"`                     // Nama zona waktu IANA, misal "Asia/Jakarta"
+	PhoneNumber string `json:"phoneNumber"`
+	GroupID     string `json:"groupID"`
+	Message     string `json:"message" validate:"required"`
+	Account     string `json:"account,omitempty"` // Akun WhatsApp pengirim, kosong berarti akun default
+	Enabled     *bool  `json:"enabled,omitempty"` // Default aktif jika tidak diisi
+}
@@ -14,6 +14,7 @@ type ConnectionStatus struct {
 // StatusResponse untuk hasil query status
 type StatusResponse struct {
 	Success bool             `json:"sukses"`
+	Account string           `json:"akun,omitempty"`
 	Status  string           `json:"status"`
 	Details ConnectionStatus `json:"details"`
 	Time    time.Time        `json:"timestamp"`
@@ -11,11 +11,16 @@ import (
 	"gopkg.in/yaml.v2"
 )
 
+// DefaultPath mengembalikan lokasi default file konfigurasi
+func DefaultPath() string {
+	// Direktori config dapat dipindahkan lewat APP_CONFIG_DIR
+	return filepath.Join(utils.ConfigDir, "config.yaml")
+}
+
 // LoadDefault memuat konfigurasi dari path default
 func LoadDefault() (*Config, error) {
-	// Gunakan ProjectRoot langsung untuk mengakses direktori config
-	configDir := filepath.Join(utils.ProjectRoot, "config")
-	configPath := filepath.Join(configDir, "config.yaml")
+	configPath := DefaultPath()
+	configDir := filepath.Dir(configPath)
 
 	data, err := ioutil.ReadFile(configPath)
 	if err != nil {
@@ -38,19 +43,21 @@ func LoadDefault() (*Config, error) {
 				return nil, fmt.Errorf("gagal menulis config default ke file: %w", err)
 			}
 			fmt.Printf("File konfigurasi baru dibuat di '%s'\n", configPath)
+			cfg.path = configPath
 			return cfg, nil
 		}
 		return nil, fmt.Errorf("gagal membaca file konfigurasi: %w", err)
 	}
 
-	// Parse YAML
-	var config Config
-	err = yaml.Unmarshal(data, &config)
+	// Parse YAML di atas nilai default, key yang tidak ada di file memakai default
+	config := defaultConfig()
+	err = yaml.Unmarshal(data, config)
 	if err != nil {
 		return nil, fmt.Errorf("gagal parse YAML: %w", err)
 	}
+	config.path = configPath
 
-	return &config, nil
+	return config, nil
 }
 
 // LoadFromPath memuat konfigurasi dari path yang ditentukan
@@ -60,20 +67,21 @@ func LoadFromPath(configPath string) (*Config, error) {
 		return nil, fmt.Errorf("gagal membaca file konfigurasi: %w", err)
 	}
 
-	var config Config
-	err = yaml.Unmarshal(data, &config)
+	config := defaultConfig()
+	err = yaml.Unmarshal(data, config)
 	if err != nil {
 		return nil, fmt.Errorf("gagal parse YAML: %w", err)
 	}
+	config.path = configPath
 
-	return &config, nil
+	return config, nil
 }
 
 // defaultConfig mengembalikan konfigurasi default
 func defaultConfig() *Config {
-	// Akses direktori langsung menggunakan ProjectRoot
-	dataDir := filepath.Join(utils.ProjectRoot, "data")
-	logsDir := filepath.Join(utils.ProjectRoot, "logs")
+	// Direktori data dan log dapat dipindahkan lewat APP_DATA_DIR dan APP_LOG_DIR
+	dataDir := utils.DataDir
+	logsDir := utils.LogDir
 
 	return &Config{
 		Server: ServerConfig{
@@ -85,19 +93,28 @@ func defaultConfig() *Config {
 			BaseURL:         "http://localhost:8080",
 		},
 		WhatsApp: WhatsAppConfig{
-			StoreDir:    filepath.Join(dataDir, "whatsapp"),
-			QrCodeDir:   filepath.Join(dataDir, "qrcodes"),
-			MaxRetry:    5,
-			RetryDelay:  5 * time.Second,
-			IdleTimeout: 30 * time.Minute,
+			StoreDir:        filepath.Join(dataDir, "whatsapp"),
+			MaxRetry:        5,
+			RetryDelay:      5 * time.Second,
+			IdleTimeout:     30 * time.Minute,
+			MaxDocumentSize: DefaultMaxDocumentSize,
+			QueueWorkers:    2,
+			SendRetry: SendRetryConfig{
+				MaxAttempts:    5,
+				InitialBackoff: 5 * time.Second,
+				MaxBackoff:     5 * time.Minute,
+				RetryOn:        []string{"not_connected", "timeout", "network", "rate_limit", "server"},
+			},
+			QueueRetention: 7 * 24 * time.Hour,
 		},
 		Auth: AuthConfig{
-			TokenSecret:  "change-this-to-secure-random-string",
-			AccessToken:  "change-this-to-your-api-token",
-			TokenExpiry:  24 * time.Hour,
-			SessionDir:   filepath.Join(dataDir, "sessions"),
-			CookieName:   "whatsmeow_session",
-			CookieMaxAge: 86400,
+			TokenSecret:        "change-this-to-secure-random-string",
+			AccessToken:        "change-this-to-your-api-token",
+			TokenExpiry:        24 * time.Hour,
+			SessionDir:         filepath.Join(dataDir, "sessions"),
+			CookieName:         "whatsmeow_session",
+			CookieMaxAge:       86400,
+			TokenRotationGrace: DefaultTokenRotationGrace,
 		},
 		Storage: StorageConfig{
 			Type:     "badger",
@@ -105,19 +122,40 @@ func defaultConfig() *Config {
 			InMemory: false,
 		},
 		Logging: LoggingConfig{
-			Level:      "info",
-			File:       filepath.Join(logsDir, "app.log"),
-			MaxSize:    10,
-			MaxBackups: 3,
-			MaxAge:     28,
-			Compress:   true,
+			Level:        "info",
+			PersistLevel: "info",
+			File:         filepath.Join(logsDir, "app.log"),
+			MaxSize:      10,
+			MaxBackups:   3,
+			MaxAge:       28,
+			Compress:     true,
+			Retention: LogRetentionConfig{
+				MaxAge: LogMaxAgeConfig{
+					Debug:   24 * time.Hour,
+					Info:    7 * 24 * time.Hour,
+					Warning: 30 * 24 * time.Hour,
+					Error:   90 * 24 * time.Hour,
+					Fatal:   90 * 24 * time.Hour,
+				},
+				MaxEntries: 100000,
+				MaxBytes:   100 << 20,
+				Interval:   10 * time.Minute,
+			},
+		},
+		Webhooks: WebhookConfig{
+			Timeout:        10 * time.Second,
+			MaxAttempts:    5,
+			InitialBackoff: 2 * time.Second,
+			MaxBackoff:     2 * time.Minute,
+			Workers:        2,
 		},
 	}
 }
 
-// SaveToFile menyimpan konfigurasi ke file
+// SaveToFile menyimpan konfigurasi ke file. Pengaturan yang berasal dari
+// environment variable atau flag disimpan dengan nilai aslinya dari file.
 func SaveToFile(cfg *Config, path string) error {
-	yamlData, err := yaml.Marshal(cfg)
+	yamlData, err := yaml.Marshal(cfg.fileLayer())
 	if err != nil {
 		return fmt.Errorf("gagal marshal config: %w", err)
 	}
@@ -0,0 +1,156 @@
+package config
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+)
+
+// Change adalah satu pengaturan yang berubah antara dua konfigurasi
+type Change struct {
+	Key string // Path YAML pengaturan, misal "whatsapp.max_retry"
+	Old string
+	New string
+}
+
+// String mengembalikan representasi perubahan yang siap ditulis ke log
+func (c Change) String() string {
+	return fmt.Sprintf("%s: %s -> %s", c.Key, c.Old, c.New)
+}
+
+// Changes adalah daftar perubahan konfigurasi
+type Changes []Change
+
+// Has memeriksa apakah ada perubahan pada key atau bagian konfigurasi tertentu.
+// Prefix "auth" cocok dengan semua perubahan di bawah auth, misal "auth.access_token".
+func (c Changes) Has(prefix string) bool {
+	for _, change := range c {
+		if matchesPrefix(change.Key, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchesPrefix memeriksa apakah key sama dengan prefix atau berada di bawahnya
+func matchesPrefix(key, prefix string) bool {
+	return key == prefix || strings.HasPrefix(key, prefix+".")
+}
+
+// Keys mengembalikan daftar key yang berubah
+func (c Changes) Keys() []string {
+	keys := make([]string, len(c))
+	for i, change := range c {
+		keys[i] = change.Key
+	}
+	return keys
+}
+
+// Diff membandingkan dua konfigurasi dan mengembalikan pengaturan yang berubah.
+// Nilai rahasia seperti token dan secret disamarkan.
+func Diff(old, new *Config) Changes {
+	var changes Changes
+	diffValue("", reflect.ValueOf(*old), reflect.ValueOf(*new), &changes)
+	return changes
+}
+
+// diffValue membandingkan field struct secara rekursif berdasarkan tag yaml
+func diffValue(prefix string, old, new reflect.Value, changes *Changes) {
+	t := old.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if field.PkgPath != "" {
+			continue // Field tidak diekspor, misal path
+		}
+
+		name := strings.Split(field.Tag.Get("yaml"), ",")[0]
+		if name == "" || name == "-" {
+			continue
+		}
+		key := name
+		if prefix != "" {
+			key = prefix + "." + name
+		}
+
+		oldField, newField := old.Field(i), new.Field(i)
+		if field.Type.Kind() == reflect.Struct && field.Type != reflect.TypeOf(time.Time{}) {
+			diffValue(key, oldField, newField, changes)
+			continue
+		}
+
+		if reflect.DeepEqual(oldField.Interface(), newField.Interface()) {
+			continue
+		}
+
+		*changes = append(*changes, Change{
+			Key: key,
+			Old: formatValue(key, oldField),
+			New: formatValue(key, newField),
+		})
+	}
+}
+
+// formatValue mengubah nilai pengaturan menjadi string, menyamarkan nilai rahasia
+func formatValue(key string, v reflect.Value) string {
+	if isSecretKey(key) {
+		if v.IsZero() {
+			return `""`
+		}
+		return "******"
+	}
+	if v.Kind() == reflect.Slice && v.Type().Elem().Kind() == reflect.Struct {
+		return fmt.Sprintf("%d item", v.Len())
+	}
+	return fmt.Sprintf("%v", v.Interface())
+}
+
+// isSecretKey memeriksa apakah key berisi nilai rahasia
+func isSecretKey(key string) bool {
+	return strings.HasSuffix(key, "token") || strings.HasSuffix(key, "secret")
+}
+
+// PendingRestart mengembalikan pengaturan yang sudah diubah dibanding konfigurasi
+// yang sedang berjalan tetapi baru berlaku setelah aplikasi di-restart
+func (cfg *Config) PendingRestart(running *Config) []string {
+	var pending []string
+	for _, change := range Diff(running, cfg) {
+		if RequiresRestart(change.Key) {
+			pending = append(pending, change.Key)
+		}
+	}
+	return pending
+}
+
+// restartKeys berisi bagian konfigurasi yang hanya dibaca saat aplikasi dimulai
+var restartKeys = []string{
+	"server.host",
+	"server.port",
+	"server.base_url",
+	"server.read_timeout",
+	"server.write_timeout",
+	"server.views_dir",
+	"server.static_dir",
+	"whatsapp.store_dir",
+	"whatsapp.max_document_size",
+	"whatsapp.queue_workers",
+	"auth.session_dir",
+	"auth.cookie_name",
+	"storage",
+	"logging.file",
+	"logging.max_size",
+	"logging.max_backups",
+	"logging.max_age",
+	"logging.compress",
+	"webhooks",
+}
+
+// RequiresRestart memeriksa apakah perubahan pada key baru berlaku setelah restart
+func RequiresRestart(key string) bool {
+	for _, prefix := range restartKeys {
+		if matchesPrefix(key, prefix) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,165 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"github.com/gwenziro/bot-notify/internal/utils"
+)
+
+// ErrOverridden dikembalikan Save jika perubahan mengenai pengaturan yang nilainya berasal dari env atau flag
+var ErrOverridden = errors.New("pengaturan berasal dari env atau flag dan tidak dapat diubah")
+
+// Subscriber dipanggil setelah perubahan konfigurasi diterapkan.
+// cfg adalah konfigurasi yang sedang berjalan dan sudah berisi nilai baru.
+type Subscriber func(cfg *Config, changes Changes)
+
+// Live menyimpan konfigurasi yang sedang berjalan dan dipakai bersama oleh semua komponen.
+//
+// Konfigurasi yang sudah dipublikasikan tidak pernah diubah. Setiap perubahan dibuat pada
+// salinan lalu dipublikasikan sebagai *Config baru, sehingga pembaca cukup memanggil Get
+// tanpa lock dan selalu melihat nilai yang konsisten. Komponen tidak boleh menyimpan
+// hasil Get untuk pengaturan yang dapat berubah saat berjalan.
+type Live struct {
+	mu          sync.Mutex
+	running     atomic.Pointer[Config]
+	saved       atomic.Pointer[Config]
+	subscribers []Subscriber
+	logger      utils.LogrusEntry
+}
+
+// NewLive membuat Live dari konfigurasi yang dimuat saat aplikasi dimulai.
+// cfg tidak boleh diubah lagi setelah diberikan ke Live.
+func NewLive(cfg *Config) *Live {
+	l := &Live{logger: utils.ForModule("config")}
+	l.running.Store(cfg)
+	l.saved.Store(cfg)
+	return l
+}
+
+// Get mengembalikan konfigurasi yang sedang berjalan. Hasilnya hanya boleh dibaca.
+func (l *Live) Get() *Config {
+	return l.running.Load()
+}
+
+// Saved mengembalikan konfigurasi terakhir yang dimuat atau disimpan, termasuk
+// pengaturan yang baru berlaku setelah restart. Hasilnya hanya boleh dibaca.
+func (l *Live) Saved() *Config {
+	return l.saved.Load()
+}
+
+// PendingRestart mengembalikan pengaturan yang sudah diubah tetapi baru berlaku setelah restart
+func (l *Live) PendingRestart() []string {
+	return l.Saved().PendingRestart(l.Get())
+}
+
+// Subscribe mendaftarkan fungsi yang dipanggil setiap kali konfigurasi berubah
+func (l *Live) Subscribe(fn Subscriber) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.subscribers = append(l.subscribers, fn)
+}
+
+// Apply menerapkan konfigurasi hasil reload. Pengaturan yang dapat diubah saat berjalan
+// langsung berlaku, sedangkan pengaturan yang membutuhkan restart hanya dicatat sebagai
+// konfigurasi tersimpan. Mengembalikan pengaturan yang berubah.
+func (l *Live) Apply(next *Config) Changes {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.apply(next)
+}
+
+// Save mengubah salinan konfigurasi tersimpan dengan fn, menulisnya ke file konfigurasi,
+// lalu menerapkannya seperti Apply. Konfigurasi tidak berubah jika fn atau penulisan file gagal,
+// atau jika fn mengubah pengaturan yang nilainya berasal dari env atau flag.
+func (l *Live) Save(fn func(cfg *Config) error) (Changes, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	saved := l.saved.Load()
+	next := saved.clone()
+	if err := fn(next); err != nil {
+		return nil, err
+	}
+
+	// Nilai dari env atau flag tidak ditulis ke file, sehingga perubahannya akan hilang
+	// saat reload berikutnya. Tolak daripada perubahan kembali tanpa pemberitahuan.
+	var locked []string
+	for _, change := range Diff(saved, next) {
+		if source, ok := saved.OverrideSource(change.Key); ok {
+			locked = append(locked, fmt.Sprintf("%s (diatur dari %s)", change.Key, source))
+		}
+	}
+	if len(locked) > 0 {
+		return nil, fmt.Errorf("%w: %s", ErrOverridden, strings.Join(locked, ", "))
+	}
+	if err := SaveToFile(next, next.Path()); err != nil {
+		return nil, fmt.Errorf("gagal menyimpan konfigurasi: %w", err)
+	}
+	return l.apply(next), nil
+}
+
+// apply mempublikasikan konfigurasi baru dan memberi tahu subscriber. Harus dipanggil dengan mu terkunci.
+func (l *Live) apply(next *Config) Changes {
+	changes := Diff(l.saved.Load(), next)
+
+	// Salin hanya pengaturan yang aman diubah saat berjalan ke konfigurasi yang berjalan
+	running := l.running.Load().clone()
+	for _, change := range changes {
+		restart := RequiresRestart(change.Key)
+		l.logger.WithFields(utils.Fields{
+			"key":     change.Key,
+			"old":     change.Old,
+			"new":     change.New,
+			"restart": restart,
+		}).Info("Pengaturan berubah")
+
+		if restart {
+			continue
+		}
+		dst, err := lookupField(running, change.Key)
+		if err != nil {
+			continue
+		}
+		src, err := lookupField(next, change.Key)
+		if err != nil {
+			continue
+		}
+		dst.Set(src)
+	}
+	running.path = next.path
+	running.base = next.base
+	running.overridden = next.overridden
+	running.overrideSources = next.overrideSources
+
+	// Konfigurasi tersimpan tetap diganti walau tanpa perubahan, karena nilai file untuk
+	// pengaturan dari env atau flag bisa berubah
+	l.running.Store(running)
+	l.saved.Store(next)
+
+	if len(changes) == 0 {
+		return nil
+	}
+	for _, fn := range l.subscribers {
+		fn(running, changes)
+	}
+	return changes
+}
+
+// clone membuat salinan konfigurasi yang dapat diubah tanpa memengaruhi konfigurasi asal
+func (cfg *Config) clone() *Config {
+	out := *cfg
+	if cfg.WhatsApp.SendRetry.RetryOn != nil {
+		out.WhatsApp.SendRetry.RetryOn = append(make([]string, 0, len(cfg.WhatsApp.SendRetry.RetryOn)), cfg.WhatsApp.SendRetry.RetryOn...)
+	}
+	if cfg.Webhooks.Subscriptions != nil {
+		out.Webhooks.Subscriptions = append(make([]WebhookSubscription, 0, len(cfg.Webhooks.Subscriptions)), cfg.Webhooks.Subscriptions...)
+	}
+	if cfg.overridden != nil {
+		out.overridden = append(make([]string, 0, len(cfg.overridden)), cfg.overridden...)
+	}
+	return &out
+}
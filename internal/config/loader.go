@@ -0,0 +1,365 @@
+package config
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// EnvPrefix adalah prefix environment variable konfigurasi.
+// Key YAML "server.port" menjadi BOTNOTIFY_SERVER_PORT.
+const EnvPrefix = "BOTNOTIFY_"
+
+// fileSuffix menandai environment variable yang berisi path file rahasia,
+// misal BOTNOTIFY_AUTH_ACCESS_TOKEN_FILE=/run/secrets/token
+const fileSuffix = "_FILE"
+
+// override adalah satu nilai pengaturan dari command-line flag
+type override struct {
+	key   string
+	value string
+}
+
+// Loader memuat konfigurasi berlapis dengan urutan prioritas:
+// default → file YAML → environment variable → command-line flag.
+type Loader struct {
+	// Path adalah lokasi file konfigurasi, kosong berarti DefaultPath
+	Path string
+
+	// LookupEnv membaca environment variable, default os.LookupEnv
+	LookupEnv func(key string) (string, bool)
+
+	overrides []override
+}
+
+// NewLoader membuat instance baru Loader
+func NewLoader() *Loader {
+	return &Loader{LookupEnv: os.LookupEnv}
+}
+
+// Set menambahkan override untuk key YAML tertentu, misal Set("server.port", "9090")
+func (l *Loader) Set(key, value string) {
+	l.overrides = append(l.overrides, override{key: key, value: value})
+}
+
+// BindFlags mendaftarkan flag konfigurasi ke FlagSet: --config, --host, --port,
+// --base-url, --log-level, --dev, dan --set key=value yang dapat diulang
+func (l *Loader) BindFlags(fs *flag.FlagSet) {
+	fs.StringVar(&l.Path, "config", l.Path, "Path file konfigurasi YAML")
+	fs.Var(&keyFlag{loader: l, key: "server.host"}, "host", "Alamat host server")
+	fs.Var(&keyFlag{loader: l, key: "server.port"}, "port", "Port server")
+	fs.Var(&keyFlag{loader: l, key: "server.base_url"}, "base-url", "URL dasar aplikasi")
+	fs.Var(&keyFlag{loader: l, key: "logging.level"}, "log-level", "Level log (debug, info, warn, error)")
+	fs.Var(&keyFlag{loader: l, key: "server.dev_mode", isBool: true}, "dev", "Mode development, masalah konfigurasi hanya menjadi peringatan")
+	fs.Var(&setFlag{loader: l}, "set", "Override pengaturan dengan format key=value, misal whatsapp.max_retry=10 (dapat diulang)")
+}
+
+// Load memuat konfigurasi dari semua lapisan
+func (l *Loader) Load() (*Config, error) {
+	var (
+		cfg *Config
+		err error
+	)
+	if l.Path == "" {
+		cfg, err = LoadDefault()
+	} else {
+		cfg, err = LoadFromPath(l.Path)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// Simpan salinan lapisan file agar nilai dari env dan flag tidak ikut ditulis oleh SaveToFile
+	base := *cfg
+	cfg.base = &base
+
+	var errs []string
+	if err := l.applyEnv(cfg); err != nil {
+		errs = append(errs, err.Error())
+	}
+	for _, o := range l.overrides {
+		if err := setValue(cfg, o.key, o.value); err != nil {
+			errs = append(errs, fmt.Sprintf("flag %s: %v", o.key, err))
+			continue
+		}
+		cfg.markOverridden(o.key, "flag")
+	}
+	if len(errs) > 0 {
+		return nil, fmt.Errorf("gagal menerapkan override konfigurasi: %s", strings.Join(errs, "; "))
+	}
+
+	return cfg, nil
+}
+
+// applyEnv menerapkan environment variable BOTNOTIFY_* dan varian *_FILE
+func (l *Loader) applyEnv(cfg *Config) error {
+	lookup := l.LookupEnv
+	if lookup == nil {
+		lookup = os.LookupEnv
+	}
+
+	var errs []string
+	for _, key := range Keys() {
+		name := EnvName(key)
+
+		value, ok := lookup(name)
+		source := name
+		if path, fromFile := lookup(name + fileSuffix); fromFile {
+			if ok {
+				errs = append(errs, fmt.Sprintf("%s dan %s tidak boleh diisi bersamaan", name, name+fileSuffix))
+				continue
+			}
+			data, err := os.ReadFile(path)
+			if err != nil {
+				errs = append(errs, fmt.Sprintf("%s: gagal membaca file: %v", name+fileSuffix, err))
+				continue
+			}
+			value, ok = strings.TrimSpace(string(data)), true
+			source = name + fileSuffix
+		}
+		if !ok {
+			continue
+		}
+
+		if err := setValue(cfg, key, value); err != nil {
+			errs = append(errs, fmt.Sprintf("%s: %v", name, err))
+			continue
+		}
+		cfg.markOverridden(key, source)
+	}
+
+	if len(errs) > 0 {
+		return errors.New(strings.Join(errs, "; "))
+	}
+	return nil
+}
+
+// EnvName mengembalikan nama environment variable untuk key YAML
+func EnvName(key string) string {
+	return EnvPrefix + strings.ToUpper(strings.ReplaceAll(key, ".", "_"))
+}
+
+// Keys mengembalikan semua key YAML yang dapat di-override lewat env atau flag
+func Keys() []string {
+	var keys []string
+	walkFields("", reflect.TypeOf(Config{}), func(key string, _ reflect.Type) {
+		keys = append(keys, key)
+	})
+	return keys
+}
+
+// walkFields menelusuri field struct konfigurasi berdasarkan tag yaml.
+// Slice berisi struct (misal webhooks.subscriptions) dilewati karena tidak dapat diisi dari satu string.
+func walkFields(prefix string, t reflect.Type, fn func(key string, t reflect.Type)) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		key, ok := fieldKey(prefix, field)
+		if !ok {
+			continue
+		}
+
+		switch {
+		case field.Type.Kind() == reflect.Struct && field.Type != reflect.TypeOf(time.Time{}):
+			walkFields(key, field.Type, fn)
+		case field.Type.Kind() == reflect.Slice && field.Type.Elem().Kind() == reflect.Struct:
+			continue
+		default:
+			fn(key, field.Type)
+		}
+	}
+}
+
+// fieldKey mengembalikan key YAML sebuah field, atau false jika field tidak dipetakan
+func fieldKey(prefix string, field reflect.StructField) (string, bool) {
+	if field.PkgPath != "" {
+		return "", false
+	}
+	name := strings.Split(field.Tag.Get("yaml"), ",")[0]
+	if name == "" || name == "-" {
+		return "", false
+	}
+	if prefix != "" {
+		name = prefix + "." + name
+	}
+	return name, true
+}
+
+// lookupField mencari field konfigurasi berdasarkan key YAML
+func lookupField(cfg *Config, key string) (reflect.Value, error) {
+	v := reflect.ValueOf(cfg).Elem()
+	prefix := ""
+	for _, part := range strings.Split(key, ".") {
+		if v.Kind() != reflect.Struct {
+			return reflect.Value{}, fmt.Errorf("key tidak dikenal: %s", key)
+		}
+
+		found := false
+		for i := 0; i < v.NumField(); i++ {
+			name, ok := fieldKey(prefix, v.Type().Field(i))
+			if ok && name == joinKey(prefix, part) {
+				v = v.Field(i)
+				prefix = name
+				found = true
+				break
+			}
+		}
+		if !found {
+			return reflect.Value{}, fmt.Errorf("key tidak dikenal: %s", key)
+		}
+	}
+	return v, nil
+}
+
+// joinKey menggabungkan prefix dan nama field menjadi key YAML
+func joinKey(prefix, name string) string {
+	if prefix == "" {
+		return name
+	}
+	return prefix + "." + name
+}
+
+// setValue mengisi field konfigurasi dari string
+func setValue(cfg *Config, key, value string) error {
+	v, err := lookupField(cfg, key)
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case v.Type() == reflect.TypeOf(time.Duration(0)):
+		d, err := time.ParseDuration(value)
+		if err != nil {
+			return fmt.Errorf("durasi tidak valid: %q", value)
+		}
+		v.SetInt(int64(d))
+	case v.Type() == reflect.TypeOf(time.Time{}):
+		t, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			return fmt.Errorf("waktu harus format RFC3339: %q", value)
+		}
+		v.Set(reflect.ValueOf(t))
+	case v.Kind() == reflect.String:
+		v.SetString(value)
+	case v.Kind() == reflect.Int:
+		n, err := strconv.Atoi(value)
+		if err != nil {
+			return fmt.Errorf("angka tidak valid: %q", value)
+		}
+		v.SetInt(int64(n))
+	case v.Kind() == reflect.Bool:
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return fmt.Errorf("boolean tidak valid: %q", value)
+		}
+		v.SetBool(b)
+	case v.Kind() == reflect.Slice && v.Type().Elem().Kind() == reflect.String:
+		var items []string
+		for _, item := range strings.Split(value, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				items = append(items, item)
+			}
+		}
+		v.Set(reflect.ValueOf(items))
+	default:
+		return fmt.Errorf("key %s tidak dapat diisi dari env atau flag", key)
+	}
+	return nil
+}
+
+// markOverridden mencatat key yang nilainya berasal dari env atau flag beserta sumbernya.
+// Flag diterapkan setelah env, jadi sumber terakhir yang dicatat adalah yang berlaku.
+func (cfg *Config) markOverridden(key, source string) {
+	if cfg.overrideSources == nil {
+		cfg.overrideSources = make(map[string]string)
+	}
+	if _, ok := cfg.overrideSources[key]; !ok {
+		cfg.overridden = append(cfg.overridden, key)
+	}
+	cfg.overrideSources[key] = source
+}
+
+// Overridden mengembalikan key yang nilainya berasal dari env atau flag, bukan dari file
+func (cfg *Config) Overridden() []string {
+	return cfg.overridden
+}
+
+// OverrideSource mengembalikan sumber nilai key yang berasal dari env atau flag, berupa
+// nama environment variable atau "flag". ok bernilai false jika nilainya dari file.
+func (cfg *Config) OverrideSource(key string) (source string, ok bool) {
+	source, ok = cfg.overrideSources[key]
+	return source, ok
+}
+
+// fileLayer mengembalikan salinan konfigurasi dengan nilai env dan flag dikembalikan
+// ke nilai dari file, sehingga rahasia dari env tidak tertulis ke file konfigurasi
+func (cfg *Config) fileLayer() *Config {
+	out := *cfg
+	if cfg.base == nil {
+		return &out
+	}
+	for _, key := range cfg.overridden {
+		dst, err := lookupField(&out, key)
+		if err != nil {
+			continue
+		}
+		src, err := lookupField(cfg.base, key)
+		if err != nil {
+			continue
+		}
+		dst.Set(src)
+	}
+	return &out
+}
+
+// keyFlag adalah flag yang mengisi satu key konfigurasi
+type keyFlag struct {
+	loader *Loader
+	key    string
+	value  string
+	isBool bool
+}
+
+// IsBoolFlag membuat flag boolean dapat dipakai tanpa nilai, misal --dev
+func (f *keyFlag) IsBoolFlag() bool {
+	return f.isBool
+}
+
+// String mengimplementasikan flag.Value
+func (f *keyFlag) String() string {
+	if f == nil {
+		return ""
+	}
+	return f.value
+}
+
+// Set mengimplementasikan flag.Value
+func (f *keyFlag) Set(value string) error {
+	f.value = value
+	f.loader.Set(f.key, value)
+	return nil
+}
+
+// setFlag adalah flag --set key=value yang dapat diulang
+type setFlag struct {
+	loader *Loader
+}
+
+// String mengimplementasikan flag.Value
+func (f *setFlag) String() string {
+	return ""
+}
+
+// Set mengimplementasikan flag.Value
+func (f *setFlag) Set(value string) error {
+	key, val, ok := strings.Cut(value, "=")
+	if !ok || strings.TrimSpace(key) == "" {
+		return fmt.Errorf("format harus key=value: %q", value)
+	}
+	f.loader.Set(strings.TrimSpace(key), val)
+	return nil
+}
@@ -0,0 +1,357 @@
+package config
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// testYAML adalah file konfigurasi pada test
+const testYAML = `server:
+  port: 9000
+auth:
+  access_token: token-dari-file
+logging:
+  level: warn
+`
+
+// writeTestConfig menulis file konfigurasi ke direktori sementara dan mengembalikan path-nya
+func writeTestConfig(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("gagal menulis file konfigurasi: %v", err)
+	}
+	return path
+}
+
+// loadTestConfig memuat konfigurasi dari path dengan argumen command-line tertentu
+func loadTestConfig(path string, args ...string) (*Config, error) {
+	loader := NewLoader()
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	loader.BindFlags(fs)
+	if err := fs.Parse(append([]string{"--config", path}, args...)); err != nil {
+		return nil, err
+	}
+	return loader.Load()
+}
+
+// valueOf mengembalikan nilai key konfigurasi sebagai string
+func valueOf(t *testing.T, cfg *Config, key string) string {
+	t.Helper()
+	v, err := lookupField(cfg, key)
+	if err != nil {
+		t.Fatalf("gagal membaca %s: %v", key, err)
+	}
+	return fmt.Sprint(v.Interface())
+}
+
+func TestLoaderPrecedence(t *testing.T) {
+	tests := []struct {
+		name       string
+		yaml       string
+		env        map[string]string
+		files      map[string]string
+		args       []string
+		key        string
+		want       string
+		wantSource string
+	}{
+		{
+			name: "default",
+			yaml: "server:\n  host: 0.0.0.0\n",
+			key:  "server.port",
+			want: "8080",
+		},
+		{
+			name: "yaml menimpa default",
+			yaml: testYAML,
+			key:  "server.port",
+			want: "9000",
+		},
+		{
+			name:       "env menimpa yaml",
+			yaml:       testYAML,
+			env:        map[string]string{"BOTNOTIFY_SERVER_PORT": "9100"},
+			key:        "server.port",
+			want:       "9100",
+			wantSource: "BOTNOTIFY_SERVER_PORT",
+		},
+		{
+			name:       "env menimpa default",
+			yaml:       testYAML,
+			env:        map[string]string{"BOTNOTIFY_WHATSAPP_IDLE_TIMEOUT": "5m"},
+			key:        "whatsapp.idle_timeout",
+			want:       "5m0s",
+			wantSource: "BOTNOTIFY_WHATSAPP_IDLE_TIMEOUT",
+		},
+		{
+			name:       "file rahasia menimpa yaml",
+			yaml:       testYAML,
+			files:      map[string]string{"BOTNOTIFY_AUTH_ACCESS_TOKEN_FILE": "token-rahasia\n"},
+			key:        "auth.access_token",
+			want:       "token-rahasia",
+			wantSource: "BOTNOTIFY_AUTH_ACCESS_TOKEN_FILE",
+		},
+		{
+			name:       "flag menimpa env",
+			yaml:       testYAML,
+			env:        map[string]string{"BOTNOTIFY_SERVER_PORT": "9100"},
+			args:       []string{"--port", "9200"},
+			key:        "server.port",
+			want:       "9200",
+			wantSource: "flag",
+		},
+		{
+			name:       "flag menimpa file rahasia",
+			yaml:       testYAML,
+			files:      map[string]string{"BOTNOTIFY_AUTH_ACCESS_TOKEN_FILE": "token-rahasia"},
+			args:       []string{"--set", "auth.access_token=token-flag"},
+			key:        "auth.access_token",
+			want:       "token-flag",
+			wantSource: "flag",
+		},
+		{
+			name:       "flag terakhir yang berlaku",
+			yaml:       testYAML,
+			args:       []string{"--log-level", "debug", "--set", "logging.level=error"},
+			key:        "logging.level",
+			want:       "error",
+			wantSource: "flag",
+		},
+		{
+			name:       "flag boolean tanpa nilai",
+			yaml:       testYAML,
+			args:       []string{"--dev"},
+			key:        "server.dev_mode",
+			want:       "true",
+			wantSource: "flag",
+		},
+		{
+			name:       "env daftar dipisah koma",
+			yaml:       testYAML,
+			env:        map[string]string{"BOTNOTIFY_WHATSAPP_SEND_RETRY_RETRY_ON": "timeout, network"},
+			key:        "whatsapp.send_retry.retry_on",
+			want:       "[timeout network]",
+			wantSource: "BOTNOTIFY_WHATSAPP_SEND_RETRY_RETRY_ON",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			path := writeTestConfig(t, tc.yaml)
+			for name, value := range tc.env {
+				t.Setenv(name, value)
+			}
+			for name, content := range tc.files {
+				secret := filepath.Join(t.TempDir(), "secret")
+				if err := os.WriteFile(secret, []byte(content), 0600); err != nil {
+					t.Fatalf("gagal menulis file rahasia: %v", err)
+				}
+				t.Setenv(name, secret)
+			}
+
+			cfg, err := loadTestConfig(path, tc.args...)
+			if err != nil {
+				t.Fatalf("gagal memuat konfigurasi: %v", err)
+			}
+
+			if got := valueOf(t, cfg, tc.key); got != tc.want {
+				t.Errorf("%s = %q, ingin %q", tc.key, got, tc.want)
+			}
+			source, ok := cfg.OverrideSource(tc.key)
+			if ok != (tc.wantSource != "") || source != tc.wantSource {
+				t.Errorf("sumber %s = %q (%v), ingin %q", tc.key, source, ok, tc.wantSource)
+			}
+			if cfg.Path() != path {
+				t.Errorf("Path = %q, ingin %q", cfg.Path(), path)
+			}
+		})
+	}
+}
+
+func TestLoaderErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		env  map[string]string
+		args []string
+	}{
+		{
+			name: "env dan file rahasia bersamaan",
+			env: map[string]string{
+				"BOTNOTIFY_AUTH_ACCESS_TOKEN":      "token-env",
+				"BOTNOTIFY_AUTH_ACCESS_TOKEN_FILE": "/tidak/dipakai",
+			},
+		},
+		{
+			name: "file rahasia tidak ada",
+			env:  map[string]string{"BOTNOTIFY_AUTH_ACCESS_TOKEN_FILE": "/tidak/ada/secret"},
+		},
+		{
+			name: "env angka tidak valid",
+			env:  map[string]string{"BOTNOTIFY_SERVER_PORT": "delapan"},
+		},
+		{
+			name: "env durasi tidak valid",
+			env:  map[string]string{"BOTNOTIFY_WHATSAPP_RETRY_DELAY": "5"},
+		},
+		{
+			name: "flag key tidak dikenal",
+			args: []string{"--set", "server.tidak_ada=1"},
+		},
+		{
+			name: "flag tanpa nilai",
+			args: []string{"--set", "server.port"},
+		},
+		{
+			name: "flag boolean tidak valid",
+			args: []string{"--set", "server.dev_mode=mungkin"},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			path := writeTestConfig(t, testYAML)
+			for name, value := range tc.env {
+				t.Setenv(name, value)
+			}
+
+			if _, err := loadTestConfig(path, tc.args...); err == nil {
+				t.Error("ingin error, didapat nil")
+			}
+		})
+	}
+}
+
+func TestLoaderLookupEnv(t *testing.T) {
+	path := writeTestConfig(t, testYAML)
+	t.Setenv("BOTNOTIFY_SERVER_PORT", "9100")
+
+	// LookupEnv menggantikan environment proses sepenuhnya
+	loader := NewLoader()
+	loader.Path = path
+	loader.LookupEnv = func(key string) (string, bool) {
+		if key == "BOTNOTIFY_LOGGING_LEVEL" {
+			return "debug", true
+		}
+		return "", false
+	}
+
+	cfg, err := loader.Load()
+	if err != nil {
+		t.Fatalf("gagal memuat konfigurasi: %v", err)
+	}
+	if cfg.Server.Port != 9000 || cfg.Logging.Level != "debug" {
+		t.Errorf("port = %d, level = %q, ingin 9000 dan debug", cfg.Server.Port, cfg.Logging.Level)
+	}
+}
+
+func TestOverridden(t *testing.T) {
+	path := writeTestConfig(t, testYAML)
+	t.Setenv("BOTNOTIFY_SERVER_PORT", "9100")
+	t.Setenv("BOTNOTIFY_LOGGING_LEVEL", "debug")
+
+	cfg, err := loadTestConfig(path, "--port", "9200", "--base-url", "https://bot.example.com")
+	if err != nil {
+		t.Fatalf("gagal memuat konfigurasi: %v", err)
+	}
+
+	// Key yang di-override env lalu flag hanya dicatat sekali
+	want := []string{"server.port", "logging.level", "server.base_url"}
+	if got := cfg.Overridden(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Overridden = %v, ingin %v", got, want)
+	}
+
+	sources := map[string]string{
+		"server.port":     "flag",
+		"logging.level":   "BOTNOTIFY_LOGGING_LEVEL",
+		"server.base_url": "flag",
+	}
+	for key, want := range sources {
+		if got, ok := cfg.OverrideSource(key); !ok || got != want {
+			t.Errorf("sumber %s = %q (%v), ingin %q", key, got, ok, want)
+		}
+	}
+	if source, ok := cfg.OverrideSource("auth.access_token"); ok {
+		t.Errorf("auth.access_token tercatat dari %q, ingin dari file", source)
+	}
+}
+
+func TestFileLayer(t *testing.T) {
+	path := writeTestConfig(t, testYAML)
+	t.Setenv("BOTNOTIFY_AUTH_ACCESS_TOKEN", "token-env")
+	t.Setenv("BOTNOTIFY_WHATSAPP_MAX_RETRY", "9")
+
+	cfg, err := loadTestConfig(path, "--port", "9200")
+	if err != nil {
+		t.Fatalf("gagal memuat konfigurasi: %v", err)
+	}
+
+	layer := cfg.fileLayer()
+	if layer.Auth.AccessToken != "token-dari-file" || layer.Server.Port != 9000 || layer.WhatsApp.MaxRetry != 5 {
+		t.Errorf("fileLayer = token %q, port %d, max_retry %d, ingin nilai dari file dan default",
+			layer.Auth.AccessToken, layer.Server.Port, layer.WhatsApp.MaxRetry)
+	}
+
+	// Konfigurasi asal tetap berisi nilai dari env dan flag
+	if cfg.Auth.AccessToken != "token-env" || cfg.Server.Port != 9200 || cfg.WhatsApp.MaxRetry != 9 {
+		t.Errorf("konfigurasi asal berubah: token %q, port %d, max_retry %d",
+			cfg.Auth.AccessToken, cfg.Server.Port, cfg.WhatsApp.MaxRetry)
+	}
+}
+
+func TestSaveKeepsOverridesOutOfFile(t *testing.T) {
+	path := writeTestConfig(t, testYAML)
+	t.Setenv("BOTNOTIFY_AUTH_ACCESS_TOKEN", "token-env")
+
+	cfg, err := loadTestConfig(path, "--port", "9200")
+	if err != nil {
+		t.Fatalf("gagal memuat konfigurasi: %v", err)
+	}
+	live := NewLive(cfg)
+
+	// Pengaturan dari env atau flag tidak boleh diubah lewat Save
+	for key, fn := range map[string]func(cfg *Config) error{
+		"auth.access_token": func(cfg *Config) error { cfg.Auth.AccessToken = "token-baru"; return nil },
+		"server.port":       func(cfg *Config) error { cfg.Server.Port = 9300; return nil },
+	} {
+		if _, err := live.Save(fn); !errors.Is(err, ErrOverridden) {
+			t.Errorf("Save %s: error = %v, ingin %v", key, err, ErrOverridden)
+		}
+	}
+
+	changes, err := live.Save(func(cfg *Config) error {
+		cfg.Logging.Level = "error"
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("gagal menyimpan konfigurasi: %v", err)
+	}
+	if len(changes) != 1 || changes[0].Key != "logging.level" {
+		t.Errorf("perubahan = %v, ingin hanya logging.level", changes)
+	}
+
+	saved, err := LoadFromPath(path)
+	if err != nil {
+		t.Fatalf("gagal membaca file konfigurasi: %v", err)
+	}
+	if saved.Auth.AccessToken != "token-dari-file" || saved.Server.Port != 9000 {
+		t.Errorf("file berisi token %q dan port %d, ingin nilai asli dari file", saved.Auth.AccessToken, saved.Server.Port)
+	}
+	if saved.Logging.Level != "error" {
+		t.Errorf("logging.level di file = %q, ingin error", saved.Logging.Level)
+	}
+
+	// Konfigurasi yang berjalan tetap memakai nilai dari env dan flag
+	running := live.Get()
+	if running.Auth.AccessToken != "token-env" || running.Server.Port != 9200 || running.Logging.Level != "error" {
+		t.Errorf("konfigurasi berjalan = token %q, port %d, level %q",
+			running.Auth.AccessToken, running.Server.Port, running.Logging.Level)
+	}
+}
@@ -1,11 +1,18 @@
 package config
 
 import (
+	"crypto/subtle"
 	"time"
 
 	"github.com/gwenziro/bot-notify/internal/utils"
 )
 
+// DefaultMaxDocumentSize adalah batas ukuran dokumen default dalam MB
+const DefaultMaxDocumentSize = 16
+
+// DefaultTokenRotationGrace adalah masa berlaku token lama setelah rotasi
+const DefaultTokenRotationGrace = time.Hour
+
 // Config adalah struktur konfigurasi utama
 type Config struct {
 	Server   ServerConfig   `yaml:"server"`
@@ -13,6 +20,24 @@ type Config struct {
 	Auth     AuthConfig     `yaml:"auth"`
 	Storage  StorageConfig  `yaml:"storage"`
 	Logging  LoggingConfig  `yaml:"logging"`
+	Webhooks WebhookConfig  `yaml:"webhooks"`
+
+	// path adalah lokasi file tempat konfigurasi dimuat
+	path string
+	// base adalah konfigurasi dari file sebelum env dan flag diterapkan
+	base *Config
+	// overridden berisi key yang nilainya berasal dari env atau flag
+	overridden []string
+	// overrideSources berisi sumber nilai setiap key di overridden, misal nama environment variable
+	overrideSources map[string]string
+}
+
+// Path mengembalikan lokasi file konfigurasi yang dimuat
+func (cfg *Config) Path() string {
+	if cfg.path == "" {
+		return DefaultPath()
+	}
+	return cfg.path
 }
 
 // ServerConfig berisi konfigurasi untuk web server
@@ -23,15 +48,46 @@ type ServerConfig struct {
 	WriteTimeout    time.Duration `yaml:"write_timeout"`
 	ShutdownTimeout time.Duration `yaml:"shutdown_timeout"`
 	BaseURL         string        `yaml:"base_url"`
+	// DevMode membuat masalah validasi konfigurasi hanya menjadi peringatan
+	DevMode bool `yaml:"dev_mode"`
+	// ViewsDir dan StaticDir membaca template dan aset dari disk, bukan dari binary.
+	// Kosongkan untuk memakai aset yang di-embed.
+	ViewsDir  string `yaml:"views_dir"`
+	StaticDir string `yaml:"static_dir"`
 }
 
 // WhatsAppConfig berisi konfigurasi untuk layanan WhatsApp
 type WhatsAppConfig struct {
 	StoreDir    string        `yaml:"store_dir"`
-	QrCodeDir   string        `yaml:"qr_code_dir"`
 	MaxRetry    int           `yaml:"max_retry"`
 	RetryDelay  time.Duration `yaml:"retry_delay"`
 	IdleTimeout time.Duration `yaml:"idle_timeout"`
+	// MaxDocumentSize adalah ukuran maksimum dokumen yang dikirim dalam MB
+	MaxDocumentSize int `yaml:"max_document_size"`
+	// QueueWorkers adalah jumlah worker pengirim pesan dari antrean
+	QueueWorkers int `yaml:"queue_workers"`
+	// SendRetry adalah kebijakan retry default untuk pesan di antrean
+	SendRetry SendRetryConfig `yaml:"send_retry"`
+	// QueueRetention adalah lama pesan terkirim, dibatalkan, atau di dead-letter queue
+	// disimpan sebelum dihapus. 0 berarti disimpan selamanya.
+	QueueRetention time.Duration `yaml:"queue_retention"`
+}
+
+// SendRetryConfig berisi kebijakan retry default untuk pengiriman pesan dari antrean
+type SendRetryConfig struct {
+	MaxAttempts    int           `yaml:"max_attempts"`
+	InitialBackoff time.Duration `yaml:"initial_backoff"`
+	MaxBackoff     time.Duration `yaml:"max_backoff"`
+	// RetryOn berisi kelas error yang dicoba ulang, kosong berarti memakai default
+	RetryOn []string `yaml:"retry_on"`
+}
+
+// MaxDocumentBytes mengembalikan batas ukuran dokumen dalam byte
+func (w WhatsAppConfig) MaxDocumentBytes() int64 {
+	if w.MaxDocumentSize <= 0 {
+		return DefaultMaxDocumentSize << 20
+	}
+	return int64(w.MaxDocumentSize) << 20
 }
 
 // AuthConfig berisi konfigurasi untuk autentikasi
@@ -42,6 +98,45 @@ type AuthConfig struct {
 	SessionDir   string        `yaml:"session_dir"`
 	CookieName   string        `yaml:"cookie_name"`
 	CookieMaxAge int           `yaml:"cookie_max_age"`
+	// TokenRotationGrace adalah masa token lama tetap diterima API setelah rotasi
+	TokenRotationGrace time.Duration `yaml:"token_rotation_grace"`
+	// PreviousAccessToken dan PreviousTokenExpiresAt diisi otomatis saat rotasi token
+	PreviousAccessToken    string    `yaml:"previous_access_token,omitempty"`
+	PreviousTokenExpiresAt time.Time `yaml:"previous_token_expires_at,omitempty"`
+}
+
+// RotationGrace mengembalikan masa tenggang token lama setelah rotasi
+func (a AuthConfig) RotationGrace() time.Duration {
+	if a.TokenRotationGrace <= 0 {
+		return DefaultTokenRotationGrace
+	}
+	return a.TokenRotationGrace
+}
+
+// RotateAccessToken mengganti access token. Token lama tetap diterima
+// sampai masa tenggang rotasi berakhir.
+func (a *AuthConfig) RotateAccessToken(token string, now time.Time) {
+	a.PreviousAccessToken = a.AccessToken
+	a.PreviousTokenExpiresAt = now.Add(a.RotationGrace())
+	a.AccessToken = token
+}
+
+// PreviousTokenActive memeriksa apakah token lama masih dalam masa tenggang
+func (a AuthConfig) PreviousTokenActive(now time.Time) bool {
+	return a.PreviousAccessToken != "" && now.Before(a.PreviousTokenExpiresAt)
+}
+
+// AcceptsToken memeriksa token terhadap access token saat ini dan token lama
+// yang masih dalam masa tenggang, dengan perbandingan waktu konstan
+func (a AuthConfig) AcceptsToken(token string, now time.Time) bool {
+	if token == "" {
+		return false
+	}
+	if a.AccessToken != "" && subtle.ConstantTimeCompare([]byte(token), []byte(a.AccessToken)) == 1 {
+		return true
+	}
+	return a.PreviousTokenActive(now) &&
+		subtle.ConstantTimeCompare([]byte(token), []byte(a.PreviousAccessToken)) == 1
 }
 
 // StorageConfig berisi konfigurasi untuk penyimpanan data
@@ -53,12 +148,58 @@ type StorageConfig struct {
 
 // LoggingConfig adalah konfigurasi untuk logger
 type LoggingConfig struct {
-	Level      string `yaml:"level"`
-	File       string `yaml:"file"`
-	MaxSize    int    `yaml:"max_size"`
-	MaxBackups int    `yaml:"max_backups"`
-	MaxAge     int    `yaml:"max_age"`
-	Compress   bool   `yaml:"compress"`
+	Level string `yaml:"level"`
+	// PersistLevel adalah level minimum log yang disimpan ke storage dan tampil di halaman log
+	PersistLevel string `yaml:"persist_level"`
+	File         string `yaml:"file"`
+	MaxSize      int    `yaml:"max_size"`
+	MaxBackups   int    `yaml:"max_backups"`
+	MaxAge       int    `yaml:"max_age"`
+	Compress     bool   `yaml:"compress"`
+	// Retention membatasi log yang disimpan ke storage
+	Retention LogRetentionConfig `yaml:"retention"`
+}
+
+// LogRetentionConfig adalah batas penyimpanan log di storage. Nilai 0 berarti tanpa batas.
+// Log terlama dihapus lebih dulu oleh pruner yang berjalan di background.
+type LogRetentionConfig struct {
+	MaxAge LogMaxAgeConfig `yaml:"max_age"`
+	// MaxEntries adalah jumlah maksimum log yang disimpan
+	MaxEntries int `yaml:"max_entries"`
+	// MaxBytes adalah perkiraan ukuran maksimum log yang disimpan dalam byte
+	MaxBytes int `yaml:"max_bytes"`
+	// Interval adalah jarak antar pruning, 0 menonaktifkan pruning otomatis
+	Interval time.Duration `yaml:"interval"`
+}
+
+// LogMaxAgeConfig adalah usia maksimum log untuk setiap level
+type LogMaxAgeConfig struct {
+	Debug   time.Duration `yaml:"debug"`
+	Info    time.Duration `yaml:"info"`
+	Warning time.Duration `yaml:"warning"`
+	Error   time.Duration `yaml:"error"`
+	Fatal   time.Duration `yaml:"fatal"`
+}
+
+// WebhookConfig berisi konfigurasi pengiriman pesan masuk ke webhook eksternal
+type WebhookConfig struct {
+	Subscriptions  []WebhookSubscription `yaml:"subscriptions"`
+	Timeout        time.Duration         `yaml:"timeout"`
+	MaxAttempts    int                   `yaml:"max_attempts"`
+	InitialBackoff time.Duration         `yaml:"initial_backoff"`
+	MaxBackoff     time.Duration         `yaml:"max_backoff"`
+	// Workers adalah jumlah worker pengirim webhook
+	Workers int `yaml:"workers"`
+}
+
+// WebhookSubscription adalah satu tujuan webhook untuk pesan masuk
+type WebhookSubscription struct {
+	Name string `yaml:"name"`
+	URL  string `yaml:"url"`
+	// Secret dipakai untuk menandatangani payload dengan HMAC-SHA256
+	Secret string `yaml:"secret"`
+	// IncludeGroups menentukan apakah pesan dari grup ikut dikirim
+	IncludeGroups bool `yaml:"include_groups"`
 }
 
 // GetLogConfig mengkonversi LoggingConfig ke utils.LogConfig
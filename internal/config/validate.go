@@ -0,0 +1,247 @@
+package config
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/gwenziro/bot-notify/internal/utils"
+	"github.com/sirupsen/logrus"
+)
+
+// minSecretLength adalah panjang minimum access token dan token secret
+const minSecretLength = 16
+
+// defaultSecrets berisi nilai contoh yang tidak boleh dipakai di produksi
+var defaultSecrets = map[string]bool{
+	"change-this-to-your-api-token":         true,
+	"change-this-to-your-api-access-token":  true,
+	"change-this-to-secure-random-string":   true,
+	"change-this-to-a-secure-random-string": true,
+	"change-this-to-a-webhook-secret":       true,
+}
+
+// storageTypes berisi tipe storage yang didukung
+var storageTypes = []string{"badger"}
+
+// ValidationError berisi semua masalah yang ditemukan saat validasi konfigurasi
+type ValidationError struct {
+	Problems []string
+}
+
+// Error mengimplementasikan interface error
+func (e *ValidationError) Error() string {
+	return fmt.Sprintf("konfigurasi tidak valid: %s", strings.Join(e.Problems, "; "))
+}
+
+// validator mengumpulkan masalah konfigurasi
+type validator struct {
+	problems []string
+}
+
+// addf menambahkan satu masalah
+func (v *validator) addf(format string, args ...interface{}) {
+	v.problems = append(v.problems, fmt.Sprintf(format, args...))
+}
+
+// positive memeriksa durasi yang harus lebih dari nol
+func (v *validator) positive(key string, d time.Duration) {
+	if d <= 0 {
+		v.addf("%s harus lebih dari 0, didapat %s", key, d)
+	}
+}
+
+// nonNegative memeriksa angka atau durasi yang tidak boleh negatif
+func (v *validator) nonNegative(key string, n int64) {
+	if n < 0 {
+		v.addf("%s tidak boleh negatif", key)
+	}
+}
+
+// secret memeriksa nilai rahasia tidak kosong, bukan nilai contoh, dan cukup panjang
+func (v *validator) secret(key, value string) {
+	switch {
+	case value == "":
+		v.addf("%s harus diisi", key)
+	case defaultSecrets[value]:
+		v.addf("%s masih memakai nilai contoh, ganti dengan nilai acak (atau set %s)", key, EnvName(key))
+	case len(value) < minSecretLength:
+		v.addf("%s terlalu pendek, minimal %d karakter", key, minSecretLength)
+	}
+}
+
+// writableDir memeriksa direktori dapat ditulisi tanpa membuat file atau direktori,
+// karena validasi juga dijalankan setiap reload. Direktori yang belum ada diperiksa
+// dari induk terdekat yang sudah ada, dan baru dibuat saat start oleh EnsureDirs.
+func (v *validator) writableDir(key, dir string) {
+	if dir == "" {
+		v.addf("%s harus diisi", key)
+		return
+	}
+
+	path := dir
+	for {
+		info, err := os.Stat(path)
+		if err == nil {
+			if !info.IsDir() {
+				v.addf("%s: %s bukan direktori", key, path)
+				return
+			}
+			break
+		}
+		parent := filepath.Dir(path)
+		if !os.IsNotExist(err) || parent == path {
+			v.addf("%s: direktori %s tidak dapat diperiksa: %v", key, dir, err)
+			return
+		}
+		path = parent
+	}
+
+	if err := checkWritable(path); err != nil {
+		v.addf("%s: direktori %s tidak dapat ditulisi: %v", key, path, err)
+	}
+}
+
+// existingDir memeriksa direktori opsional yang harus sudah ada jika diisi
+func (v *validator) existingDir(key, dir string) {
+	if dir != "" && !utils.DirectoryExists(dir) {
+		v.addf("%s tidak ditemukan: %s", key, dir)
+	}
+}
+
+// httpURL memeriksa URL http atau https yang valid
+func (v *validator) httpURL(key, value string) {
+	u, err := url.Parse(value)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		v.addf("%s harus berupa URL http atau https yang valid, didapat %q", key, value)
+	}
+}
+
+// dataDirs mengembalikan direktori yang ditulisi aplikasi sesuai konfigurasi
+func (cfg *Config) dataDirs() []string {
+	dirs := []string{cfg.WhatsApp.StoreDir, cfg.Auth.SessionDir}
+	if !cfg.Storage.InMemory {
+		dirs = append(dirs, cfg.Storage.Path)
+	}
+	if cfg.Logging.File != "" {
+		dirs = append(dirs, filepath.Dir(cfg.Logging.File))
+	}
+	return dirs
+}
+
+// EnsureDirs membuat direktori data yang belum ada. Dipanggil sekali saat start,
+// setelah konfigurasi divalidasi.
+func (cfg *Config) EnsureDirs() error {
+	for _, dir := range cfg.dataDirs() {
+		if dir == "" {
+			continue
+		}
+		if err := utils.EnsureDirectoryExists(dir); err != nil {
+			return fmt.Errorf("gagal membuat direktori %s: %w", dir, err)
+		}
+	}
+	return nil
+}
+
+// Validate memeriksa konfigurasi dan mengembalikan *ValidationError
+// yang berisi semua masalah yang ditemukan
+func (cfg *Config) Validate() error {
+	v := &validator{}
+
+	// Server
+	if cfg.Server.Host == "" {
+		v.addf("server.host harus diisi")
+	}
+	if cfg.Server.Port < 1 || cfg.Server.Port > 65535 {
+		v.addf("server.port harus antara 1 dan 65535, didapat %d", cfg.Server.Port)
+	}
+	v.httpURL("server.base_url", cfg.Server.BaseURL)
+	v.nonNegative("server.read_timeout", int64(cfg.Server.ReadTimeout))
+	v.nonNegative("server.write_timeout", int64(cfg.Server.WriteTimeout))
+	v.nonNegative("server.shutdown_timeout", int64(cfg.Server.ShutdownTimeout))
+	v.existingDir("server.views_dir", cfg.Server.ViewsDir)
+	v.existingDir("server.static_dir", cfg.Server.StaticDir)
+
+	// WhatsApp
+	v.writableDir("whatsapp.store_dir", cfg.WhatsApp.StoreDir)
+	v.nonNegative("whatsapp.max_retry", int64(cfg.WhatsApp.MaxRetry))
+	v.positive("whatsapp.retry_delay", cfg.WhatsApp.RetryDelay)
+	v.nonNegative("whatsapp.idle_timeout", int64(cfg.WhatsApp.IdleTimeout))
+	v.nonNegative("whatsapp.max_document_size", int64(cfg.WhatsApp.MaxDocumentSize))
+	v.nonNegative("whatsapp.queue_workers", int64(cfg.WhatsApp.QueueWorkers))
+	v.nonNegative("whatsapp.send_retry.max_attempts", int64(cfg.WhatsApp.SendRetry.MaxAttempts))
+	v.nonNegative("whatsapp.send_retry.initial_backoff", int64(cfg.WhatsApp.SendRetry.InitialBackoff))
+	v.nonNegative("whatsapp.send_retry.max_backoff", int64(cfg.WhatsApp.SendRetry.MaxBackoff))
+	v.nonNegative("whatsapp.queue_retention", int64(cfg.WhatsApp.QueueRetention))
+	if r := cfg.WhatsApp.SendRetry; r.MaxBackoff > 0 && r.MaxBackoff < r.InitialBackoff {
+		v.addf("whatsapp.send_retry.max_backoff (%s) lebih kecil dari initial_backoff (%s)", r.MaxBackoff, r.InitialBackoff)
+	}
+
+	// Auth
+	v.secret("auth.access_token", cfg.Auth.AccessToken)
+	v.secret("auth.token_secret", cfg.Auth.TokenSecret)
+	v.positive("auth.token_expiry", cfg.Auth.TokenExpiry)
+	v.nonNegative("auth.token_rotation_grace", int64(cfg.Auth.TokenRotationGrace))
+	v.writableDir("auth.session_dir", cfg.Auth.SessionDir)
+	if cfg.Auth.CookieName == "" {
+		v.addf("auth.cookie_name harus diisi")
+	}
+	v.nonNegative("auth.cookie_max_age", int64(cfg.Auth.CookieMaxAge))
+
+	// Storage
+	knownStorage := false
+	for _, t := range storageTypes {
+		if cfg.Storage.Type == t {
+			knownStorage = true
+		}
+	}
+	if !knownStorage {
+		v.addf("storage.type tidak dikenal: %q (didukung: %s)", cfg.Storage.Type, strings.Join(storageTypes, ", "))
+	}
+	if !cfg.Storage.InMemory {
+		v.writableDir("storage.path", cfg.Storage.Path)
+	}
+
+	// Logging
+	if _, err := logrus.ParseLevel(cfg.Logging.Level); err != nil {
+		v.addf("logging.level tidak dikenal: %q (gunakan debug, info, warn, atau error)", cfg.Logging.Level)
+	}
+	if _, err := logrus.ParseLevel(cfg.Logging.PersistLevel); err != nil {
+		v.addf("logging.persist_level tidak dikenal: %q (gunakan debug, info, warn, atau error)", cfg.Logging.PersistLevel)
+	}
+	if cfg.Logging.File != "" {
+		v.writableDir("logging.file", filepath.Dir(cfg.Logging.File))
+	}
+	retention := cfg.Logging.Retention
+	v.nonNegative("logging.retention.max_age.debug", int64(retention.MaxAge.Debug))
+	v.nonNegative("logging.retention.max_age.info", int64(retention.MaxAge.Info))
+	v.nonNegative("logging.retention.max_age.warning", int64(retention.MaxAge.Warning))
+	v.nonNegative("logging.retention.max_age.error", int64(retention.MaxAge.Error))
+	v.nonNegative("logging.retention.max_age.fatal", int64(retention.MaxAge.Fatal))
+	v.nonNegative("logging.retention.max_entries", int64(retention.MaxEntries))
+	v.nonNegative("logging.retention.max_bytes", int64(retention.MaxBytes))
+	v.nonNegative("logging.retention.interval", int64(retention.Interval))
+
+	// Webhooks
+	v.nonNegative("webhooks.timeout", int64(cfg.Webhooks.Timeout))
+	v.nonNegative("webhooks.max_attempts", int64(cfg.Webhooks.MaxAttempts))
+	v.nonNegative("webhooks.workers", int64(cfg.Webhooks.Workers))
+	for i, sub := range cfg.Webhooks.Subscriptions {
+		key := fmt.Sprintf("webhooks.subscriptions[%d]", i)
+		if sub.Name == "" {
+			v.addf("%s.name harus diisi", key)
+		}
+		v.httpURL(key+".url", sub.URL)
+		if defaultSecrets[sub.Secret] {
+			v.addf("%s.secret masih memakai nilai contoh", key)
+		}
+	}
+
+	if len(v.problems) > 0 {
+		return &ValidationError{Problems: v.problems}
+	}
+	return nil
+}
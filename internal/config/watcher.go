@@ -0,0 +1,157 @@
+package config
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/gwenziro/bot-notify/internal/utils"
+)
+
+// reloadDebounce menunda reload agar beberapa event tulis dari editor digabung menjadi satu
+const reloadDebounce = 500 * time.Millisecond
+
+// Watcher memantau file konfigurasi dan sinyal SIGHUP, lalu me-reload konfigurasi.
+// Konfigurasi baru dipublikasikan melalui Live sehingga semua komponen yang membaca
+// Live.Get langsung melihat nilai baru.
+type Watcher struct {
+	live   *Live
+	loader *Loader
+	path   string
+	logger utils.LogrusEntry
+	mu     sync.Mutex
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// NewWatcher membuat instance baru Watcher untuk konfigurasi yang sedang berjalan.
+// loader yang sama dipakai saat reload agar override dari env dan flag tetap berlaku.
+func NewWatcher(live *Live, loader *Loader) *Watcher {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	return &Watcher{
+		live:   live,
+		loader: loader,
+		path:   filepath.Clean(live.Get().Path()),
+		logger: utils.ForModule("config"),
+		ctx:    ctx,
+		cancel: cancel,
+	}
+}
+
+// Start mulai memantau file konfigurasi dan sinyal SIGHUP
+func (w *Watcher) Start() error {
+	fsWatcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return fmt.Errorf("gagal membuat file watcher: %w", err)
+	}
+
+	// Pantau direktorinya, karena banyak editor menyimpan file dengan rename
+	if err := fsWatcher.Add(filepath.Dir(w.path)); err != nil {
+		fsWatcher.Close()
+		return fmt.Errorf("gagal memantau direktori konfigurasi: %w", err)
+	}
+
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+
+	w.logger.WithField("path", w.path).Info("Memantau perubahan file konfigurasi")
+
+	w.wg.Add(1)
+	go w.loop(fsWatcher, hup)
+	return nil
+}
+
+// Stop menghentikan pemantauan
+func (w *Watcher) Stop() {
+	w.cancel()
+	w.wg.Wait()
+}
+
+// loop menunggu event file atau sinyal lalu menjalankan reload
+func (w *Watcher) loop(fsWatcher *fsnotify.Watcher, hup chan os.Signal) {
+	defer w.wg.Done()
+	defer fsWatcher.Close()
+	defer signal.Stop(hup)
+
+	debounce := time.NewTimer(reloadDebounce)
+	debounce.Stop()
+	defer debounce.Stop()
+
+	for {
+		select {
+		case <-w.ctx.Done():
+			return
+
+		case <-hup:
+			w.logger.Info("Menerima SIGHUP, me-reload konfigurasi")
+			w.reload("sighup")
+
+		case event, ok := <-fsWatcher.Events:
+			if !ok {
+				return
+			}
+			if filepath.Clean(event.Name) != w.path {
+				continue
+			}
+			if event.Has(fsnotify.Write) || event.Has(fsnotify.Create) || event.Has(fsnotify.Rename) {
+				debounce.Reset(reloadDebounce)
+			}
+
+		case <-debounce.C:
+			w.reload("file")
+
+		case err, ok := <-fsWatcher.Errors:
+			if !ok {
+				return
+			}
+			w.logger.WithError(err).Warn("Error dari file watcher konfigurasi")
+		}
+	}
+}
+
+// reload menjalankan Reload dan mencatat error-nya
+func (w *Watcher) reload(source string) {
+	if err := w.Reload(source); err != nil {
+		w.logger.WithError(err).Error("Reload konfigurasi gagal, konfigurasi lama tetap dipakai")
+	}
+}
+
+// Reload membaca ulang file konfigurasi, memvalidasinya, lalu menerapkan perubahan
+// dan memberi tahu subscriber. Jika file tidak valid, konfigurasi lama tetap dipakai.
+func (w *Watcher) Reload(source string) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	next, err := w.loader.Load()
+	if err != nil {
+		return err
+	}
+	if err := next.Validate(); err != nil {
+		if !next.Server.DevMode {
+			return err
+		}
+		w.logger.WithError(err).Warn("Konfigurasi tidak valid, tetap diterapkan karena dev_mode aktif")
+	}
+
+	changes := w.live.Apply(next)
+	if len(changes) == 0 {
+		w.logger.WithField("source", source).Debug("Konfigurasi di-reload tanpa perubahan")
+		return nil
+	}
+
+	w.logger.WithFields(utils.Fields{
+		"source":  source,
+		"changes": len(changes),
+	}).Info("Konfigurasi berhasil di-reload")
+
+	return nil
+}
@@ -0,0 +1,20 @@
+//go:build !unix
+
+package config
+
+import (
+	"errors"
+	"os"
+)
+
+// checkWritable memeriksa bit izin tulis direktori yang sudah ada
+func checkWritable(dir string) error {
+	info, err := os.Stat(dir)
+	if err != nil {
+		return err
+	}
+	if info.Mode().Perm()&0200 == 0 {
+		return errors.New("direktori hanya dapat dibaca")
+	}
+	return nil
+}
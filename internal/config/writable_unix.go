@@ -0,0 +1,10 @@
+//go:build unix
+
+package config
+
+import "golang.org/x/sys/unix"
+
+// checkWritable memeriksa izin tulis proses pada direktori yang sudah ada
+func checkWritable(dir string) error {
+	return unix.Access(dir, unix.W_OK)
+}
@@ -0,0 +1,151 @@
+package metrics
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/middleware/adaptor"
+	"github.com/gofiber/fiber/v2/utils"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// namespace adalah prefix untuk semua metric aplikasi
+const namespace = "botnotify"
+
+var (
+	// MessagesSent menghitung pesan yang berhasil dikirim per tipe penerima
+	MessagesSent = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "messages_sent_total",
+		Help:      "Jumlah pesan WhatsApp yang berhasil dikirim.",
+	}, []string{"type"})
+
+	// MessagesFailed menghitung percobaan kirim yang gagal per tipe penerima
+	MessagesFailed = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "messages_failed_total",
+		Help:      "Jumlah percobaan kirim pesan WhatsApp yang gagal.",
+	}, []string{"type"})
+
+	// SendDuration mengukur latensi pengiriman pesan ke server WhatsApp
+	SendDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "send_duration_seconds",
+		Help:      "Latensi pengiriman pesan WhatsApp dalam detik.",
+		Buckets:   []float64{0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30},
+	}, []string{"type"})
+
+	// ReconnectAttempts menghitung percobaan reconnect per alasan
+	ReconnectAttempts = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "reconnect_attempts_total",
+		Help:      "Jumlah percobaan reconnect ke WhatsApp.",
+	}, []string{"reason"})
+
+	// HTTPRequests menghitung request HTTP per method, route, dan status
+	HTTPRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "http_requests_total",
+		Help:      "Jumlah request HTTP yang diproses.",
+	}, []string{"method", "route", "status"})
+
+	// HTTPDuration mengukur durasi request HTTP per method dan route
+	HTTPDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "http_request_duration_seconds",
+		Help:      "Durasi request HTTP dalam detik.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"method", "route"})
+)
+
+func init() {
+	prometheus.MustRegister(
+		MessagesSent,
+		MessagesFailed,
+		SendDuration,
+		ReconnectAttempts,
+		HTTPRequests,
+		HTTPDuration,
+	)
+}
+
+// ObserveSend mencatat hasil satu percobaan kirim pesan
+func ObserveSend(msgType string, started time.Time, err error) {
+	SendDuration.WithLabelValues(msgType).Observe(time.Since(started).Seconds())
+	if err != nil {
+		MessagesFailed.WithLabelValues(msgType).Inc()
+		return
+	}
+	MessagesSent.WithLabelValues(msgType).Inc()
+}
+
+// statusCollector melaporkan status klien WhatsApp saat ini sebagai gauge.
+// Status aktif bernilai 1, status lainnya 0.
+type statusCollector struct {
+	desc     *prometheus.Desc
+	statuses []string
+	current  func() string
+}
+
+// RegisterClientStatus mendaftarkan gauge status klien WhatsApp.
+// statuses adalah semua status yang mungkin, current mengembalikan status saat ini.
+func RegisterClientStatus(statuses []string, current func() string) {
+	prometheus.MustRegister(&statusCollector{
+		desc: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "whatsapp", "status"),
+			"Status koneksi WhatsApp saat ini (1 untuk status aktif).",
+			[]string{"status"}, nil),
+		statuses: statuses,
+		current:  current,
+	})
+}
+
+// Describe mengimplementasikan prometheus.Collector
+func (c *statusCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.desc
+}
+
+// Collect mengimplementasikan prometheus.Collector
+func (c *statusCollector) Collect(ch chan<- prometheus.Metric) {
+	current := c.current()
+	for _, status := range c.statuses {
+		value := 0.0
+		if status == current {
+			value = 1
+		}
+		ch <- prometheus.MustNewConstMetric(c.desc, prometheus.GaugeValue, value, status)
+	}
+}
+
+// Middleware mencatat jumlah dan durasi request HTTP.
+// Label route memakai pola route Fiber (misal /api/messages/:id) agar kardinalitas tetap kecil.
+func Middleware() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		started := time.Now()
+		err := c.Next()
+
+		status := c.Response().StatusCode()
+		if err != nil {
+			// Error handler belum dijalankan, ambil kode dari error Fiber jika ada
+			status = fiber.StatusInternalServerError
+			if e, ok := err.(*fiber.Error); ok {
+				status = e.Code
+			}
+		}
+
+		// Method dari Fiber memakai buffer request yang dipakai ulang, salin sebelum jadi label
+		method := utils.CopyString(c.Method())
+		route := c.Route().Path
+		HTTPRequests.WithLabelValues(method, route, strconv.Itoa(status)).Inc()
+		HTTPDuration.WithLabelValues(method, route).Observe(time.Since(started).Seconds())
+
+		return err
+	}
+}
+
+// Handler mengembalikan handler Fiber untuk endpoint /metrics
+func Handler() fiber.Handler {
+	return adaptor.HTTPHandler(promhttp.Handler())
+}
@@ -0,0 +1,289 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gwenziro/bot-notify/internal/api/model"
+	"github.com/gwenziro/bot-notify/internal/storage"
+)
+
+const (
+	// logStatPrefix adalah prefix key counter log yang diperbarui setiap log disimpan atau dihapus:
+	//   logstat:version
+	//   logstat:level:{LEVEL}
+	//   logstat:source:{SOURCE}
+	//   logstat:hour:{yyyymmddhh UTC}:{LEVEL}
+	//   logstat:bytes
+	logStatPrefix = "logstat"
+
+	// logStatVersion menandai counter sudah dibangun dari log yang ada. Dinaikkan saat
+	// jenis counter bertambah agar counter dibangun ulang dari log yang tersimpan.
+	logStatVersion = "2"
+
+	// logHourFormat adalah format jam pada key counter per jam
+	logHourFormat = "2006010215"
+
+	// logHourlyHistory adalah lama counter per jam disimpan, sedikit lebih dari rentang ringkasan
+	logHourlyHistory = 8 * 24 * time.Hour
+
+	// summaryHours adalah rentang tingkat error per jam pada ringkasan log
+	summaryHours = 7 * 24
+)
+
+// logCounters adalah salinan counter log di memori. Counter level, source, dan ukuran
+// mengikuti log yang tersimpan, sedangkan counter per jam mencatat log yang masuk dan tidak
+// berkurang saat log dihapus retensi.
+type logCounters struct {
+	byLevel  map[string]int
+	bySource map[string]int
+	hourly   map[string]map[string]int
+	bytes    int64
+}
+
+func newLogCounters() *logCounters {
+	return &logCounters{
+		byLevel:  make(map[string]int),
+		bySource: make(map[string]int),
+		hourly:   make(map[string]map[string]int),
+	}
+}
+
+// add mengubah counter level dan source, lalu mencatat nilai barunya ke entries
+func (c *logCounters) add(level, source string, delta int, entries map[string][]byte) {
+	level = strings.ToUpper(level)
+	source = strings.ToUpper(source)
+
+	c.byLevel[level] += delta
+	entries[logStatPrefix+":level:"+level] = []byte(strconv.Itoa(c.byLevel[level]))
+	if c.byLevel[level] <= 0 {
+		delete(c.byLevel, level)
+	}
+
+	c.bySource[source] += delta
+	entries[logStatPrefix+":source:"+source] = []byte(strconv.Itoa(c.bySource[source]))
+	if c.bySource[source] <= 0 {
+		delete(c.bySource, source)
+	}
+}
+
+// addBytes mengubah total ukuran log beserta indeksnya, lalu mencatat nilai barunya ke entries
+func (c *logCounters) addBytes(delta int64, entries map[string][]byte) {
+	c.bytes += delta
+	entries[logStatPrefix+":bytes"] = []byte(strconv.FormatInt(c.bytes, 10))
+}
+
+// addHour mengubah counter jam masuknya log, lalu mencatat nilai barunya ke entries
+func (c *logCounters) addHour(at time.Time, level string, delta int, entries map[string][]byte) {
+	level = strings.ToUpper(level)
+	hour := at.UTC().Format(logHourFormat)
+
+	if c.hourly[hour] == nil {
+		c.hourly[hour] = make(map[string]int)
+	}
+	c.hourly[hour][level] += delta
+	entries[fmt.Sprintf("%s:hour:%s:%s", logStatPrefix, hour, level)] = []byte(strconv.Itoa(c.hourly[hour][level]))
+}
+
+// loadCounters memuat counter log dari storage, atau membangunnya dari log yang ada
+// jika belum pernah dibuat. Harus dipanggil dengan countersMu terkunci.
+func (r *LogRepository) loadCounters(ctx context.Context) error {
+	if r.counters != nil {
+		return nil
+	}
+
+	version, err := r.storage.Get(ctx, logStatPrefix+":version")
+	if storage.IsNotFound(err) || (err == nil && string(version) != logStatVersion) {
+		return r.rebuildCounters(ctx)
+	}
+	if err != nil {
+		return fmt.Errorf("gagal membaca counter log: %w", err)
+	}
+
+	counters := newLogCounters()
+	prefix := logStatPrefix + ":"
+	err = r.storage.Iterate(ctx, storage.IterateOptions{Prefix: prefix}, func(key string, value []byte) (bool, error) {
+		n, err := strconv.Atoi(string(value))
+		if err != nil || n <= 0 {
+			return true, nil
+		}
+
+		parts := strings.Split(strings.TrimPrefix(key, prefix), ":")
+		switch {
+		case len(parts) == 1 && parts[0] == "bytes":
+			counters.bytes = int64(n)
+		case len(parts) == 2 && parts[0] == "level":
+			counters.byLevel[parts[1]] = n
+		case len(parts) == 2 && parts[0] == "source":
+			counters.bySource[parts[1]] = n
+		case len(parts) == 3 && parts[0] == "hour":
+			if counters.hourly[parts[1]] == nil {
+				counters.hourly[parts[1]] = make(map[string]int)
+			}
+			counters.hourly[parts[1]][parts[2]] = n
+		}
+		return true, nil
+	})
+	if err != nil {
+		return fmt.Errorf("gagal membaca counter log: %w", err)
+	}
+
+	r.counters = counters
+	return nil
+}
+
+// rebuildCounters menghitung ulang counter dari seluruh log yang tersimpan. Hanya dijalankan
+// ketika counter belum ada di storage atau versinya berbeda.
+func (r *LogRepository) rebuildCounters(ctx context.Context) error {
+	if err := r.storage.DeleteWithPrefix(ctx, logStatPrefix+":"); err != nil {
+		return fmt.Errorf("gagal menghapus counter log: %w", err)
+	}
+
+	counters := newLogCounters()
+	entries := map[string][]byte{logStatPrefix + ":version": []byte(logStatVersion)}
+	since := time.Now().Add(-logHourlyHistory)
+
+	err := r.storage.Iterate(ctx, storage.IterateOptions{Prefix: logPrefix + ":"}, func(key string, value []byte) (bool, error) {
+		entry, err := readLogEntry(key, value)
+		if err != nil {
+			return true, nil
+		}
+
+		counters.add(entry.level, entry.source, 1, entries)
+		counters.addBytes(entry.size, entries)
+		if entry.at.After(since) {
+			counters.addHour(entry.at, entry.level, 1, entries)
+		}
+		return true, nil
+	})
+	if err != nil {
+		return fmt.Errorf("gagal membaca logs: %w", err)
+	}
+
+	if err := r.storage.SetBatch(ctx, entries); err != nil {
+		return fmt.Errorf("gagal menyimpan counter log: %w", err)
+	}
+
+	r.logger.WithField("levels", len(counters.byLevel)).Info("Counter log dibangun dari log yang tersimpan")
+	r.counters = counters
+	return nil
+}
+
+// trimHourlyCounters menghapus counter per jam yang lebih lama dari logHourlyHistory
+func (r *LogRepository) trimHourlyCounters(ctx context.Context, now time.Time) error {
+	r.countersMu.Lock()
+	defer r.countersMu.Unlock()
+
+	if err := r.loadCounters(ctx); err != nil {
+		return err
+	}
+
+	cutoff := now.Add(-logHourlyHistory).UTC().Format(logHourFormat)
+	var keys []string
+	for hour, levels := range r.counters.hourly {
+		if hour >= cutoff {
+			continue
+		}
+		for level := range levels {
+			keys = append(keys, fmt.Sprintf("%s:hour:%s:%s", logStatPrefix, hour, level))
+		}
+		delete(r.counters.hourly, hour)
+	}
+
+	if len(keys) == 0 {
+		return nil
+	}
+	if err := r.storage.DeleteBatch(ctx, keys); err != nil {
+		return fmt.Errorf("gagal menghapus counter log lama: %w", err)
+	}
+	return nil
+}
+
+// Summary menyusun ringkasan log dari counter: total per level dan source, log yang masuk
+// hari ini dan 24 jam terakhir, tingkat error per jam selama 7 hari, serta latest log terbaru.
+// Hari ini dihitung dari counter per jam yang dimulai sejak tengah malam zona waktu now.
+func (r *LogRepository) Summary(now time.Time, latest int) (*model.LogSummary, error) {
+	ctx := context.Background()
+
+	r.countersMu.Lock()
+	if err := r.loadCounters(ctx); err != nil {
+		r.countersMu.Unlock()
+		return nil, err
+	}
+
+	summary := &model.LogSummary{
+		ByLevel:     make(map[string]int, len(r.counters.byLevel)),
+		BySource:    make(map[string]int, len(r.counters.bySource)),
+		GeneratedAt: now,
+	}
+	for level, n := range r.counters.byLevel {
+		summary.ByLevel[level] = n
+		summary.TotalLogs += n
+	}
+	for source, n := range r.counters.bySource {
+		summary.BySource[source] = n
+	}
+
+	// Jam pertama yang dihitung untuk 24 jam terakhir dan tingkat error per jam. Counter per jam
+	// mengikuti jam UTC, sehingga pada zona dengan selisih setengah jam (misal +05:30) counter
+	// yang dimulai sebelum tengah malam tidak ikut dihitung sebagai hari ini.
+	midnight := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	current := now.Truncate(time.Hour)
+	last24From := current.Add(-23 * time.Hour)
+	rateFrom := current.Add(-(summaryHours - 1) * time.Hour)
+
+	summary.HourlyRate = make([]model.LogHourlyErrorRate, summaryHours)
+	for i := range summary.HourlyRate {
+		summary.HourlyRate[i].Hour = rateFrom.Add(time.Duration(i) * time.Hour).UTC()
+	}
+
+	for hour, levels := range r.counters.hourly {
+		at, err := time.Parse(logHourFormat, hour)
+		if err != nil || at.After(current) {
+			continue
+		}
+
+		total, errs := 0, 0
+		for level, n := range levels {
+			total += n
+			if level == string(model.LogLevelError) || level == string(model.LogLevelFatal) {
+				errs += n
+			}
+		}
+
+		if !at.Before(midnight) {
+			summary.TodayLogs += total
+		}
+		if !at.Before(last24From) {
+			summary.Last24Hours += total
+		}
+		if !at.Before(rateFrom) {
+			bucket := &summary.HourlyRate[int(at.Sub(rateFrom)/time.Hour)]
+			bucket.Total += total
+			bucket.Errors += errs
+		}
+	}
+	r.countersMu.Unlock()
+
+	summary.ErrorCount = summary.ByLevel[string(model.LogLevelError)] + summary.ByLevel[string(model.LogLevelFatal)]
+	summary.WarningCount = summary.ByLevel[string(model.LogLevelWarning)]
+	for i := range summary.HourlyRate {
+		if bucket := &summary.HourlyRate[i]; bucket.Total > 0 {
+			bucket.Rate = float64(bucket.Errors) / float64(bucket.Total)
+		}
+	}
+
+	summary.Latest = []*model.Log{}
+	if latest > 0 {
+		page, err := r.QueryLogs(model.LogQuery{Limit: latest})
+		if err != nil {
+			return nil, err
+		}
+		summary.Latest = page.Logs
+	}
+
+	return summary, nil
+}
@@ -0,0 +1,44 @@
+package repository
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSummaryToday(t *testing.T) {
+	now := time.Date(2026, 1, 10, 4, 45, 0, 0, time.UTC)
+
+	tests := []struct {
+		name      string
+		zone      *time.Location
+		wantToday int
+	}{
+		{"utc", time.UTC, 1},
+		{"+07:00", time.FixedZone("WIB", 7*60*60), 3},
+		// Tengah malam 18:30 UTC, counter jam 18:00 UTC dimulai sebelum tengah malam
+		{"+05:30", time.FixedZone("IST", 5*60*60+30*60), 2},
+		// Tengah malam 03:30 UTC hari yang sama
+		{"-03:30", time.FixedZone("NST", -(3*60*60 + 30*60)), 1},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			r := newTestRepository(t)
+			saveTestLog(t, r, time.Date(2026, 1, 9, 12, 0, 0, 0, time.UTC), "INFO", "API", "siang-kemarin")
+			saveTestLog(t, r, time.Date(2026, 1, 9, 18, 10, 0, 0, time.UTC), "INFO", "API", "sebelum-tengah-malam-ist")
+			saveTestLog(t, r, time.Date(2026, 1, 9, 19, 5, 0, 0, time.UTC), "ERROR", "API", "setelah-tengah-malam-ist")
+			saveTestLog(t, r, time.Date(2026, 1, 10, 4, 30, 0, 0, time.UTC), "INFO", "WEB", "pagi")
+
+			summary, err := r.Summary(now.In(tc.zone), 0)
+			if err != nil {
+				t.Fatalf("gagal membuat ringkasan: %v", err)
+			}
+			if summary.TodayLogs != tc.wantToday {
+				t.Errorf("TodayLogs = %d, ingin %d", summary.TodayLogs, tc.wantToday)
+			}
+			if summary.Last24Hours != 4 || summary.TotalLogs != 4 {
+				t.Errorf("Last24Hours = %d, TotalLogs = %d, ingin 4", summary.Last24Hours, summary.TotalLogs)
+			}
+		})
+	}
+}
@@ -2,9 +2,12 @@ package repository
 
 import (
 	"context"
+	"encoding/base64"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/google/uuid"
@@ -13,16 +16,41 @@ import (
 	"github.com/gwenziro/bot-notify/internal/utils"
 )
 
+const (
+	// logPrefix adalah prefix key log: logs:{timestamp}:{level}:{id}
+	logPrefix = "logs"
+
+	// logIndexPrefix adalah prefix key indeks log. Indeks memakai akhiran key yang sama
+	// dengan log sehingga urutannya identik dan log dapat dibaca langsung dari key indeks:
+	//   logidx:level:{level}:{timestamp}:{level}:{id}
+	//   logidx:source:{source}:{timestamp}:{level}:{id}
+	//   logidx:level-source:{level}:{source}:{timestamp}:{level}:{id}
+	logIndexPrefix = "logidx"
+
+	// logTimeFormat adalah format timestamp pada key, lebarnya tetap agar urutan key sama dengan urutan waktu
+	logTimeFormat = "20060102150405.000000"
+)
+
+// pruneBatchSize adalah jumlah log yang dihapus dalam satu batch saat pruning
+const pruneBatchSize = 500
+
+// ErrInvalidCursor dikembalikan ketika cursor paginasi log tidak dapat dibaca
+var ErrInvalidCursor = errors.New("cursor log tidak valid")
+
 // LogRepository menangani operasi penyimpanan log
 type LogRepository struct {
 	storage storage.Storage
 	helper  *storage.Helper
 	logger  utils.LogrusEntry
+
+	// Counter log untuk ringkasan, dimuat saat pertama kali dibutuhkan
+	countersMu sync.Mutex
+	counters   *logCounters
 }
 
 // NewLogRepository membuat repository log baru
 func NewLogRepository(store storage.Storage, logger utils.LogrusEntry) *LogRepository {
-	helper := storage.NewHelper(store, "logs")
+	helper := storage.NewHelper(store, logPrefix)
 	return &LogRepository{
 		storage: store,
 		helper:  helper,
@@ -30,7 +58,84 @@ func NewLogRepository(store storage.Storage, logger utils.LogrusEntry) *LogRepos
 	}
 }
 
-// SaveLog menyimpan log ke penyimpanan
+// logSuffix membuat akhiran key log dan indeksnya: {timestamp}:{level}:{id}
+func logSuffix(log *model.Log) string {
+	return fmt.Sprintf("%s:%s:%s", log.Timestamp.UTC().Format(logTimeFormat), strings.ToLower(log.Level), log.ID)
+}
+
+// logIndexKeys membuat key indeks level, source, dan gabungan keduanya untuk log
+func logIndexKeys(log *model.Log, suffix string) []string {
+	return []string{
+		rangePrefix(log.Level, "") + suffix,
+		rangePrefix("", log.Source) + suffix,
+		rangePrefix(log.Level, log.Source) + suffix,
+	}
+}
+
+// rangePrefix memilih prefix key yang ditelusuri untuk filter level dan source:
+// indeks gabungan, indeks salah satunya, atau seluruh log jika tanpa filter.
+func rangePrefix(level, source string) string {
+	level = strings.ToLower(level)
+	source = strings.ToLower(source)
+
+	switch {
+	case level != "" && source != "":
+		return fmt.Sprintf("%s:level-source:%s:%s:", logIndexPrefix, level, source)
+	case level != "":
+		return fmt.Sprintf("%s:level:%s:", logIndexPrefix, level)
+	case source != "":
+		return fmt.Sprintf("%s:source:%s:", logIndexPrefix, source)
+	default:
+		return logPrefix + ":"
+	}
+}
+
+// encodeCursor membuat cursor opaque dari akhiran key log
+func encodeCursor(suffix string) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(suffix))
+}
+
+// LogCursor mengembalikan cursor untuk posisi log, sama dengan cursor pada LogPage
+func LogCursor(log *model.Log) string {
+	return encodeCursor(logSuffix(log))
+}
+
+// decodeCursor membaca akhiran key log dari cursor. Cursor kosong menghasilkan string kosong.
+func decodeCursor(cursor string) (string, error) {
+	if cursor == "" {
+		return "", nil
+	}
+
+	data, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return "", ErrInvalidCursor
+	}
+
+	suffix := string(data)
+	if parts := strings.SplitN(suffix, ":", 3); len(parts) != 3 || len(parts[0]) != len(logTimeFormat) {
+		return "", ErrInvalidCursor
+	}
+	return suffix, nil
+}
+
+// timeBounds mengubah rentang waktu query menjadi batas timestamp key, kosong berarti tanpa batas
+func timeBounds(q model.LogQuery) (lower, upper string) {
+	if !q.From.IsZero() {
+		lower = q.From.UTC().Format(logTimeFormat)
+	}
+	if !q.To.IsZero() {
+		upper = q.To.UTC().Format(logTimeFormat)
+	}
+	return lower, upper
+}
+
+// matchesSearch memeriksa apakah pesan log mengandung teks yang dicari
+func matchesSearch(log *model.Log, search string) bool {
+	return search == "" || strings.Contains(strings.ToLower(log.Message), strings.ToLower(search))
+}
+
+// SaveLog menyimpan log beserta indeks level dan source-nya, serta counter ringkasan,
+// dalam satu transaksi
 func (r *LogRepository) SaveLog(log *model.Log) error {
 	ctx := context.Background()
 
@@ -39,112 +144,508 @@ func (r *LogRepository) SaveLog(log *model.Log) error {
 		log.ID = uuid.New().String()
 	}
 
-	// Simpan log dengan key berdasarkan waktu untuk urutan kronologis
-	// Format: logs:{timestamp}:{level}:{id}
-	timeKey := log.Timestamp.Format("20060102150405.000000")
-	key := fmt.Sprintf("%s:%s:%s", timeKey, strings.ToLower(log.Level), log.ID)
-
-	// Simpan menggunakan helper JSON
-	err := r.helper.SetJSON(ctx, key, log)
+	data, err := json.Marshal(log)
 	if err != nil {
+		return fmt.Errorf("gagal marshal log: %w", err)
+	}
+
+	// Key berdasarkan waktu untuk urutan kronologis, indeks hanya berisi key tanpa value.
+	// Ukuran dihitung seperti readLogEntry agar sama saat log dihapus.
+	suffix := logSuffix(log)
+	entries := map[string][]byte{logPrefix + ":" + suffix: data}
+	for _, key := range logIndexKeys(log, suffix) {
+		entries[key] = []byte{}
+	}
+	size := int64(len(data))
+	for key := range entries {
+		size += int64(len(key))
+	}
+
+	r.countersMu.Lock()
+	defer r.countersMu.Unlock()
+
+	if err := r.loadCounters(ctx); err != nil {
+		return err
+	}
+	r.counters.add(log.Level, log.Source, 1, entries)
+	r.counters.addHour(log.Timestamp, log.Level, 1, entries)
+	r.counters.addBytes(size, entries)
+
+	if err := r.storage.SetBatch(ctx, entries); err != nil {
+		// Kembalikan counter di memori karena log tidak tersimpan
+		r.counters.add(log.Level, log.Source, -1, map[string][]byte{})
+		r.counters.addHour(log.Timestamp, log.Level, -1, map[string][]byte{})
+		r.counters.addBytes(-size, map[string][]byte{})
 		return fmt.Errorf("gagal menyimpan log: %w", err)
 	}
 
 	return nil
 }
 
-// GetLogsByFilter mendapatkan log berdasarkan filter
-func (r *LogRepository) GetLogsByFilter(page, limit int, level, source, search, from, to string) ([]*model.Log, int, error) {
+// QueryLogs membaca satu halaman log dari yang terbaru. Hanya rentang key indeks
+// yang cocok dengan filter level dan source, serta batas waktu, yang ditelusuri.
+func (r *LogRepository) QueryLogs(q model.LogQuery) (*model.LogPage, error) {
 	ctx := context.Background()
 
-	// Get all logs with prefix
-	data, err := r.helper.GetAllWithPrefix(ctx, "")
+	before, err := decodeCursor(q.Before)
 	if err != nil {
-		return nil, 0, fmt.Errorf("gagal mendapatkan logs: %w", err)
+		return nil, err
+	}
+	after, err := decodeCursor(q.After)
+	if err != nil {
+		return nil, err
+	}
+	if before != "" && after != "" {
+		return nil, fmt.Errorf("%w: before dan after tidak dapat dipakai bersamaan", ErrInvalidCursor)
 	}
 
-	// Parse semua log dan terapkan filter
-	var allLogs []*model.Log
-	for _, v := range data {
-		var log model.Log
-		if err := json.Unmarshal(v, &log); err != nil {
-			r.logger.WithError(err).Warn("Gagal parse log entry")
-			continue
+	prefix := rangePrefix(q.Level, q.Source)
+	lower, upper := timeBounds(q)
+
+	// Halaman sebelumnya (after) ditelusuri maju dari cursor lalu dibalik,
+	// selain itu ditelusuri mundur dari cursor atau dari batas atas waktu
+	reverse := after == ""
+	opts := storage.IterateOptions{
+		Prefix:   prefix,
+		Reverse:  reverse,
+		KeysOnly: prefix != logPrefix+":",
+	}
+	if reverse {
+		switch {
+		case before != "" && (upper == "" || before < upper):
+			opts.Start = prefix + before
+		case upper != "":
+			opts.Start = prefix + upper
 		}
+	} else {
+		opts.Start = prefix + after
+		if lower > after {
+			opts.Start = prefix + lower
+		}
+	}
 
-		// Filter berdasarkan level
-		if level != "" && !strings.EqualFold(log.Level, level) {
-			continue
+	var logs []*model.Log
+	var suffixes []string
+	skip := q.Offset
+	more := false
+
+	err = r.storage.Iterate(ctx, opts, func(key string, value []byte) (bool, error) {
+		suffix := key[len(prefix):]
+		if suffix == before || suffix == after {
+			return true, nil
 		}
 
-		// Filter berdasarkan source
-		if source != "" && !strings.EqualFold(log.Source, source) {
-			continue
+		// Di luar rentang waktu: lewati jika belum masuk rentang, berhenti jika sudah melewatinya
+		ts := suffix[:min(len(suffix), len(logTimeFormat))]
+		if upper != "" && ts >= upper {
+			return reverse, nil
+		}
+		if lower != "" && ts < lower {
+			return !reverse, nil
 		}
 
-		// Filter berdasarkan teks yang dicari di pesan
-		if search != "" && !strings.Contains(strings.ToLower(log.Message), strings.ToLower(search)) {
-			continue
+		log, err := r.loadLog(ctx, suffix, value)
+		if err != nil {
+			r.logger.WithError(err).WithField("key", key).Warn("Gagal parse log entry")
+			return true, nil
+		}
+		if log == nil || !matchesSearch(log, q.Search) {
+			return true, nil
 		}
 
-		// Filter berdasarkan tanggal from
-		if from != "" {
-			fromDate, err := time.Parse("2006-01-02", from)
-			if err == nil && log.Timestamp.Before(fromDate) {
-				continue
-			}
+		if skip > 0 {
+			skip--
+			return true, nil
+		}
+		if len(logs) == q.Limit {
+			more = true
+			return false, nil
 		}
 
-		// Filter berdasarkan tanggal to
-		if to != "" {
-			toDate, err := time.Parse("2006-01-02", to)
-			if err == nil {
-				// Tambahkan 1 hari ke to date untuk mencakup seluruh hari
-				toDate = toDate.Add(24 * time.Hour)
-				if log.Timestamp.After(toDate) {
-					continue
-				}
-			}
+		logs = append(logs, log)
+		suffixes = append(suffixes, suffix)
+		return true, nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("gagal mendapatkan logs: %w", err)
+	}
+
+	if !reverse {
+		for i, j := 0, len(logs)-1; i < j; i, j = i+1, j-1 {
+			logs[i], logs[j] = logs[j], logs[i]
+			suffixes[i], suffixes[j] = suffixes[j], suffixes[i]
 		}
+	}
 
-		// Log sesuai filter, tambahkan ke hasil
-		allLogs = append(allLogs, &log)
+	page := &model.LogPage{Logs: logs}
+	if page.Logs == nil {
+		page.Logs = []*model.Log{}
+	}
+	if len(logs) == 0 {
+		return page, nil
+	}
+
+	// Log yang lebih lama masih ada jika penelusuran mundur terpotong limit, atau
+	// selalu ada jika halaman ini diambil maju dari cursor after
+	if !reverse || more {
+		page.NextCursor = encodeCursor(suffixes[len(suffixes)-1])
+	}
+	if (!reverse && more) || (reverse && (before != "" || q.Offset > 0)) {
+		page.PrevCursor = encodeCursor(suffixes[0])
 	}
 
-	// Hitung total logs yang sesuai filter
-	totalLogs := len(allLogs)
+	return page, nil
+}
 
-	// Terapkan paginasi
-	startIdx := (page - 1) * limit
-	if startIdx >= totalLogs {
-		return []*model.Log{}, totalLogs, nil
+// loadLog membaca log dari value key log, atau dari key log jika value berasal dari indeks.
+// Mengembalikan nil jika log untuk indeks sudah tidak ada.
+func (r *LogRepository) loadLog(ctx context.Context, suffix string, value []byte) (*model.Log, error) {
+	if value == nil {
+		data, err := r.storage.Get(ctx, logPrefix+":"+suffix)
+		if storage.IsNotFound(err) {
+			return nil, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		value = data
 	}
 
-	endIdx := startIdx + limit
-	if endIdx > totalLogs {
-		endIdx = totalLogs
+	var log model.Log
+	if err := json.Unmarshal(value, &log); err != nil {
+		return nil, err
 	}
+	return &log, nil
+}
 
-	// Sort logs by timestamp (descending - newest first)
-	utils.SortLogs(allLogs)
+// CountLogs menghitung log yang cocok dengan filter tanpa memperhatikan cursor dan offset.
+// Filter level atau source saja dijawab dari counter ringkasan. Selain itu, tanpa pencarian
+// teks hanya key pada rentang indeks yang ditelusuri.
+func (r *LogRepository) CountLogs(q model.LogQuery) (int, error) {
+	ctx := context.Background()
 
-	return allLogs[startIdx:endIdx], totalLogs, nil
+	if q.Search == "" && q.From.IsZero() && q.To.IsZero() && (q.Level == "" || q.Source == "") {
+		r.countersMu.Lock()
+		defer r.countersMu.Unlock()
+
+		if err := r.loadCounters(ctx); err != nil {
+			return 0, err
+		}
+		switch {
+		case q.Level != "":
+			return r.counters.byLevel[strings.ToUpper(q.Level)], nil
+		case q.Source != "":
+			return r.counters.bySource[strings.ToUpper(q.Source)], nil
+		}
+		total := 0
+		for _, n := range r.counters.byLevel {
+			total += n
+		}
+		return total, nil
+	}
+
+	prefix := rangePrefix(q.Level, q.Source)
+	lower, upper := timeBounds(q)
+
+	opts := storage.IterateOptions{
+		Prefix:   prefix,
+		Reverse:  true,
+		KeysOnly: q.Search == "" || prefix != logPrefix+":",
+	}
+	if upper != "" {
+		opts.Start = prefix + upper
+	}
+
+	count := 0
+	err := r.storage.Iterate(ctx, opts, func(key string, value []byte) (bool, error) {
+		suffix := key[len(prefix):]
+		ts := suffix[:min(len(suffix), len(logTimeFormat))]
+		if upper != "" && ts >= upper {
+			return true, nil
+		}
+		if lower != "" && ts < lower {
+			return false, nil
+		}
+
+		if q.Search != "" {
+			log, err := r.loadLog(ctx, suffix, value)
+			if err != nil || log == nil || !matchesSearch(log, q.Search) {
+				return true, nil
+			}
+		}
+
+		count++
+		return true, nil
+	})
+	if err != nil {
+		return 0, fmt.Errorf("gagal menghitung logs: %w", err)
+	}
+
+	return count, nil
 }
 
-// ClearAllLogs menghapus seluruh data log
+// ClearAllLogs menghapus seluruh data log beserta indeks dan counternya
 func (r *LogRepository) ClearAllLogs() error {
 	ctx := context.Background()
-	return r.helper.DeleteAllWithPrefix(ctx, "")
+
+	r.countersMu.Lock()
+	defer r.countersMu.Unlock()
+
+	if err := r.helper.DeleteAllWithPrefix(ctx, ""); err != nil {
+		return err
+	}
+	if err := r.storage.DeleteWithPrefix(ctx, logIndexPrefix+":"); err != nil {
+		return err
+	}
+
+	// Counter dibangun ulang dari log yang tersisa saat dibutuhkan lagi
+	r.counters = nil
+	return r.storage.DeleteWithPrefix(ctx, logStatPrefix+":")
 }
 
 // GetTotalLogCount mengembalikan jumlah total log dalam sistem
 func (r *LogRepository) GetTotalLogCount() (int, error) {
+	return r.CountLogs(model.LogQuery{})
+}
+
+// logEntry adalah metadata satu log yang dibaca saat pruning
+type logEntry struct {
+	keys   []string
+	level  string
+	source string
+	at     time.Time
+	size   int64
+}
+
+// readLogEntry membaca metadata log dari key dan value log
+func readLogEntry(key string, value []byte) (*logEntry, error) {
+	var meta struct {
+		Level  string `json:"level"`
+		Source string `json:"source"`
+	}
+	if err := json.Unmarshal(value, &meta); err != nil {
+		return nil, err
+	}
+
+	suffix := strings.TrimPrefix(key, logPrefix+":")
+	at, err := time.Parse(logTimeFormat, suffix[:min(len(suffix), len(logTimeFormat))])
+	if err != nil {
+		return nil, err
+	}
+
+	entry := &logEntry{
+		keys:   append([]string{key}, logIndexKeys(&model.Log{Level: meta.Level, Source: meta.Source}, suffix)...),
+		level:  strings.ToUpper(meta.Level),
+		source: strings.ToUpper(meta.Source),
+		at:     at,
+		size:   int64(len(value)),
+	}
+	for _, k := range entry.keys {
+		entry.size += int64(len(k))
+	}
+	return entry, nil
+}
+
+// Stats membaca statistik log yang tersimpan dari counter. Hanya log terlama dan terbaru
+// yang dibaca dari storage, tanpa menelusuri seluruh log.
+func (r *LogRepository) Stats() (*model.LogRetentionStats, error) {
 	ctx := context.Background()
+	stats := &model.LogRetentionStats{ByLevel: make(map[string]int), UpdatedAt: time.Now()}
+
+	r.countersMu.Lock()
+	if err := r.loadCounters(ctx); err != nil {
+		r.countersMu.Unlock()
+		return nil, err
+	}
+	for level, n := range r.counters.byLevel {
+		stats.ByLevel[level] = n
+		stats.TotalEntries += n
+	}
+	stats.TotalBytes = r.counters.bytes
+	r.countersMu.Unlock()
+
+	var err error
+	if stats.OldestAt, err = r.edgeLogTime(ctx, false); err != nil {
+		return nil, err
+	}
+	if stats.NewestAt, err = r.edgeLogTime(ctx, true); err != nil {
+		return nil, err
+	}
+	return stats, nil
+}
+
+// edgeLogTime mengembalikan waktu log terlama, atau terbaru jika reverse, dari key log pertama
+func (r *LogRepository) edgeLogTime(ctx context.Context, reverse bool) (*time.Time, error) {
+	var at *time.Time
+	opts := storage.IterateOptions{Prefix: logPrefix + ":", Reverse: reverse, KeysOnly: true}
+	err := r.storage.Iterate(ctx, opts, func(key string, _ []byte) (bool, error) {
+		suffix := strings.TrimPrefix(key, logPrefix+":")
+		t, err := time.Parse(logTimeFormat, suffix[:min(len(suffix), len(logTimeFormat))])
+		if err != nil {
+			return true, nil
+		}
+		at = &t
+		return false, nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("gagal membaca logs: %w", err)
+	}
+	return at, nil
+}
+
+// Prune menghapus log yang melewati batas usia levelnya, lalu log terlama sampai jumlah
+// dan ukuran log berada dalam batas. Hanya log yang dihapus yang ditelusuri: indeks level
+// dibaca dari log terlama sampai batas usia, dan jumlah serta ukuran log diambil dari counter.
+// Log dihapus per batch beserta indeksnya. Mengembalikan statistik log yang tersisa.
+func (r *LogRepository) Prune(policy model.LogRetentionPolicy, now time.Time) (*model.LogRetentionStats, error) {
+	ctx := context.Background()
+	pruned := 0
+
+	var batch []*logEntry
+	flush := func() error {
+		if len(batch) == 0 {
+			return nil
+		}
+		if err := r.deleteEntries(ctx, batch); err != nil {
+			return err
+		}
+		batch = batch[:0]
+		return nil
+	}
+	remove := func(entry *logEntry) error {
+		batch = append(batch, entry)
+		pruned++
+		if len(batch) >= pruneBatchSize {
+			return flush()
+		}
+		return nil
+	}
+
+	if err := r.trimHourlyCounters(ctx, now); err != nil {
+		return nil, err
+	}
+
+	// Tahap 1: untuk setiap level, telusuri indeks level dari log terlama dan berhenti
+	// pada log pertama yang masih dalam batas usia
+	var orphans []string
+	for level, maxAge := range policy.MaxAge {
+		if maxAge <= 0 {
+			continue
+		}
+		cutoff := now.Add(-maxAge).UTC().Format(logTimeFormat)
+		prefix := rangePrefix(level, "")
+
+		opts := storage.IterateOptions{Prefix: prefix, KeysOnly: true}
+		err := r.storage.Iterate(ctx, opts, func(key string, _ []byte) (bool, error) {
+			suffix := key[len(prefix):]
+			if suffix[:min(len(suffix), len(logTimeFormat))] >= cutoff {
+				return false, nil
+			}
+
+			data, err := r.storage.Get(ctx, logPrefix+":"+suffix)
+			if storage.IsNotFound(err) {
+				// Indeks tertinggal tanpa log, hapus agar tidak dibaca lagi
+				orphans = append(orphans, key)
+				return true, nil
+			}
+			if err != nil {
+				return false, err
+			}
+
+			entry, err := readLogEntry(logPrefix+":"+suffix, data)
+			if err != nil {
+				r.logger.WithError(err).WithField("key", key).Warn("Gagal parse log entry")
+				return true, nil
+			}
+			return true, remove(entry)
+		})
+		if err != nil {
+			return nil, fmt.Errorf("gagal membaca logs: %w", err)
+		}
+		if err := flush(); err != nil {
+			return nil, err
+		}
+	}
+	if len(orphans) > 0 {
+		if err := r.storage.DeleteBatch(ctx, orphans); err != nil {
+			return nil, fmt.Errorf("gagal menghapus indeks log: %w", err)
+		}
+	}
 
-	data, err := r.helper.GetAllWithPrefix(ctx, "")
+	// Tahap 2: hapus log terlama selama jumlah atau ukuran log dari counter melewati batas
+	r.countersMu.Lock()
+	if err := r.loadCounters(ctx); err != nil {
+		r.countersMu.Unlock()
+		return nil, err
+	}
+	var entries int
+	for _, n := range r.counters.byLevel {
+		entries += n
+	}
+	bytes := r.counters.bytes
+	r.countersMu.Unlock()
+
+	overLimit := func() bool {
+		return (policy.MaxEntries > 0 && entries > policy.MaxEntries) ||
+			(policy.MaxBytes > 0 && bytes > policy.MaxBytes)
+	}
+	if overLimit() {
+		opts := storage.IterateOptions{Prefix: logPrefix + ":"}
+		err := r.storage.Iterate(ctx, opts, func(key string, value []byte) (bool, error) {
+			if !overLimit() {
+				return false, nil
+			}
+
+			entry, err := readLogEntry(key, value)
+			if err != nil {
+				return true, nil
+			}
+			entries--
+			bytes -= entry.size
+			return true, remove(entry)
+		})
+		if err != nil {
+			return nil, fmt.Errorf("gagal membaca logs: %w", err)
+		}
+		if err := flush(); err != nil {
+			return nil, err
+		}
+	}
+
+	stats, err := r.Stats()
 	if err != nil {
-		return 0, fmt.Errorf("gagal mendapatkan jumlah log: %w", err)
+		return nil, err
+	}
+	stats.Pruned = pruned
+	stats.UpdatedAt = now
+	return stats, nil
+}
+
+// deleteEntries menghapus log beserta indeksnya dan mengurangi counter level, source, dan ukuran.
+// Counter per jam tidak dikurangi karena mencatat log yang masuk.
+func (r *LogRepository) deleteEntries(ctx context.Context, batch []*logEntry) error {
+	r.countersMu.Lock()
+	defer r.countersMu.Unlock()
+
+	if err := r.loadCounters(ctx); err != nil {
+		return err
+	}
+
+	var keys []string
+	for _, entry := range batch {
+		keys = append(keys, entry.keys...)
+	}
+	if err := r.storage.DeleteBatch(ctx, keys); err != nil {
+		return fmt.Errorf("gagal menghapus log lama: %w", err)
 	}
 
-	return len(data), nil
+	counts := make(map[string][]byte)
+	for _, entry := range batch {
+		r.counters.add(entry.level, entry.source, -1, counts)
+		r.counters.addBytes(-entry.size, counts)
+	}
+	if err := r.storage.SetBatch(ctx, counts); err != nil {
+		return fmt.Errorf("gagal memperbarui counter log: %w", err)
+	}
+	return nil
 }
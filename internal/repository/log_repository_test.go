@@ -0,0 +1,475 @@
+package repository
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/gwenziro/bot-notify/internal/api/model"
+	"github.com/gwenziro/bot-notify/internal/storage"
+	"github.com/gwenziro/bot-notify/internal/utils"
+)
+
+// testNow adalah waktu acuan log pada test
+var testNow = time.Date(2026, 1, 10, 12, 0, 0, 0, time.UTC)
+
+// newTestRepository membuat LogRepository di atas Badger in-memory
+func newTestRepository(t *testing.T) *LogRepository {
+	t.Helper()
+	store, err := storage.NewBadgerStorage(storage.StorageOptions{InMemory: true})
+	if err != nil {
+		t.Fatalf("gagal membuat storage: %v", err)
+	}
+	t.Cleanup(func() { store.Close() })
+	return NewLogRepository(store, utils.ForModule("test"))
+}
+
+// saveTestLog menyimpan log dengan waktu, level, dan source tertentu
+func saveTestLog(t *testing.T, r *LogRepository, at time.Time, level, source, message string) *model.Log {
+	t.Helper()
+	log := &model.Log{
+		ID:        fmt.Sprintf("%s-%d", message, at.UnixNano()),
+		Timestamp: at,
+		Level:     level,
+		Source:    source,
+		Message:   message,
+	}
+	if err := r.SaveLog(log); err != nil {
+		t.Fatalf("gagal menyimpan log: %v", err)
+	}
+	return log
+}
+
+// messages mengembalikan pesan log secara berurutan
+func messages(logs []*model.Log) []string {
+	out := make([]string, len(logs))
+	for i, log := range logs {
+		out[i] = log.Message
+	}
+	return out
+}
+
+// allMessages membaca seluruh log dari yang terbaru, opsional dengan filter level
+func allMessages(t *testing.T, r *LogRepository, level string) []string {
+	t.Helper()
+	page, err := r.QueryLogs(model.LogQuery{Level: level, Limit: 1000})
+	if err != nil {
+		t.Fatalf("gagal membaca log: %v", err)
+	}
+	return messages(page.Logs)
+}
+
+func TestPrune(t *testing.T) {
+	seed := func(t *testing.T) *LogRepository {
+		r := newTestRepository(t)
+		saveTestLog(t, r, testNow.Add(-300*time.Hour), "ERROR", "SYSTEM", "error-lama")
+		saveTestLog(t, r, testNow.Add(-200*time.Hour), "INFO", "API", "info-lama")
+		saveTestLog(t, r, testNow.Add(-48*time.Hour), "DEBUG", "WEB", "debug-lama")
+		saveTestLog(t, r, testNow.Add(-2*time.Hour), "INFO", "API", "info-baru")
+		saveTestLog(t, r, testNow.Add(-time.Hour), "DEBUG", "WEB", "debug-baru")
+		return r
+	}
+	maxAge := map[string]time.Duration{"DEBUG": 24 * time.Hour, "INFO": 168 * time.Hour}
+
+	tests := []struct {
+		name       string
+		policy     func(r *LogRepository) model.LogRetentionPolicy
+		wantPruned int
+		wantLogs   []string
+		wantLevels map[string]int
+	}{
+		{
+			name:       "tanpa batas",
+			policy:     func(*LogRepository) model.LogRetentionPolicy { return model.LogRetentionPolicy{} },
+			wantPruned: 0,
+			wantLogs:   []string{"debug-baru", "info-baru", "debug-lama", "info-lama", "error-lama"},
+			wantLevels: map[string]int{"DEBUG": 2, "INFO": 2, "ERROR": 1},
+		},
+		{
+			name:       "batas usia per level",
+			policy:     func(*LogRepository) model.LogRetentionPolicy { return model.LogRetentionPolicy{MaxAge: maxAge} },
+			wantPruned: 2,
+			wantLogs:   []string{"debug-baru", "info-baru", "error-lama"},
+			wantLevels: map[string]int{"DEBUG": 1, "INFO": 1, "ERROR": 1},
+		},
+		{
+			name: "batas usia lalu jumlah",
+			policy: func(*LogRepository) model.LogRetentionPolicy {
+				return model.LogRetentionPolicy{MaxAge: maxAge, MaxEntries: 2}
+			},
+			wantPruned: 3,
+			wantLogs:   []string{"debug-baru", "info-baru"},
+			wantLevels: map[string]int{"DEBUG": 1, "INFO": 1},
+		},
+		{
+			name: "batas ukuran",
+			policy: func(r *LogRepository) model.LogRetentionPolicy {
+				stats, err := r.Stats()
+				if err != nil {
+					t.Fatalf("gagal membaca statistik: %v", err)
+				}
+				return model.LogRetentionPolicy{MaxBytes: stats.TotalBytes - 1}
+			},
+			wantPruned: 1,
+			wantLogs:   []string{"debug-baru", "info-baru", "debug-lama", "info-lama"},
+			wantLevels: map[string]int{"DEBUG": 2, "INFO": 2},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := seed(t)
+			stats, err := r.Prune(tt.policy(r), testNow)
+			if err != nil {
+				t.Fatalf("prune gagal: %v", err)
+			}
+
+			if stats.Pruned != tt.wantPruned {
+				t.Errorf("pruned = %d, ingin %d", stats.Pruned, tt.wantPruned)
+			}
+			if got := allMessages(t, r, ""); fmt.Sprint(got) != fmt.Sprint(tt.wantLogs) {
+				t.Errorf("log tersisa = %v, ingin %v", got, tt.wantLogs)
+			}
+			if stats.TotalEntries != len(tt.wantLogs) {
+				t.Errorf("total = %d, ingin %d", stats.TotalEntries, len(tt.wantLogs))
+			}
+			if fmt.Sprint(stats.ByLevel) != fmt.Sprint(tt.wantLevels) {
+				t.Errorf("per level = %v, ingin %v", stats.ByLevel, tt.wantLevels)
+			}
+
+			// Indeks level ikut terhapus bersama log
+			for level, n := range tt.wantLevels {
+				if got := allMessages(t, r, level); len(got) != n {
+					t.Errorf("indeks %s berisi %v, ingin %d log", level, got, n)
+				}
+			}
+		})
+	}
+}
+
+func TestPruneCountersMatchStoredLogs(t *testing.T) {
+	r := newTestRepository(t)
+	for i := 0; i < 50; i++ {
+		level := []string{"DEBUG", "INFO", "WARNING", "ERROR"}[i%4]
+		saveTestLog(t, r, testNow.Add(-time.Duration(i)*time.Hour), level, "SYSTEM", fmt.Sprintf("log-%02d", i))
+	}
+
+	policy := model.LogRetentionPolicy{
+		MaxAge:     map[string]time.Duration{"DEBUG": 12 * time.Hour},
+		MaxEntries: 30,
+	}
+	stats, err := r.Prune(policy, testNow)
+	if err != nil {
+		t.Fatalf("prune gagal: %v", err)
+	}
+
+	// Counter yang dikurangi saat pruning harus sama dengan counter yang dibangun ulang
+	r.counters = nil
+	if err := r.storage.Delete(t.Context(), logStatPrefix+":version"); err != nil {
+		t.Fatalf("gagal menghapus versi counter: %v", err)
+	}
+	rebuilt, err := r.Stats()
+	if err != nil {
+		t.Fatalf("gagal membaca statistik: %v", err)
+	}
+
+	if stats.TotalEntries != 30 || rebuilt.TotalEntries != 30 {
+		t.Errorf("total = %d, dibangun ulang %d, ingin 30", stats.TotalEntries, rebuilt.TotalEntries)
+	}
+	if stats.TotalBytes != rebuilt.TotalBytes {
+		t.Errorf("ukuran = %d, dibangun ulang %d", stats.TotalBytes, rebuilt.TotalBytes)
+	}
+	if fmt.Sprint(stats.ByLevel) != fmt.Sprint(rebuilt.ByLevel) {
+		t.Errorf("per level = %v, dibangun ulang %v", stats.ByLevel, rebuilt.ByLevel)
+	}
+}
+
+// seedQueryLogs menyimpan 10 log berjarak satu menit, log-00 adalah yang terbaru.
+// Level bergantian INFO dan ERROR, source API untuk setiap log ketiga dan WEB selainnya.
+func seedQueryLogs(t *testing.T) (*LogRepository, []*model.Log) {
+	t.Helper()
+	r := newTestRepository(t)
+	logs := make([]*model.Log, 10)
+	for i := range logs {
+		level, source := "INFO", "WEB"
+		if i%2 == 1 {
+			level = "ERROR"
+		}
+		if i%3 == 0 {
+			source = "API"
+		}
+		logs[i] = saveTestLog(t, r, testNow.Add(-time.Duration(i)*time.Minute), level, source, fmt.Sprintf("log-%02d", i))
+	}
+	return r, logs
+}
+
+func TestQueryLogs(t *testing.T) {
+	r, logs := seedQueryLogs(t)
+	cursor := func(i int) string { return LogCursor(logs[i]) }
+
+	tests := []struct {
+		name     string
+		query    model.LogQuery
+		want     []string
+		wantNext string
+		wantPrev string
+	}{
+		{
+			name:     "halaman pertama",
+			query:    model.LogQuery{Limit: 3},
+			want:     []string{"log-00", "log-01", "log-02"},
+			wantNext: cursor(2),
+		},
+		{
+			name:     "halaman berikutnya dari before",
+			query:    model.LogQuery{Before: cursor(2), Limit: 3},
+			want:     []string{"log-03", "log-04", "log-05"},
+			wantNext: cursor(5),
+			wantPrev: cursor(3),
+		},
+		{
+			name:     "halaman terakhir",
+			query:    model.LogQuery{Before: cursor(8), Limit: 3},
+			want:     []string{"log-09"},
+			wantPrev: cursor(9),
+		},
+		{
+			name:  "before pada log terlama",
+			query: model.LogQuery{Before: cursor(9), Limit: 3},
+			want:  []string{},
+		},
+		{
+			name:     "halaman sebelumnya dari after",
+			query:    model.LogQuery{After: cursor(6), Limit: 3},
+			want:     []string{"log-03", "log-04", "log-05"},
+			wantNext: cursor(5),
+			wantPrev: cursor(3),
+		},
+		{
+			name:     "after sampai log terbaru",
+			query:    model.LogQuery{After: cursor(3), Limit: 3},
+			want:     []string{"log-00", "log-01", "log-02"},
+			wantNext: cursor(2),
+		},
+		{
+			name:     "after kurang dari satu halaman",
+			query:    model.LogQuery{After: cursor(2), Limit: 3},
+			want:     []string{"log-00", "log-01"},
+			wantNext: cursor(1),
+		},
+		{
+			name:  "after pada log terbaru",
+			query: model.LogQuery{After: cursor(0), Limit: 3},
+			want:  []string{},
+		},
+		{
+			name:     "offset",
+			query:    model.LogQuery{Offset: 2, Limit: 3},
+			want:     []string{"log-02", "log-03", "log-04"},
+			wantNext: cursor(4),
+			wantPrev: cursor(2),
+		},
+		{
+			name:  "rentang waktu dengan batas atas eksklusif",
+			query: model.LogQuery{From: testNow.Add(-6 * time.Minute), To: testNow.Add(-2 * time.Minute), Limit: 10},
+			want:  []string{"log-03", "log-04", "log-05", "log-06"},
+		},
+		{
+			name:     "rentang waktu dengan before",
+			query:    model.LogQuery{From: testNow.Add(-6 * time.Minute), Before: cursor(4), Limit: 1},
+			want:     []string{"log-05"},
+			wantNext: cursor(5),
+			wantPrev: cursor(5),
+		},
+		{
+			name:  "rentang waktu dengan after",
+			query: model.LogQuery{To: testNow.Add(-2 * time.Minute), After: cursor(5), Limit: 10},
+			want:  []string{"log-03", "log-04"},
+			// Halaman dari after selalu memiliki log yang lebih lama
+			wantNext: cursor(4),
+		},
+		{
+			name:  "filter level",
+			query: model.LogQuery{Level: "error", Limit: 10},
+			want:  []string{"log-01", "log-03", "log-05", "log-07", "log-09"},
+		},
+		{
+			name:  "filter source",
+			query: model.LogQuery{Source: "API", Limit: 10},
+			want:  []string{"log-00", "log-03", "log-06", "log-09"},
+		},
+		{
+			name:  "filter level dan source",
+			query: model.LogQuery{Level: "ERROR", Source: "api", Limit: 10},
+			want:  []string{"log-03", "log-09"},
+		},
+		{
+			name:     "filter level dengan before",
+			query:    model.LogQuery{Level: "ERROR", Before: cursor(3), Limit: 2},
+			want:     []string{"log-05", "log-07"},
+			wantNext: cursor(7),
+			wantPrev: cursor(5),
+		},
+		{
+			name:     "filter level dengan after",
+			query:    model.LogQuery{Level: "ERROR", After: cursor(7), Limit: 2},
+			want:     []string{"log-03", "log-05"},
+			wantNext: cursor(5),
+			wantPrev: cursor(3),
+		},
+		{
+			name:  "filter level dan rentang waktu",
+			query: model.LogQuery{Level: "INFO", From: testNow.Add(-4 * time.Minute), To: testNow, Limit: 10},
+			want:  []string{"log-02", "log-04"},
+		},
+		{
+			name:     "pencarian",
+			query:    model.LogQuery{Search: "LOG-0", Source: "WEB", Limit: 2},
+			want:     []string{"log-01", "log-02"},
+			wantNext: cursor(2),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			page, err := r.QueryLogs(tt.query)
+			if err != nil {
+				t.Fatalf("query gagal: %v", err)
+			}
+			if got := messages(page.Logs); fmt.Sprint(got) != fmt.Sprint(tt.want) {
+				t.Errorf("log = %v, ingin %v", got, tt.want)
+			}
+			if page.NextCursor != tt.wantNext {
+				t.Errorf("next cursor = %q, ingin %q", page.NextCursor, tt.wantNext)
+			}
+			if page.PrevCursor != tt.wantPrev {
+				t.Errorf("prev cursor = %q, ingin %q", page.PrevCursor, tt.wantPrev)
+			}
+		})
+	}
+}
+
+func TestQueryLogsWalksAllPages(t *testing.T) {
+	r, _ := seedQueryLogs(t)
+
+	// Maju dengan NextCursor sampai habis, lalu kembali dengan PrevCursor
+	var forward []string
+	var prevs []string
+	q := model.LogQuery{Limit: 4}
+	for {
+		page, err := r.QueryLogs(q)
+		if err != nil {
+			t.Fatalf("query gagal: %v", err)
+		}
+		forward = append(forward, messages(page.Logs)...)
+		prevs = append(prevs, page.PrevCursor)
+		if page.NextCursor == "" {
+			break
+		}
+		q = model.LogQuery{Before: page.NextCursor, Limit: 4}
+	}
+	if want := allMessages(t, r, ""); fmt.Sprint(forward) != fmt.Sprint(want) {
+		t.Fatalf("log maju = %v, ingin %v", forward, want)
+	}
+
+	var backward []string
+	after := prevs[len(prevs)-1]
+	for after != "" {
+		page, err := r.QueryLogs(model.LogQuery{After: after, Limit: 4})
+		if err != nil {
+			t.Fatalf("query gagal: %v", err)
+		}
+		backward = append(messages(page.Logs), backward...)
+		after = page.PrevCursor
+	}
+	// PrevCursor halaman terakhir menunjuk log-08, sehingga log-08 dan log-09 tidak terbaca ulang
+	if want := forward[:len(forward)-2]; fmt.Sprint(backward) != fmt.Sprint(want) {
+		t.Errorf("log mundur = %v, ingin %v", backward, want)
+	}
+}
+
+func TestQueryLogsDeletedCursorEntry(t *testing.T) {
+	r, logs := seedQueryLogs(t)
+
+	// Cursor tetap dapat dipakai setelah log yang ditunjuknya dihapus
+	suffix := logSuffix(logs[3])
+	keys := append([]string{logPrefix + ":" + suffix}, logIndexKeys(logs[3], suffix)...)
+	if err := r.storage.DeleteBatch(t.Context(), keys); err != nil {
+		t.Fatalf("gagal menghapus log: %v", err)
+	}
+	cursor := LogCursor(logs[3])
+
+	tests := []struct {
+		name  string
+		query model.LogQuery
+		want  []string
+	}{
+		{"before", model.LogQuery{Before: cursor, Limit: 3}, []string{"log-04", "log-05", "log-06"}},
+		{"after", model.LogQuery{After: cursor, Limit: 3}, []string{"log-00", "log-01", "log-02"}},
+		{"before dengan filter level", model.LogQuery{Level: "ERROR", Before: cursor, Limit: 3}, []string{"log-05", "log-07", "log-09"}},
+		{"after dengan filter source", model.LogQuery{Source: "API", After: cursor, Limit: 3}, []string{"log-00"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			page, err := r.QueryLogs(tt.query)
+			if err != nil {
+				t.Fatalf("query gagal: %v", err)
+			}
+			if got := messages(page.Logs); fmt.Sprint(got) != fmt.Sprint(tt.want) {
+				t.Errorf("log = %v, ingin %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestQueryLogsInvalidCursor(t *testing.T) {
+	r, logs := seedQueryLogs(t)
+
+	tests := []struct {
+		name  string
+		query model.LogQuery
+	}{
+		{"bukan base64", model.LogQuery{Before: "!!!", Limit: 3}},
+		{"format salah", model.LogQuery{After: encodeCursor("bukan-cursor"), Limit: 3}},
+		{"before dan after", model.LogQuery{Before: LogCursor(logs[5]), After: LogCursor(logs[1]), Limit: 3}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := r.QueryLogs(tt.query); !errors.Is(err, ErrInvalidCursor) {
+				t.Errorf("error = %v, ingin ErrInvalidCursor", err)
+			}
+		})
+	}
+}
+
+func TestCountLogs(t *testing.T) {
+	r, logs := seedQueryLogs(t)
+
+	tests := []struct {
+		name  string
+		query model.LogQuery
+		want  int
+	}{
+		{"semua dari counter", model.LogQuery{}, 10},
+		{"level dari counter", model.LogQuery{Level: "error"}, 5},
+		{"source dari counter", model.LogQuery{Source: "API"}, 4},
+		{"level dan source dari indeks", model.LogQuery{Level: "ERROR", Source: "API"}, 2},
+		{"rentang waktu", model.LogQuery{From: testNow.Add(-6 * time.Minute), To: testNow.Add(-2 * time.Minute)}, 4},
+		{"level dan rentang waktu", model.LogQuery{Level: "INFO", From: testNow.Add(-4 * time.Minute)}, 3},
+		{"pencarian", model.LogQuery{Search: "log-0"}, 10},
+		{"pencarian dengan filter", model.LogQuery{Search: "LOG-05", Level: "ERROR"}, 1},
+		{"cursor dan offset diabaikan", model.LogQuery{Level: "ERROR", Source: "API", Before: LogCursor(logs[0]), Offset: 1, Limit: 1}, 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := r.CountLogs(tt.query)
+			if err != nil {
+				t.Fatalf("count gagal: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("count = %d, ingin %d", got, tt.want)
+			}
+		})
+	}
+}
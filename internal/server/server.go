@@ -5,7 +5,6 @@ import (
 	"encoding/json"
 	"fmt"
 	"os"
-	"path/filepath"
 	"time"
 
 	"github.com/gofiber/fiber/v2"
@@ -17,6 +16,8 @@ import (
 	"github.com/gofiber/template/html/v2"
 	"github.com/gwenziro/bot-notify/internal/api"
 	"github.com/gwenziro/bot-notify/internal/config"
+	"github.com/gwenziro/bot-notify/internal/metrics"
+	"github.com/gwenziro/bot-notify/internal/sse"
 	"github.com/gwenziro/bot-notify/internal/utils"
 	"github.com/gwenziro/bot-notify/internal/web"
 )
@@ -28,7 +29,9 @@ type ServerOptions struct {
 
 	// Opsi untuk web rendering
 	EnableTemplateEngine bool
-	ViewsPath            string
+	// ViewsDir membaca template dari disk dengan reload otomatis. Kosongkan
+	// untuk memakai template yang di-embed ke binary.
+	ViewsDir string
 
 	// Handler yang akan didaftarkan
 	WebHandler *web.WebHandler
@@ -41,36 +44,29 @@ type Server struct {
 	SessionStore *session.Store
 }
 
+// minRequestTimeout adalah batas bawah timeout baca dan tulis request. Stream SSE tidak
+// dibatasi WriteTimeout karena batas waktu tulisnya diperpanjang setiap event.
+const minRequestTimeout = 60 * time.Second
+
 // NewServer membuat instance baru server dengan opsi yang diberikan
 func NewServer(opts ServerOptions) (*Server, error) {
 	// Siapkan konfigurasi dasar fiber dengan timeout yang lebih ketat
 	fiberConfig := fiber.Config{
-		ReadTimeout:           opts.Config.Server.ReadTimeout,
-		WriteTimeout:          opts.Config.Server.WriteTimeout,
+		ReadTimeout:           max(opts.Config.Server.ReadTimeout, minRequestTimeout),
+		WriteTimeout:          max(opts.Config.Server.WriteTimeout, minRequestTimeout),
 		IdleTimeout:           30 * time.Second, // Tambahkan idle timeout
 		DisableStartupMessage: false,            // Aktifkan pesan startup
+		BodyLimit:             bodyLimit(opts.Config),
 	}
 
 	// Setup template engine jika diaktifkan
-	if opts.EnableTemplateEngine && opts.ViewsPath != "" {
-		// Pastikan direktori views ada
-		if err := utils.EnsureDirectoryExists(opts.ViewsPath); err != nil {
-			return nil, err
-		}
-
-		// Pastikan direktori layouts ada
-		layoutDir := filepath.Join(opts.ViewsPath, "layouts")
-		if err := utils.EnsureDirectoryExists(layoutDir); err != nil {
-			return nil, err
-		}
-
+	if opts.EnableTemplateEngine {
 		// Setup template engine dengan debug info
 		utils.Info("Mengonfigurasi template engine", utils.Fields{
-			"views_path":  opts.ViewsPath,
-			"layouts_dir": layoutDir,
+			"views_dir": viewsSource(opts.ViewsDir),
 		})
 
-		engine := html.New(opts.ViewsPath, ".html")
+		engine := html.NewFileSystem(web.ViewsFS(opts.ViewsDir), ".html")
 
 		// Konfigurasi engine agar bekerja dengan layout
 		engine.AddFunc("yield", func() string {
@@ -109,11 +105,11 @@ func NewServer(opts ServerOptions) (*Server, error) {
 			return dict, nil
 		})
 
-		// Reload templates untuk development
-		engine.Reload(true)
-
-		// Debug mode untuk lebih banyak informasi error
-		engine.Debug(true)
+		// Reload dan debug hanya untuk template dari disk saat development
+		if opts.ViewsDir != "" {
+			engine.Reload(true)
+			engine.Debug(true)
+		}
 
 		// Set engine ke konfigurasi fiber
 		fiberConfig.Views = engine
@@ -128,7 +124,9 @@ func NewServer(opts ServerOptions) (*Server, error) {
 	// Buat Fiber app dengan konfigurasi
 	app := fiber.New(fiberConfig)
 
-	// Add standard middleware
+	// Add standard middleware. Metrics dipasang paling awal agar request yang panic
+	// tetap tercatat sebagai error 500 setelah ditangkap recover.
+	app.Use(metrics.Middleware())
 	app.Use(recover.New())
 	app.Use(fiberLogger.New(fiberLogger.Config{
 		Format: "[${time}] ${status} - ${latency} ${method} ${path}\n",
@@ -141,7 +139,8 @@ func NewServer(opts ServerOptions) (*Server, error) {
 		AllowCredentials: false,
 		ExposeHeaders:    "Content-Length, Content-Type",
 	}))
-	app.Use(compress.New())
+	// Stream SSE harus langsung dikirim ke klien, bukan dibuffer untuk dikompresi
+	app.Use(compress.New(compress.Config{Next: sse.IsStream}))
 
 	// Setup session store
 	sessionStore := session.New(session.Config{
@@ -162,21 +161,15 @@ func NewServer(opts ServerOptions) (*Server, error) {
 		return c.Next()
 	})
 
+	// Endpoint metric Prometheus tanpa autentikasi, sama seperti /ping
+	app.Get("/metrics", metrics.Handler())
+
 	// Pass session store to WebHandler if it exists
 	if opts.WebHandler != nil {
 		// Set session store to WebHandler
 		opts.WebHandler.SetSessionStore(sessionStore)
 
-		if opts.EnableTemplateEngine {
-			// Serve static files jika template engine diaktifkan
-			staticPath := filepath.Join(utils.ProjectRoot, "static")
-			if err := utils.EnsureDirectoryExists(staticPath); err != nil {
-				return nil, err
-			}
-			app.Static("/static", staticPath)
-		}
-
-		// Daftarkan web routes
+		// Daftarkan web routes, termasuk aset statis
 		opts.WebHandler.RegisterRoutes(app)
 	}
 
@@ -187,7 +180,7 @@ func NewServer(opts ServerOptions) (*Server, error) {
 
 	utils.Info("Server berhasil diinisialisasi dengan timeout handling", utils.Fields{
 		"template_engine": opts.EnableTemplateEngine,
-		"views_path":      opts.ViewsPath,
+		"views_dir":       viewsSource(opts.ViewsDir),
 		"read_timeout":    opts.Config.Server.ReadTimeout,
 		"write_timeout":   opts.Config.Server.WriteTimeout,
 		"idle_timeout":    30 * time.Second,
@@ -199,6 +192,16 @@ func NewServer(opts ServerOptions) (*Server, error) {
 	}, nil
 }
 
+// bodyLimit menghitung batas ukuran body request agar upload dokumen
+// (termasuk overhead base64 dan multipart) tidak ditolak oleh Fiber
+func bodyLimit(cfg *config.Config) int {
+	limit := int(cfg.WhatsApp.MaxDocumentBytes()*4/3) + (1 << 20)
+	if limit < fiber.DefaultBodyLimit {
+		return fiber.DefaultBodyLimit
+	}
+	return limit
+}
+
 // createAPIErrorHandler membuat handler kesalahan untuk API
 func createAPIErrorHandler() func(*fiber.Ctx, error) error {
 	return func(c *fiber.Ctx, err error) error {
@@ -265,3 +268,11 @@ func createWebErrorHandler() func(*fiber.Ctx, error) error {
 		return nil
 	}
 }
+
+// viewsSource menjelaskan asal template untuk keperluan log
+func viewsSource(dir string) string {
+	if dir == "" {
+		return "embedded"
+	}
+	return dir
+}
@@ -0,0 +1,78 @@
+package apikey
+
+import (
+	"fmt"
+	"time"
+)
+
+// Scope adalah hak akses yang dimiliki sebuah API key
+type Scope string
+
+const (
+	ScopeSendPersonal Scope = "send:personal"
+	ScopeSendGroup    Scope = "send:group"
+	ScopeGroupsRead   Scope = "groups:read"
+	ScopeLogsRead     Scope = "logs:read"
+	ScopeAdmin        Scope = "admin"
+)
+
+// AllScopes berisi semua scope yang dikenal
+var AllScopes = []Scope{
+	ScopeSendPersonal,
+	ScopeSendGroup,
+	ScopeGroupsRead,
+	ScopeLogsRead,
+	ScopeAdmin,
+}
+
+// ParseScopes memvalidasi dan mengkonversi daftar nama scope
+func ParseScopes(names []string) ([]Scope, error) {
+	scopes := make([]Scope, 0, len(names))
+	for _, name := range names {
+		scope := Scope(name)
+		known := false
+		for _, s := range AllScopes {
+			if s == scope {
+				known = true
+				break
+			}
+		}
+		if !known {
+			return nil, fmt.Errorf("scope tidak dikenal: %s", name)
+		}
+		scopes = append(scopes, scope)
+	}
+	return scopes, nil
+}
+
+// Key adalah API key bernama. Token asli tidak disimpan, hanya hash-nya.
+type Key struct {
+	ID         string     `json:"id"`
+	Name       string     `json:"name"`
+	Prefix     string     `json:"prefix"` // Beberapa karakter awal token untuk identifikasi
+	Scopes     []Scope    `json:"scopes"`
+	CreatedAt  time.Time  `json:"created_at"`
+	ExpiresAt  *time.Time `json:"expires_at,omitempty"`
+	LastUsedAt *time.Time `json:"last_used_at,omitempty"`
+}
+
+// storedKey adalah bentuk Key yang disimpan di storage, termasuk hash token
+type storedKey struct {
+	Key
+	Hash string `json:"hash"`
+}
+
+// HasScope memeriksa apakah key memiliki scope tertentu. Scope admin mencakup semua scope.
+func (k *Key) HasScope(scope Scope) bool {
+	for _, s := range k.Scopes {
+		if s == scope || s == ScopeAdmin {
+			return true
+		}
+	}
+	return false
+}
+
+// Expired memeriksa apakah key sudah kedaluwarsa
+func (k *Key) Expired(now time.Time) bool {
+	return k.ExpiresAt != nil && !now.Before(*k.ExpiresAt)
+}
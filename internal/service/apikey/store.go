@@ -0,0 +1,224 @@
+package apikey
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/gwenziro/bot-notify/internal/storage"
+	"github.com/gwenziro/bot-notify/internal/utils"
+)
+
+const (
+	// storagePrefix adalah prefix key API key di storage
+	storagePrefix = "apikeys"
+
+	// tokenPrefix menandai token API key agar mudah dikenali
+	tokenPrefix = "bn_"
+
+	// lastUsedInterval membatasi penulisan last-used agar tidak terjadi di setiap request
+	lastUsedInterval = time.Minute
+)
+
+var (
+	// ErrKeyNotFound dikembalikan ketika API key tidak ada
+	ErrKeyNotFound = errors.New("API key tidak ditemukan")
+
+	// ErrKeyExpired dikembalikan ketika API key sudah kedaluwarsa
+	ErrKeyExpired = errors.New("API key sudah kedaluwarsa")
+)
+
+// Store menyimpan API key dalam bentuk hash di storage
+type Store struct {
+	helper *storage.Helper
+	logger utils.LogrusEntry
+	mu     sync.Mutex
+}
+
+// NewStore membuat instance baru Store
+func NewStore(store storage.Storage) *Store {
+	return &Store{
+		helper: storage.NewHelper(store, storagePrefix),
+		logger: utils.ForModule("apikey"),
+	}
+}
+
+// keyKey membuat key untuk data API key
+func keyKey(id string) string {
+	return "key:" + id
+}
+
+// hashKey membuat key indeks dari hash token ke ID API key
+func hashKey(hash string) string {
+	return "hash:" + hash
+}
+
+// hashToken menghitung hash SHA-256 dari token
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// generateToken membuat token acak yang aman secara kriptografis
+func generateToken() (string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("gagal membuat token acak: %w", err)
+	}
+	return tokenPrefix + base64.RawURLEncoding.EncodeToString(buf), nil
+}
+
+// Create membuat API key baru dan mengembalikan token asli.
+// Token hanya dikembalikan sekali dan tidak dapat diambil lagi.
+func (s *Store) Create(name string, scopes []Scope, expiresAt *time.Time) (string, *Key, error) {
+	if strings.TrimSpace(name) == "" {
+		return "", nil, errors.New("nama API key harus diisi")
+	}
+	if len(scopes) == 0 {
+		return "", nil, errors.New("minimal satu scope harus diberikan")
+	}
+
+	token, err := generateToken()
+	if err != nil {
+		return "", nil, err
+	}
+
+	stored := storedKey{
+		Key: Key{
+			ID:        uuid.New().String(),
+			Name:      name,
+			Prefix:    token[:len(tokenPrefix)+6],
+			Scopes:    scopes,
+			CreatedAt: time.Now(),
+			ExpiresAt: expiresAt,
+		},
+		Hash: hashToken(token),
+	}
+
+	ctx := context.Background()
+	if err := s.helper.SetJSON(ctx, keyKey(stored.ID), stored); err != nil {
+		return "", nil, fmt.Errorf("gagal menyimpan API key: %w", err)
+	}
+	if err := s.helper.SetJSON(ctx, hashKey(stored.Hash), stored.ID); err != nil {
+		return "", nil, fmt.Errorf("gagal menyimpan indeks API key: %w", err)
+	}
+
+	s.logger.WithFields(utils.Fields{
+		"id":     stored.ID,
+		"name":   name,
+		"scopes": scopes,
+	}).Info("API key dibuat")
+
+	return token, &stored.Key, nil
+}
+
+// List mengembalikan semua API key tanpa hash token, terbaru lebih dulu
+func (s *Store) List() ([]*Key, error) {
+	data, err := s.helper.GetAllWithPrefix(context.Background(), "key:")
+	if err != nil {
+		return nil, fmt.Errorf("gagal membaca API key: %w", err)
+	}
+
+	keys := make([]*Key, 0, len(data))
+	for k, raw := range data {
+		var stored storedKey
+		if err := json.Unmarshal(raw, &stored); err != nil {
+			s.logger.WithError(err).WithField("key", k).Warn("Gagal parse API key")
+			continue
+		}
+		key := stored.Key
+		keys = append(keys, &key)
+	}
+
+	sort.Slice(keys, func(i, j int) bool {
+		return keys[i].CreatedAt.After(keys[j].CreatedAt)
+	})
+
+	return keys, nil
+}
+
+// Revoke menghapus API key sehingga tidak dapat digunakan lagi
+func (s *Store) Revoke(id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	stored, err := s.get(id)
+	if err != nil {
+		return err
+	}
+
+	ctx := context.Background()
+	if err := s.helper.Delete(ctx, hashKey(stored.Hash)); err != nil {
+		return fmt.Errorf("gagal menghapus indeks API key: %w", err)
+	}
+	if err := s.helper.Delete(ctx, keyKey(id)); err != nil {
+		return fmt.Errorf("gagal menghapus API key: %w", err)
+	}
+
+	s.logger.WithFields(utils.Fields{
+		"id":   id,
+		"name": stored.Name,
+	}).Info("API key dicabut")
+
+	return nil
+}
+
+// Authenticate mencari API key berdasarkan token dan memperbarui waktu terakhir digunakan
+func (s *Store) Authenticate(token string) (*Key, error) {
+	if !strings.HasPrefix(token, tokenPrefix) {
+		return nil, ErrKeyNotFound
+	}
+
+	ctx := context.Background()
+	var id string
+	if err := s.helper.GetJSON(ctx, hashKey(hashToken(token)), &id); err != nil {
+		if storage.IsNotFound(err) {
+			return nil, ErrKeyNotFound
+		}
+		return nil, fmt.Errorf("gagal membaca indeks API key: %w", err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	stored, err := s.get(id)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	if stored.Expired(now) {
+		return nil, ErrKeyExpired
+	}
+
+	if stored.LastUsedAt == nil || now.Sub(*stored.LastUsedAt) >= lastUsedInterval {
+		stored.LastUsedAt = &now
+		if err := s.helper.SetJSON(ctx, keyKey(id), stored); err != nil {
+			s.logger.WithError(err).WithField("id", id).Warn("Gagal memperbarui waktu penggunaan API key")
+		}
+	}
+
+	return &stored.Key, nil
+}
+
+// get membaca API key lengkap dengan hash dari storage
+func (s *Store) get(id string) (*storedKey, error) {
+	var stored storedKey
+	if err := s.helper.GetJSON(context.Background(), keyKey(id), &stored); err != nil {
+		if storage.IsNotFound(err) {
+			return nil, ErrKeyNotFound
+		}
+		return nil, fmt.Errorf("gagal membaca API key: %w", err)
+	}
+	return &stored, nil
+}
@@ -2,38 +2,104 @@ package log
 
 import (
 	"bytes"
+	"context"
 	"encoding/csv"
 	"encoding/json"
 	"fmt"
 	"math"
+	"sync"
 	"time"
 
 	"github.com/gwenziro/bot-notify/internal/api/model"
+	"github.com/gwenziro/bot-notify/internal/config"
 	"github.com/gwenziro/bot-notify/internal/repository"
 	"github.com/gwenziro/bot-notify/internal/utils"
 )
 
+// exportLimit adalah jumlah maksimum log dalam satu file ekspor
+const exportLimit = 10000
+
 // LogService menyediakan operasi terkait log
 type LogService struct {
 	repository *repository.LogRepository
+	config     *config.Live
 	logger     utils.LogrusEntry
+
+	// Pruning log berdasarkan logging.retention. pruneMu mencegah pruning berjalan bersamaan,
+	// sedangkan hasil pruning terakhir dibaca dengan statsMu agar statistik tidak menunggu pruning.
+	pruneMu     sync.Mutex
+	statsMu     sync.Mutex
+	stats       *model.LogRetentionStats
+	totalPruned int
+	lastPruneAt *time.Time
+	cancel      context.CancelFunc
+	wg          sync.WaitGroup
+
+	// Klien yang mengikuti log baru melalui /api/logs/stream
+	stream *logBroadcaster
 }
 
 // NewLogService membuat service log baru
-func NewLogService(repository *repository.LogRepository, logger utils.LogrusEntry) *LogService {
+func NewLogService(repository *repository.LogRepository, cfg *config.Live, logger utils.LogrusEntry) *LogService {
 	return &LogService{
 		repository: repository,
+		config:     cfg,
 		logger:     logger.WithField("component", "log-service"),
+		stream:     &logBroadcaster{subscribers: make(map[*logSubscriber]struct{})},
 	}
 }
 
 // CreateLog membuat dan menyimpan log baru
 func (s *LogService) CreateLog(level, source, message string, data map[string]interface{}) error {
 	log := model.NewLog(level, source, message, data)
-	return s.repository.SaveLog(log)
+	return s.SaveLog(log)
+}
+
+// NewLogQuery membuat LogQuery dari parameter filter. from dan to berformat
+// 2006-01-02 dan mencakup seluruh hari, tanggal yang tidak valid diabaikan.
+func NewLogQuery(level, source, search, from, to string) model.LogQuery {
+	q := model.LogQuery{Level: level, Source: source, Search: search}
+
+	if fromDate, err := time.Parse("2006-01-02", from); err == nil {
+		q.From = fromDate
+	}
+	if toDate, err := time.Parse("2006-01-02", to); err == nil {
+		// Tambahkan 1 hari ke to date untuk mencakup seluruh hari
+		q.To = toDate.Add(24 * time.Hour)
+	}
+
+	return q
+}
+
+// QueryLogs mendapatkan satu halaman logs berdasarkan cursor atau offset beserta jumlah log yang cocok.
+// Jumlah hanya dihitung untuk halaman tanpa cursor, halaman dengan cursor mengembalikan total -1.
+func (s *LogService) QueryLogs(q model.LogQuery) (*model.LogPage, int, error) {
+	if q.Limit < 1 || q.Limit > 100 {
+		q.Limit = 25 // Default limit
+	}
+	if q.Offset < 0 {
+		q.Offset = 0
+	}
+
+	page, err := s.repository.QueryLogs(q)
+	if err != nil {
+		return nil, 0, fmt.Errorf("gagal mendapatkan logs: %w", err)
+	}
+
+	// Klien menyimpan total dari halaman pertama, jadi halaman berikutnya tidak perlu menghitung ulang
+	if q.Before != "" || q.After != "" {
+		return page, -1, nil
+	}
+
+	total, err := s.repository.CountLogs(q)
+	if err != nil {
+		return nil, 0, fmt.Errorf("gagal mendapatkan logs: %w", err)
+	}
+
+	return page, total, nil
 }
 
-// GetPaginatedLogs mendapatkan logs dengan paginasi dan filter
+// GetPaginatedLogs mendapatkan logs dengan paginasi berbasis nomor halaman dan filter
 func (s *LogService) GetPaginatedLogs(page, limit int, level, source, search, from, to string) ([]*model.Log, int, int, error) {
 	// Validasi parameter
 	if page < 1 {
@@ -43,16 +109,19 @@ func (s *LogService) GetPaginatedLogs(page, limit int, level, source, search, fr
 		limit = 25 // Default limit
 	}
 
-	// Ambil logs dari repository dengan filter
-	logs, totalLogs, err := s.repository.GetLogsByFilter(page, limit, level, source, search, from, to)
+	q := NewLogQuery(level, source, search, from, to)
+	q.Offset = (page - 1) * limit
+	q.Limit = limit
+
+	result, totalLogs, err := s.QueryLogs(q)
 	if err != nil {
-		return nil, 0, 0, fmt.Errorf("gagal mendapatkan logs: %w", err)
+		return nil, 0, 0, err
 	}
 
 	// Hitung total halaman
 	totalPages := int(math.Ceil(float64(totalLogs) / float64(limit)))
 
-	return logs, totalLogs, totalPages, nil
+	return result.Logs, totalLogs, totalPages, nil
 }
 
 // ClearAllLogs menghapus seluruh data log
@@ -75,13 +144,25 @@ func (s *LogService) ClearAllLogs() error {
 	return nil
 }
 
+// GetSummary menyusun ringkasan log untuk dashboard beserta latest log terbaru
+func (s *LogService) GetSummary(latest int) (*model.LogSummary, error) {
+	summary, err := s.repository.Summary(time.Now(), latest)
+	if err != nil {
+		return nil, fmt.Errorf("gagal menyusun ringkasan log: %w", err)
+	}
+	return summary, nil
+}
+
 // ExportLogs mengekspor logs dalam format tertentu
 func (s *LogService) ExportLogs(format, level, source, search, from, to string) ([]byte, string, string, error) {
-	// Ambil semua log dengan filter tanpa paginasi (limit besar)
-	logs, _, _, err := s.GetPaginatedLogs(1, 10000, level, source, search, from, to)
+	// Ambil log terbaru yang cocok dengan filter tanpa paginasi
+	q := NewLogQuery(level, source, search, from, to)
+	q.Limit = exportLimit
+	result, err := s.repository.QueryLogs(q)
 	if err != nil {
 		return nil, "", "", fmt.Errorf("gagal mendapatkan logs untuk ekspor: %w", err)
 	}
+	logs := result.Logs
 
 	// Tentukan nama file berdasarkan timestamp
 	timestamp := time.Now().Format("20060102_150405")
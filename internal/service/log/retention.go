@@ -0,0 +1,121 @@
+package log
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/gwenziro/bot-notify/internal/api/model"
+	"github.com/gwenziro/bot-notify/internal/utils"
+)
+
+// disabledRetentionCheck adalah jarak pemeriksaan ulang konfigurasi saat pruning otomatis nonaktif
+const disabledRetentionCheck = time.Minute
+
+// RetentionPolicy membuat batas penyimpanan log dari logging.retention
+func (s *LogService) RetentionPolicy() model.LogRetentionPolicy {
+	retention := s.config.Get().Logging.Retention
+	return model.LogRetentionPolicy{
+		MaxAge: map[string]time.Duration{
+			string(model.LogLevelDebug):   retention.MaxAge.Debug,
+			string(model.LogLevelInfo):    retention.MaxAge.Info,
+			string(model.LogLevelWarning): retention.MaxAge.Warning,
+			string(model.LogLevelError):   retention.MaxAge.Error,
+			string(model.LogLevelFatal):   retention.MaxAge.Fatal,
+		},
+		MaxEntries: retention.MaxEntries,
+		MaxBytes:   int64(retention.MaxBytes),
+	}
+}
+
+// RetentionInterval mengembalikan jarak antar pruning otomatis, 0 berarti nonaktif
+func (s *LogService) RetentionInterval() time.Duration {
+	return s.config.Get().Logging.Retention.Interval
+}
+
+// StartRetention menjalankan pruning log di background sesuai logging.retention.
+// Konfigurasi dibaca ulang setiap putaran sehingga perubahan berlaku tanpa restart.
+func (s *LogService) StartRetention() {
+	ctx, cancel := context.WithCancel(context.Background())
+	s.cancel = cancel
+
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+
+		for {
+			interval := s.RetentionInterval()
+			if interval > 0 {
+				if _, err := s.PruneLogs(); err != nil {
+					s.logger.WithError(err).Error("Gagal menghapus log lama")
+				}
+			} else {
+				interval = disabledRetentionCheck
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(interval):
+			}
+		}
+	}()
+}
+
+// StopRetention menghentikan pruning log dan menunggu pruning yang sedang berjalan selesai
+func (s *LogService) StopRetention() {
+	if s.cancel == nil {
+		return
+	}
+	s.cancel()
+	s.wg.Wait()
+}
+
+// PruneLogs langsung menghapus log yang melewati batas penyimpanan dan mengembalikan statistik terbaru
+func (s *LogService) PruneLogs() (*model.LogRetentionStats, error) {
+	s.pruneMu.Lock()
+	defer s.pruneMu.Unlock()
+
+	now := time.Now()
+	stats, err := s.repository.Prune(s.RetentionPolicy(), now)
+	if err != nil {
+		return nil, fmt.Errorf("gagal menerapkan retensi log: %w", err)
+	}
+
+	s.statsMu.Lock()
+	s.totalPruned += stats.Pruned
+	s.lastPruneAt = &now
+	stats.TotalPruned = s.totalPruned
+	stats.LastPruneAt = s.lastPruneAt
+	s.stats = stats
+	s.statsMu.Unlock()
+
+	if stats.Pruned > 0 {
+		s.logger.WithFields(utils.Fields{
+			"pruned":    stats.Pruned,
+			"remaining": stats.TotalEntries,
+		}).Info("Log lama dihapus sesuai kebijakan retensi")
+	}
+
+	return stats, nil
+}
+
+// RetentionStats mengembalikan statistik log yang tersimpan dari counter beserta hasil
+// pruning terakhir, tanpa menunggu pruning yang sedang berjalan
+func (s *LogService) RetentionStats() (*model.LogRetentionStats, error) {
+	stats, err := s.repository.Stats()
+	if err != nil {
+		return nil, fmt.Errorf("gagal menghitung statistik log: %w", err)
+	}
+
+	s.statsMu.Lock()
+	defer s.statsMu.Unlock()
+
+	stats.Pruned = 0
+	if s.stats != nil {
+		stats.Pruned = s.stats.Pruned
+	}
+	stats.TotalPruned = s.totalPruned
+	stats.LastPruneAt = s.lastPruneAt
+	return stats, nil
+}
@@ -0,0 +1,229 @@
+package log
+
+import (
+	"strings"
+	"sync"
+
+	"github.com/gwenziro/bot-notify/internal/api/model"
+	"github.com/gwenziro/bot-notify/internal/repository"
+	"github.com/gwenziro/bot-notify/internal/utils"
+)
+
+const (
+	// streamBuffer adalah kapasitas antrean event per klien stream log
+	streamBuffer = 256
+
+	// resumeLimit adalah jumlah maksimum log tersimpan yang dikirim ulang saat klien
+	// menyambung kembali. Jika lebih, klien menerima event dropped dan memuat ulang log.
+	resumeLimit = 1000
+
+	// resumePageSize adalah jumlah log per halaman saat membaca log yang terlewat
+	resumePageSize = 100
+)
+
+// logSubscriber adalah satu klien stream log beserta filternya
+type logSubscriber struct {
+	filter  model.LogQuery
+	events  chan model.LogStreamEvent
+	dropped int
+}
+
+// matches memeriksa apakah log cocok dengan filter level, source, dan pencarian subscriber
+func (sub *logSubscriber) matches(log *model.Log) bool {
+	if sub.filter.Level != "" && !strings.EqualFold(sub.filter.Level, log.Level) {
+		return false
+	}
+	if sub.filter.Source != "" && !strings.EqualFold(sub.filter.Source, log.Source) {
+		return false
+	}
+	return sub.filter.Search == "" ||
+		strings.Contains(strings.ToLower(log.Message), strings.ToLower(sub.filter.Search))
+}
+
+// logBroadcaster meneruskan log yang baru tersimpan ke semua klien stream.
+// Klien yang lambat tidak menahan penyimpanan log: log untuknya dilewati dan
+// jumlahnya dikirim sebagai event dropped begitu antreannya kembali longgar.
+type logBroadcaster struct {
+	mu          sync.Mutex
+	subscribers map[*logSubscriber]struct{}
+	closed      bool
+}
+
+// publish mengirim log ke subscriber yang filternya cocok tanpa pernah memblokir.
+// Tidak boleh menulis log karena dipanggil dari hook penyimpanan log.
+func (b *logBroadcaster) publish(log *model.Log) {
+	cursor := repository.LogCursor(log)
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	for sub := range b.subscribers {
+		if !sub.matches(log) {
+			continue
+		}
+
+		// Beri tahu klien log yang terlewat sebelum mengirim log berikutnya
+		if sub.dropped > 0 {
+			select {
+			case sub.events <- model.LogStreamEvent{Event: model.LogStreamEventDropped, Dropped: sub.dropped}:
+				sub.dropped = 0
+			default:
+				sub.dropped++
+				continue
+			}
+		}
+
+		select {
+		case sub.events <- model.LogStreamEvent{Event: model.LogStreamEventLog, Log: log, Cursor: cursor}:
+		default:
+			sub.dropped++
+		}
+	}
+}
+
+// WriteLog menyimpan entri dari hook penyimpanan log. Mengimplementasikan utils.LogWriter.
+func (s *LogService) WriteLog(entry utils.LogEntry) error {
+	return s.SaveLog(&model.Log{
+		Timestamp: entry.Timestamp,
+		Level:     entry.Level,
+		Source:    entry.Source,
+		Message:   entry.Message,
+		Data:      entry.Data,
+	})
+}
+
+// SaveLog menyimpan log ke repository lalu meneruskannya ke klien stream log
+func (s *LogService) SaveLog(log *model.Log) error {
+	if err := s.repository.SaveLog(log); err != nil {
+		return err
+	}
+	s.stream.publish(log)
+	return nil
+}
+
+// Subscribe mendaftarkan klien stream log dengan filter level, source, dan pencarian
+// dari q. Fungsi yang dikembalikan harus dipanggil untuk berhenti berlangganan.
+func (s *LogService) Subscribe(q model.LogQuery) (<-chan model.LogStreamEvent, func()) {
+	sub := &logSubscriber{
+		filter: q,
+		events: make(chan model.LogStreamEvent, streamBuffer),
+	}
+
+	s.stream.mu.Lock()
+	if s.stream.closed {
+		close(sub.events)
+	} else {
+		s.stream.subscribers[sub] = struct{}{}
+	}
+	s.stream.mu.Unlock()
+
+	var once sync.Once
+	return sub.events, func() {
+		once.Do(func() {
+			s.stream.mu.Lock()
+			delete(s.stream.subscribers, sub)
+			s.stream.mu.Unlock()
+		})
+	}
+}
+
+// SubscribeFrom seperti Subscribe, tetapi lebih dulu mengirim log tersimpan yang cocok
+// dengan filter dan lebih baru dari cursor, yaitu log yang terlewat selama klien terputus.
+// Cursor yang tidak valid menghasilkan repository.ErrInvalidCursor.
+func (s *LogService) SubscribeFrom(q model.LogQuery, cursor string) (<-chan model.LogStreamEvent, func(), error) {
+	// Berlangganan lebih dulu agar log yang masuk selama membaca log lama tidak terlewat
+	live, unsubscribe := s.Subscribe(q)
+
+	var missed []*model.Log
+	more := false
+	for after := cursor; ; {
+		q.After = after
+		q.Limit = resumePageSize
+		page, err := s.repository.QueryLogs(q)
+		if err != nil {
+			unsubscribe()
+			return nil, nil, err
+		}
+
+		// Halaman after berisi log terbaru lebih dulu, dikirim ke klien dari yang terlama
+		for i := len(page.Logs) - 1; i >= 0; i-- {
+			missed = append(missed, page.Logs[i])
+		}
+		if page.PrevCursor == "" {
+			break
+		}
+		if len(missed) >= resumeLimit {
+			more = true
+			break
+		}
+		after = page.PrevCursor
+	}
+
+	events := make(chan model.LogStreamEvent, streamBuffer)
+	done := make(chan struct{})
+	send := func(evt model.LogStreamEvent) bool {
+		select {
+		case events <- evt:
+			return true
+		case <-done:
+			return false
+		}
+	}
+
+	go func() {
+		defer close(events)
+
+		if more {
+			if !send(model.LogStreamEvent{Event: model.LogStreamEventDropped}) {
+				return
+			}
+			missed = nil
+		}
+
+		// Log yang tersimpan setelah berlangganan bisa ikut terbaca, jangan dikirim dua kali
+		sent := make(map[string]bool, len(missed))
+		for _, log := range missed {
+			sent[log.ID] = true
+			if !send(model.LogStreamEvent{Event: model.LogStreamEventLog, Log: log, Cursor: repository.LogCursor(log)}) {
+				return
+			}
+		}
+
+		for {
+			select {
+			case evt, ok := <-live:
+				if !ok {
+					return
+				}
+				if evt.Log != nil && sent[evt.Log.ID] {
+					continue
+				}
+				if !send(evt) {
+					return
+				}
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	var once sync.Once
+	return events, func() {
+		once.Do(func() {
+			close(done)
+			unsubscribe()
+		})
+	}, nil
+}
+
+// CloseStream mengakhiri semua stream log yang terbuka, dipanggil sebelum server dimatikan
+func (s *LogService) CloseStream() {
+	s.stream.mu.Lock()
+	defer s.stream.mu.Unlock()
+
+	s.stream.closed = true
+	for sub := range s.stream.subscribers {
+		close(sub.events)
+		delete(s.stream.subscribers, sub)
+	}
+}
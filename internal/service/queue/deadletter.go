@@ -0,0 +1,138 @@
+package queue
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/gwenziro/bot-notify/internal/storage"
+	"github.com/gwenziro/bot-notify/internal/utils"
+)
+
+// deadLetter memindahkan pesan yang gagal permanen ke dead-letter queue
+func (q *Queue) deadLetter(msg *OutboundMessage, previousDue time.Time) {
+	ctx := context.Background()
+	now := time.Now()
+
+	msg.Status = StatusDead
+	msg.DeadAt = &now
+	msg.UpdatedAt = now
+
+	// Simpan ke dead-letter terlebih dahulu agar pesan tidak hilang jika proses berhenti
+	if err := q.helper.SetJSON(ctx, deadKey(msg.ID), msg); err != nil {
+		q.logger.WithError(err).WithField("id", msg.ID).Error("Gagal memindahkan pesan ke dead-letter queue")
+		return
+	}
+	q.helper.Delete(ctx, messageKey(msg.ID))
+	q.helper.Delete(ctx, pendingKey(previousDue, msg.ID))
+
+	q.logger.WithFields(utils.Fields{
+		"id":       msg.ID,
+		"to":       msg.Recipient,
+		"attempts": msg.Attempts,
+		"class":    msg.LastErrorClass,
+		"error":    msg.LastError,
+	}).Warn("Pesan dipindahkan ke dead-letter queue")
+}
+
+// ListDeadLetters mengembalikan semua pesan di dead-letter queue, terbaru lebih dulu
+func (q *Queue) ListDeadLetters() ([]*OutboundMessage, error) {
+	data, err := q.helper.GetAllWithPrefix(context.Background(), "dead:")
+	if err != nil {
+		return nil, fmt.Errorf("gagal membaca dead-letter queue: %w", err)
+	}
+
+	messages := make([]*OutboundMessage, 0, len(data))
+	for key, raw := range data {
+		var msg OutboundMessage
+		if err := json.Unmarshal(raw, &msg); err != nil {
+			q.logger.WithError(err).WithField("key", key).Warn("Gagal parse pesan dead-letter")
+			continue
+		}
+		messages = append(messages, &msg)
+	}
+
+	sort.Slice(messages, func(i, j int) bool {
+		return messages[i].UpdatedAt.After(messages[j].UpdatedAt)
+	})
+
+	return messages, nil
+}
+
+// GetDeadLetter mengambil pesan dari dead-letter queue berdasarkan ID
+func (q *Queue) GetDeadLetter(id string) (*OutboundMessage, error) {
+	var msg OutboundMessage
+	if err := q.helper.GetJSON(context.Background(), deadKey(id), &msg); err != nil {
+		if storage.IsNotFound(err) {
+			return nil, ErrMessageNotFound
+		}
+		return nil, fmt.Errorf("gagal membaca pesan dead-letter: %w", err)
+	}
+	return &msg, nil
+}
+
+// RequeueDeadLetter mengembalikan pesan dari dead-letter queue ke antrean utama
+// dengan jumlah percobaan direset
+func (q *Queue) RequeueDeadLetter(id string) (*OutboundMessage, error) {
+	msg, err := q.GetDeadLetter(id)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	msg.Status = StatusQueued
+	msg.Attempts = 0
+	msg.LastError = ""
+	msg.LastErrorClass = ""
+	msg.DeadAt = nil
+	msg.UpdatedAt = now
+	msg.NextAttemptAt = now
+
+	ctx := context.Background()
+	if err := q.helper.SetJSON(ctx, messageKey(msg.ID), msg); err != nil {
+		return nil, fmt.Errorf("gagal menyimpan pesan ke antrean: %w", err)
+	}
+	if err := q.helper.SetJSON(ctx, pendingKey(msg.NextAttemptAt, msg.ID), msg.ID); err != nil {
+		return nil, fmt.Errorf("gagal mendaftarkan pesan ke antrean: %w", err)
+	}
+	if err := q.helper.Delete(ctx, deadKey(msg.ID)); err != nil {
+		q.logger.WithError(err).WithField("id", msg.ID).Warn("Gagal menghapus pesan dari dead-letter queue")
+	}
+
+	q.logger.WithField("id", msg.ID).Info("Pesan dead-letter dimasukkan kembali ke antrean")
+
+	q.wake()
+	return msg, nil
+}
+
+// PurgeDeadLetter menghapus satu pesan dari dead-letter queue
+func (q *Queue) PurgeDeadLetter(id string) error {
+	if _, err := q.GetDeadLetter(id); err != nil {
+		return err
+	}
+
+	if err := q.helper.Delete(context.Background(), deadKey(id)); err != nil {
+		return fmt.Errorf("gagal menghapus pesan dead-letter: %w", err)
+	}
+
+	q.logger.WithField("id", id).Info("Pesan dead-letter dihapus")
+	return nil
+}
+
+// PurgeDeadLetters menghapus semua pesan di dead-letter queue dan mengembalikan jumlahnya
+func (q *Queue) PurgeDeadLetters() (int, error) {
+	ctx := context.Background()
+	data, err := q.helper.GetAllWithPrefix(ctx, "dead:")
+	if err != nil {
+		return 0, fmt.Errorf("gagal membaca dead-letter queue: %w", err)
+	}
+
+	if err := q.helper.DeleteAllWithPrefix(ctx, "dead:"); err != nil {
+		return 0, fmt.Errorf("gagal mengosongkan dead-letter queue: %w", err)
+	}
+
+	q.logger.WithField("jumlah", len(data)).Info("Dead-letter queue dikosongkan")
+	return len(data), nil
+}
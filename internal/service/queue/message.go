@@ -0,0 +1,90 @@
+package queue
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// MessageStatus menunjukkan status pesan di dalam antrean
+type MessageStatus string
+
+const (
+	StatusScheduled MessageStatus = "scheduled"
+	StatusQueued    MessageStatus = "queued"
+	StatusSending   MessageStatus = "sending"
+	StatusSent      MessageStatus = "sent"
+	StatusDead      MessageStatus = "dead"
+	StatusCancelled MessageStatus = "cancelled"
+)
+
+// MessageType menunjukkan jenis penerima pesan
+type MessageType string
+
+const (
+	TypePersonal MessageType = "personal"
+	TypeGroup    MessageType = "group"
+)
+
+// OutboundMessage adalah pesan keluar yang disimpan sebelum dikirim
+type OutboundMessage struct {
+	ID             string        `json:"id"`
+	Account        string        `json:"account,omitempty"`
+	Type           MessageType   `json:"type"`
+	Recipient      string        `json:"recipient"`
+	Message        string        `json:"message"`
+	Status         MessageStatus `json:"status"`
+	WhatsAppID     string        `json:"whatsapp_id,omitempty"`
+	Attempts       int           `json:"attempts"`
+	RetryPolicy    *RetryPolicy  `json:"retry_policy,omitempty"`
+	LastError      string        `json:"last_error,omitempty"`
+	LastErrorClass ErrorClass    `json:"last_error_class,omitempty"`
+	CreatedAt      time.Time     `json:"created_at"`
+	UpdatedAt      time.Time     `json:"updated_at"`
+	NextAttemptAt  time.Time     `json:"next_attempt_at"`
+	ScheduledAt    *time.Time    `json:"scheduled_at,omitempty"`
+	SentAt         *time.Time    `json:"sent_at,omitempty"`
+	DeadAt         *time.Time    `json:"dead_at,omitempty"`
+}
+
+// EnqueueOptions berisi pengaturan opsional saat memasukkan pesan ke antrean
+type EnqueueOptions struct {
+	// RetryPolicy adalah kebijakan retry khusus, nil berarti memakai default antrean
+	RetryPolicy *RetryPolicy
+	// SendAt adalah waktu pengiriman terjadwal, kosong berarti segera dikirim
+	SendAt time.Time
+	// Account adalah akun WhatsApp pengirim, kosong berarti akun default
+	Account string
+}
+
+// messageKey membuat key untuk data pesan
+func messageKey(id string) string {
+	return "msg:" + id
+}
+
+// deadKey membuat key untuk pesan di dead-letter queue
+func deadKey(id string) string {
+	return "dead:" + id
+}
+
+// pendingKey membuat key indeks antrean yang terurut berdasarkan waktu jatuh tempo
+// Format: pending:{unix_nano}:{id}
+func pendingKey(dueAt time.Time, id string) string {
+	return fmt.Sprintf("pending:%020d:%s", dueAt.UnixNano(), id)
+}
+
+// parsePendingKey mengambil waktu jatuh tempo dan ID pesan dari key indeks antrean
+func parsePendingKey(key string) (dueAt int64, id string, ok bool) {
+	// Format key: pending:{unix_nano}:{id}
+	parts := strings.SplitN(key, ":", 3)
+	if len(parts) != 3 || parts[0] != "pending" {
+		return 0, "", false
+	}
+
+	dueAt, err := strconv.ParseInt(parts[1], 10, 64)
+	if err != nil {
+		return 0, "", false
+	}
+	return dueAt, parts[2], true
+}
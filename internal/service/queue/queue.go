@@ -0,0 +1,494 @@
+package queue
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/gwenziro/bot-notify/internal/config"
+	"github.com/gwenziro/bot-notify/internal/service/whatsapp/client"
+	"github.com/gwenziro/bot-notify/internal/storage"
+	"github.com/gwenziro/bot-notify/internal/utils"
+	"go.mau.fi/whatsmeow/types"
+)
+
+const (
+	// storagePrefix adalah prefix key antrean di storage
+	storagePrefix = "outbox"
+
+	// pollInterval adalah interval pemeriksaan antrean ketika tidak ada notifikasi
+	pollInterval = 2 * time.Second
+
+	// defaultWorkers adalah jumlah worker jika tidak diatur di konfigurasi
+	defaultWorkers = 2
+)
+
+// ErrMessageNotFound dikembalikan ketika pesan tidak ada di antrean
+var ErrMessageNotFound = errors.New("pesan tidak ditemukan")
+
+// sender mengirim pesan teks dari satu akun WhatsApp, diimplementasikan oleh *client.Client
+type sender interface {
+	CanSend() bool
+	SendMessage(recipient types.JID, message string) (types.MessageID, error)
+}
+
+// accountSet menyediakan akun pengirim untuk antrean
+type accountSet interface {
+	AnyConnected() bool
+	Sender(name string) (sender, error)
+}
+
+// managerAccounts menghubungkan client.AccountManager ke antrean
+type managerAccounts struct {
+	*client.AccountManager
+}
+
+// Sender mengembalikan klien WhatsApp akun dengan nama tertentu
+func (m managerAccounts) Sender(name string) (sender, error) {
+	wa, err := m.Get(name)
+	if err != nil {
+		return nil, err
+	}
+	return wa, nil
+}
+
+// Queue adalah antrean pesan keluar yang persisten di storage.
+// Setiap pesan disimpan terlebih dahulu, lalu dikirim oleh worker pool
+// ketika WhatsApp dalam keadaan terhubung.
+type Queue struct {
+	helper        *storage.Helper
+	accounts      accountSet
+	logger        utils.LogrusEntry
+	workers       int
+	defaultPolicy RetryPolicy
+	retention     time.Duration
+	policyMu      sync.RWMutex
+
+	jobs     chan string
+	notify   chan struct{}
+	inflight map[string]struct{}
+	mu       sync.Mutex
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// NewQueue membuat instance baru Queue
+func NewQueue(store storage.Storage, accounts *client.AccountManager, cfg *config.Config) *Queue {
+	return newQueue(store, managerAccounts{accounts}, cfg)
+}
+
+// newQueue membuat Queue dengan sumber akun pengirim tertentu
+func newQueue(store storage.Storage, accounts accountSet, cfg *config.Config) *Queue {
+	workers := cfg.WhatsApp.QueueWorkers
+	if workers <= 0 {
+		workers = defaultWorkers
+	}
+
+	logger := utils.ForModule("queue")
+
+	// Kebijakan retry default, dipakai untuk pesan tanpa kebijakan khusus
+	policy, err := NewRetryPolicy(cfg.WhatsApp.SendRetry)
+	if err != nil {
+		logger.WithError(err).Warn("Konfigurasi retry tidak valid, menggunakan nilai default")
+		policy = RetryPolicy{}.withDefaults()
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	return &Queue{
+		helper:        storage.NewHelper(store, storagePrefix),
+		accounts:      accounts,
+		logger:        logger,
+		workers:       workers,
+		defaultPolicy: policy,
+		retention:     cfg.WhatsApp.QueueRetention,
+		jobs:          make(chan string),
+		notify:        make(chan struct{}, 1),
+		inflight:      make(map[string]struct{}),
+		ctx:           ctx,
+		cancel:        cancel,
+	}
+}
+
+// DefaultRetryPolicy mengembalikan kebijakan retry default antrean
+func (q *Queue) DefaultRetryPolicy() RetryPolicy {
+	q.policyMu.RLock()
+	defer q.policyMu.RUnlock()
+	return q.defaultPolicy
+}
+
+// SetDefaultRetryPolicy mengganti kebijakan retry default, misalnya setelah konfigurasi di-reload.
+// Pesan yang sudah memiliki kebijakan khusus tidak terpengaruh.
+func (q *Queue) SetDefaultRetryPolicy(cfg config.SendRetryConfig) error {
+	policy, err := NewRetryPolicy(cfg)
+	if err != nil {
+		return err
+	}
+
+	q.policyMu.Lock()
+	q.defaultPolicy = policy
+	q.policyMu.Unlock()
+
+	q.logger.WithFields(utils.Fields{
+		"max_attempts":    policy.MaxAttempts,
+		"initial_backoff": policy.InitialBackoff,
+		"max_backoff":     policy.MaxBackoff,
+	}).Info("Kebijakan retry default antrean diperbarui")
+	return nil
+}
+
+// Start menjalankan dispatcher dan worker pool
+func (q *Queue) Start() {
+	q.logger.WithField("workers", q.workers).Info("Menjalankan antrean pesan keluar")
+
+	for i := 0; i < q.workers; i++ {
+		q.wg.Add(1)
+		go q.worker()
+	}
+
+	q.wg.Add(1)
+	go q.dispatchLoop()
+
+	q.wg.Add(1)
+	go q.pruneLoop()
+}
+
+// Stop menghentikan dispatcher dan menunggu worker selesai
+func (q *Queue) Stop() {
+	q.logger.Info("Menghentikan antrean pesan keluar")
+	q.cancel()
+	q.wg.Wait()
+}
+
+// Enqueue menyimpan pesan baru ke antrean dan mengembalikan data pesan tersebut.
+// Pesan dengan opts.SendAt di masa depan disimpan sebagai pesan terjadwal.
+func (q *Queue) Enqueue(msgType MessageType, recipient types.JID, text string, opts EnqueueOptions) (*OutboundMessage, error) {
+	policy := q.DefaultRetryPolicy()
+	if opts.RetryPolicy != nil {
+		policy = opts.RetryPolicy.withDefaults()
+	}
+
+	now := time.Now()
+	msg := &OutboundMessage{
+		ID:            uuid.New().String(),
+		Account:       opts.Account,
+		Type:          msgType,
+		Recipient:     recipient.String(),
+		Message:       text,
+		Status:        StatusQueued,
+		RetryPolicy:   &policy,
+		CreatedAt:     now,
+		UpdatedAt:     now,
+		NextAttemptAt: now,
+	}
+
+	if opts.SendAt.After(now) {
+		sendAt := opts.SendAt
+		msg.Status = StatusScheduled
+		msg.ScheduledAt = &sendAt
+		msg.NextAttemptAt = sendAt
+	}
+
+	// Simpan data pesan sebelum mendaftarkannya ke indeks antrean
+	ctx := context.Background()
+	if err := q.helper.SetJSON(ctx, messageKey(msg.ID), msg); err != nil {
+		return nil, fmt.Errorf("gagal menyimpan pesan ke antrean: %w", err)
+	}
+	if err := q.helper.SetJSON(ctx, pendingKey(msg.NextAttemptAt, msg.ID), msg.ID); err != nil {
+		return nil, fmt.Errorf("gagal mendaftarkan pesan ke antrean: %w", err)
+	}
+
+	q.logger.WithFields(utils.Fields{
+		"id":      msg.ID,
+		"account": msg.Account,
+		"to":      msg.Recipient,
+		"type":    msg.Type,
+		"status":  msg.Status,
+		"send_at": msg.NextAttemptAt,
+	}).Info("Pesan masuk antrean")
+
+	q.wake()
+	return msg, nil
+}
+
+// Get mengambil pesan berdasarkan ID, termasuk pesan yang sudah masuk dead-letter queue
+func (q *Queue) Get(id string) (*OutboundMessage, error) {
+	msg, err := q.getActive(id)
+	if errors.Is(err, ErrMessageNotFound) {
+		return q.GetDeadLetter(id)
+	}
+	return msg, err
+}
+
+// getActive mengambil pesan yang masih berada di antrean utama
+func (q *Queue) getActive(id string) (*OutboundMessage, error) {
+	var msg OutboundMessage
+	if err := q.helper.GetJSON(context.Background(), messageKey(id), &msg); err != nil {
+		if storage.IsNotFound(err) {
+			return nil, ErrMessageNotFound
+		}
+		return nil, fmt.Errorf("gagal membaca pesan: %w", err)
+	}
+	return &msg, nil
+}
+
+// wake memberi sinyal ke dispatcher agar segera memeriksa antrean
+func (q *Queue) wake() {
+	select {
+	case q.notify <- struct{}{}:
+	default:
+	}
+}
+
+// dispatchLoop memeriksa antrean secara berkala dan menyalurkan pesan ke worker
+func (q *Queue) dispatchLoop() {
+	defer q.wg.Done()
+
+	ticker := time.NewTicker(pollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-q.ctx.Done():
+			return
+		case <-ticker.C:
+		case <-q.notify:
+		}
+
+		q.drain()
+	}
+}
+
+// drain menyalurkan semua pesan yang sudah jatuh tempo selama minimal satu akun WhatsApp terhubung
+func (q *Queue) drain() {
+	if !q.accounts.AnyConnected() {
+		return
+	}
+
+	ids, err := q.dueIDs(time.Now())
+	if err != nil {
+		q.logger.WithError(err).Error("Gagal membaca antrean pesan")
+		return
+	}
+
+	for _, id := range ids {
+		if !q.claim(id) {
+			continue
+		}
+
+		select {
+		case q.jobs <- id:
+		case <-q.ctx.Done():
+			q.release(id)
+			return
+		}
+	}
+}
+
+// dueIDs mengembalikan ID pesan yang sudah jatuh tempo, terurut dari yang paling lama.
+// Hanya indeks antrean yang ditelusuri, dan penelusuran berhenti pada pesan pertama yang
+// belum jatuh tempo.
+func (q *Queue) dueIDs(now time.Time) ([]string, error) {
+	var ids []string
+	opts := storage.IterateOptions{Prefix: "pending:", KeysOnly: true}
+	err := q.helper.Iterate(context.Background(), opts, func(key string, _ []byte) (bool, error) {
+		dueAt, id, ok := parsePendingKey(key)
+		if !ok {
+			return true, nil
+		}
+
+		// Key terurut berdasarkan waktu, sisanya belum jatuh tempo
+		if dueAt > now.UnixNano() {
+			return false, nil
+		}
+
+		ids = append(ids, id)
+		return true, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return ids, nil
+}
+
+// claim menandai pesan sedang diproses agar tidak disalurkan dua kali
+func (q *Queue) claim(id string) bool {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if _, busy := q.inflight[id]; busy {
+		return false
+	}
+	q.inflight[id] = struct{}{}
+	return true
+}
+
+// release melepas tanda pemrosesan pesan
+func (q *Queue) release(id string) {
+	q.mu.Lock()
+	delete(q.inflight, id)
+	q.mu.Unlock()
+}
+
+// worker mengambil pesan dari channel dan mengirimkannya
+func (q *Queue) worker() {
+	defer q.wg.Done()
+
+	for {
+		select {
+		case <-q.ctx.Done():
+			return
+		case id := <-q.jobs:
+			q.process(id)
+			q.release(id)
+		}
+	}
+}
+
+// process mengirim satu pesan dan memperbarui statusnya di storage
+func (q *Queue) process(id string) {
+	ctx := context.Background()
+
+	msg, err := q.getActive(id)
+	if errors.Is(err, ErrMessageNotFound) {
+		// Pesan sudah dipindahkan atau dihapus, bersihkan indeks yang tertinggal
+		q.removePending(id)
+		return
+	}
+	if err != nil {
+		q.logger.WithError(err).WithField("id", id).Error("Gagal memuat pesan dari antrean")
+		return
+	}
+
+	previousDue := msg.NextAttemptAt
+	if msg.Status == StatusSent || msg.Status == StatusCancelled {
+		// Pesan sudah terkirim atau dibatalkan, cukup bersihkan indeks antrean
+		q.helper.Delete(ctx, pendingKey(previousDue, msg.ID))
+		return
+	}
+
+	// Pesan untuk akun yang sedang terputus tetap menunggu di antrean tanpa
+	// menghabiskan jatah percobaan. Akun yang idle terhubung kembali saat mengirim,
+	// sedangkan akun yang sudah dihapus diproses sebagai gagal.
+	if wa, err := q.accounts.Sender(msg.Account); err == nil && !wa.CanSend() {
+		return
+	}
+
+	msg.Status = StatusSending
+	msg.Attempts++
+	msg.UpdatedAt = time.Now()
+	if err := q.helper.SetJSON(ctx, messageKey(msg.ID), msg); err != nil {
+		q.logger.WithError(err).WithField("id", msg.ID).Error("Gagal memperbarui status pesan")
+		return
+	}
+
+	waID, sendErr := q.send(msg)
+	now := time.Now()
+	msg.UpdatedAt = now
+
+	if sendErr == nil {
+		msg.Status = StatusSent
+		msg.WhatsAppID = waID
+		msg.LastError = ""
+		msg.LastErrorClass = ""
+		msg.SentAt = &now
+
+		if err := q.helper.SetJSON(ctx, messageKey(msg.ID), msg); err != nil {
+			q.logger.WithError(err).WithField("id", msg.ID).Error("Gagal menyimpan status pesan terkirim")
+		}
+		q.helper.Delete(ctx, pendingKey(previousDue, msg.ID))
+
+		q.logger.WithFields(utils.Fields{
+			"id":          msg.ID,
+			"whatsapp_id": msg.WhatsAppID,
+			"to":          msg.Recipient,
+			"attempts":    msg.Attempts,
+		}).Info("Pesan dari antrean berhasil dikirim")
+		return
+	}
+
+	class := ClassifyError(sendErr)
+	msg.LastError = sendErr.Error()
+	msg.LastErrorClass = class
+
+	// Pindahkan ke dead-letter queue jika error tidak bisa dicoba ulang atau percobaan habis
+	policy := q.policyFor(msg)
+	if !policy.Retryable(class) || msg.Attempts >= policy.MaxAttempts {
+		q.deadLetter(msg, previousDue)
+		return
+	}
+
+	// Gagal kirim, jadwalkan ulang pesan dengan exponential backoff
+	msg.Status = StatusQueued
+	msg.NextAttemptAt = now.Add(policy.Backoff(msg.Attempts))
+
+	if err := q.helper.SetJSON(ctx, messageKey(msg.ID), msg); err != nil {
+		q.logger.WithError(err).WithField("id", msg.ID).Error("Gagal menyimpan status pesan gagal")
+		return
+	}
+	if err := q.helper.SetJSON(ctx, pendingKey(msg.NextAttemptAt, msg.ID), msg.ID); err != nil {
+		q.logger.WithError(err).WithField("id", msg.ID).Error("Gagal menjadwalkan ulang pesan")
+		return
+	}
+	q.helper.Delete(ctx, pendingKey(previousDue, msg.ID))
+
+	q.logger.WithFields(utils.Fields{
+		"id":         msg.ID,
+		"to":         msg.Recipient,
+		"attempts":   msg.Attempts,
+		"next_retry": msg.NextAttemptAt,
+		"class":      class,
+		"error":      sendErr,
+	}).Warn("Gagal mengirim pesan dari antrean, akan dicoba lagi")
+}
+
+// policyFor mengembalikan kebijakan retry pesan, atau kebijakan default jika tidak ada
+func (q *Queue) policyFor(msg *OutboundMessage) RetryPolicy {
+	if msg.RetryPolicy == nil {
+		return q.DefaultRetryPolicy()
+	}
+	return msg.RetryPolicy.withDefaults()
+}
+
+// removePending menghapus semua indeks antrean milik pesan dengan ID tertentu
+func (q *Queue) removePending(id string) {
+	ctx := context.Background()
+
+	var keys []string
+	opts := storage.IterateOptions{Prefix: "pending:", KeysOnly: true}
+	err := q.helper.Iterate(ctx, opts, func(key string, _ []byte) (bool, error) {
+		if _, pendingID, ok := parsePendingKey(key); ok && pendingID == id {
+			keys = append(keys, key)
+		}
+		return true, nil
+	})
+	if err != nil {
+		q.logger.WithError(err).Warn("Gagal membaca indeks antrean")
+		return
+	}
+
+	for _, key := range keys {
+		q.helper.Delete(ctx, key)
+	}
+}
+
+// send mengirim pesan melalui klien WhatsApp akun pengirim dan mengembalikan ID pesan WhatsApp
+func (q *Queue) send(msg *OutboundMessage) (string, error) {
+	wa, err := q.accounts.Sender(msg.Account)
+	if err != nil {
+		return "", err
+	}
+
+	jid, err := types.ParseJID(msg.Recipient)
+	if err != nil {
+		return "", fmt.Errorf("%w: %v", errInvalidRecipient, err)
+	}
+
+	return wa.SendMessage(jid, msg.Message)
+}
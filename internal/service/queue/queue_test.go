@@ -0,0 +1,653 @@
+package queue
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/gwenziro/bot-notify/internal/config"
+	"github.com/gwenziro/bot-notify/internal/service/whatsapp/client"
+	"github.com/gwenziro/bot-notify/internal/storage"
+	"go.mau.fi/whatsmeow"
+	"go.mau.fi/whatsmeow/types"
+)
+
+// testRecipient adalah penerima pesan pada test
+var testRecipient = types.NewJID("628123456789", types.DefaultUserServer)
+
+// fakeSender adalah akun WhatsApp palsu yang gagal sesuai urutan errs
+type fakeSender struct {
+	canSend bool
+	errs    []error
+	calls   int
+}
+
+func (s *fakeSender) CanSend() bool {
+	return s.canSend
+}
+
+// SendMessage mengembalikan errs[n] pada percobaan ke-n, dan berhasil jika errs sudah habis
+func (s *fakeSender) SendMessage(recipient types.JID, message string) (types.MessageID, error) {
+	attempt := s.calls
+	s.calls++
+	if attempt < len(s.errs) && s.errs[attempt] != nil {
+		return "", s.errs[attempt]
+	}
+	return fmt.Sprintf("WA-%d", s.calls), nil
+}
+
+// fakeAccounts adalah daftar akun palsu berdasarkan nama
+type fakeAccounts map[string]*fakeSender
+
+func (a fakeAccounts) AnyConnected() bool {
+	for _, s := range a {
+		if s.CanSend() {
+			return true
+		}
+	}
+	return false
+}
+
+func (a fakeAccounts) Sender(name string) (sender, error) {
+	if name == "" {
+		name = client.DefaultAccount
+	}
+	s, ok := a[name]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", client.ErrAccountNotFound, name)
+	}
+	return s, nil
+}
+
+// netError adalah error jaringan palsu
+type netError struct {
+	timeout bool
+}
+
+func (e netError) Error() string   { return "koneksi terputus" }
+func (e netError) Timeout() bool   { return e.timeout }
+func (e netError) Temporary() bool { return false }
+
+// newTestStore membuat storage Badger in-memory
+func newTestStore(t *testing.T) storage.Storage {
+	t.Helper()
+	store, err := storage.NewBadgerStorage(storage.StorageOptions{InMemory: true})
+	if err != nil {
+		t.Fatalf("gagal membuat storage: %v", err)
+	}
+	t.Cleanup(func() { store.Close() })
+	return store
+}
+
+// newTestQueue membuat Queue dengan akun default palsu tanpa menjalankan worker
+func newTestQueue(t *testing.T, store storage.Storage, s *fakeSender) *Queue {
+	t.Helper()
+	cfg := &config.Config{WhatsApp: config.WhatsAppConfig{QueueRetention: 24 * time.Hour}}
+	return newQueue(store, fakeAccounts{client.DefaultAccount: s}, cfg)
+}
+
+// enqueueTestMessage memasukkan pesan ke antrean
+func enqueueTestMessage(t *testing.T, q *Queue, opts EnqueueOptions) *OutboundMessage {
+	t.Helper()
+	msg, err := q.Enqueue(TypePersonal, testRecipient, "halo", opts)
+	if err != nil {
+		t.Fatalf("gagal memasukkan pesan: %v", err)
+	}
+	return msg
+}
+
+// getTestMessage membaca pesan dari antrean atau dead-letter queue
+func getTestMessage(t *testing.T, q *Queue, id string) *OutboundMessage {
+	t.Helper()
+	msg, err := q.Get(id)
+	if err != nil {
+		t.Fatalf("gagal membaca pesan %s: %v", id, err)
+	}
+	return msg
+}
+
+// pendingKeys mengembalikan semua key indeks antrean secara berurutan
+func pendingKeys(t *testing.T, q *Queue) []string {
+	t.Helper()
+	var keys []string
+	opts := storage.IterateOptions{Prefix: "pending:", KeysOnly: true}
+	err := q.helper.Iterate(context.Background(), opts, func(key string, _ []byte) (bool, error) {
+		keys = append(keys, key)
+		return true, nil
+	})
+	if err != nil {
+		t.Fatalf("gagal membaca indeks antrean: %v", err)
+	}
+	return keys
+}
+
+// ids mengembalikan ID pesan secara berurutan
+func ids(messages []*OutboundMessage) []string {
+	out := make([]string, len(messages))
+	for i, msg := range messages {
+		out[i] = msg.ID
+	}
+	return out
+}
+
+func TestClassifyError(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want ErrorClass
+	}{
+		{"nil", nil, ""},
+		{"klien terputus", client.ErrNotConnected, ErrorClassNotConnected},
+		{"belum login dibungkus", fmt.Errorf("gagal mengirim: %w", whatsmeow.ErrNotLoggedIn), ErrorClassNotConnected},
+		{"iq timeout", whatsmeow.ErrIQTimedOut, ErrorClassTimeout},
+		{"deadline context", context.DeadlineExceeded, ErrorClassTimeout},
+		{"net timeout", netError{timeout: true}, ErrorClassTimeout},
+		{"net error", netError{}, ErrorClassNetwork},
+		{"eof", io.EOF, ErrorClassNetwork},
+		{"rate limit", whatsmeow.ErrIQRateOverLimit, ErrorClassRateLimit},
+		{"server error", whatsmeow.ErrServerReturnedError, ErrorClassServer},
+		{"iq 503", &whatsmeow.IQError{Code: 503, Text: "service-unavailable"}, ErrorClassServer},
+		{"jid tidak valid", fmt.Errorf("%w: jid kosong", errInvalidRecipient), ErrorClassInvalidRecipient},
+		{"server tidak dikenal", whatsmeow.ErrUnknownServer, ErrorClassInvalidRecipient},
+		{"lainnya", errors.New("error aneh"), ErrorClassUnknown},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := ClassifyError(tc.err); got != tc.want {
+				t.Errorf("ClassifyError(%v) = %q, ingin %q", tc.err, got, tc.want)
+			}
+		})
+	}
+}
+
+func TestRetryPolicyBackoff(t *testing.T) {
+	policy := RetryPolicy{InitialBackoff: 5 * time.Second, MaxBackoff: time.Minute}.withDefaults()
+
+	tests := []struct {
+		attempt int
+		want    time.Duration
+	}{
+		{0, 5 * time.Second},
+		{1, 5 * time.Second},
+		{2, 10 * time.Second},
+		{3, 20 * time.Second},
+		{4, 40 * time.Second},
+		{5, time.Minute},
+		{100, time.Minute},
+	}
+
+	for _, tc := range tests {
+		if got := policy.Backoff(tc.attempt); got != tc.want {
+			t.Errorf("Backoff(%d) = %v, ingin %v", tc.attempt, got, tc.want)
+		}
+	}
+}
+
+func TestProcessRetryClasses(t *testing.T) {
+	tests := []struct {
+		name      string
+		err       error
+		policy    *RetryPolicy
+		wantClass ErrorClass
+		wantRetry bool
+	}{
+		{"not_connected", client.ErrNotConnected, nil, ErrorClassNotConnected, true},
+		{"timeout", whatsmeow.ErrIQTimedOut, nil, ErrorClassTimeout, true},
+		{"network", io.EOF, nil, ErrorClassNetwork, true},
+		{"rate_limit", whatsmeow.ErrIQRateOverLimit, nil, ErrorClassRateLimit, true},
+		{"server", whatsmeow.ErrServerReturnedError, nil, ErrorClassServer, true},
+		{"invalid_recipient", whatsmeow.ErrUnknownServer, nil, ErrorClassInvalidRecipient, false},
+		{"unknown", errors.New("error aneh"), nil, ErrorClassUnknown, false},
+		{
+			name:      "unknown dengan kebijakan khusus",
+			err:       errors.New("error aneh"),
+			policy:    &RetryPolicy{RetryOn: []ErrorClass{ErrorClassUnknown}},
+			wantClass: ErrorClassUnknown,
+			wantRetry: true,
+		},
+		{
+			name:      "timeout di luar kebijakan khusus",
+			err:       whatsmeow.ErrIQTimedOut,
+			policy:    &RetryPolicy{RetryOn: []ErrorClass{ErrorClassNetwork}},
+			wantClass: ErrorClassTimeout,
+			wantRetry: false,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			s := &fakeSender{canSend: true, errs: []error{tc.err}}
+			q := newTestQueue(t, newTestStore(t), s)
+			msg := enqueueTestMessage(t, q, EnqueueOptions{RetryPolicy: tc.policy})
+
+			q.process(msg.ID)
+
+			got := getTestMessage(t, q, msg.ID)
+			if s.calls != 1 {
+				t.Errorf("jumlah pengiriman = %d, ingin 1", s.calls)
+			}
+			if got.Attempts != 1 {
+				t.Errorf("Attempts = %d, ingin 1", got.Attempts)
+			}
+			if got.LastErrorClass != tc.wantClass {
+				t.Errorf("LastErrorClass = %q, ingin %q", got.LastErrorClass, tc.wantClass)
+			}
+
+			if !tc.wantRetry {
+				if got.Status != StatusDead || got.DeadAt == nil {
+					t.Errorf("Status = %q, ingin %q dengan DeadAt", got.Status, StatusDead)
+				}
+				if _, err := q.getActive(msg.ID); !errors.Is(err, ErrMessageNotFound) {
+					t.Errorf("pesan masih ada di antrean utama: %v", err)
+				}
+				if keys := pendingKeys(t, q); len(keys) != 0 {
+					t.Errorf("indeks antrean = %v, ingin kosong", keys)
+				}
+				return
+			}
+
+			if got.Status != StatusQueued {
+				t.Errorf("Status = %q, ingin %q", got.Status, StatusQueued)
+			}
+			if backoff := got.NextAttemptAt.Sub(got.UpdatedAt); backoff != defaultInitialBackoff {
+				t.Errorf("jeda percobaan berikutnya = %v, ingin %v", backoff, defaultInitialBackoff)
+			}
+			want := []string{pendingKey(got.NextAttemptAt, msg.ID)}
+			if keys := pendingKeys(t, q); !reflect.DeepEqual(keys, want) {
+				t.Errorf("indeks antrean = %v, ingin %v", keys, want)
+			}
+		})
+	}
+}
+
+func TestProcessDeadLetterAfterMaxAttempts(t *testing.T) {
+	timeout := whatsmeow.ErrIQTimedOut
+	s := &fakeSender{canSend: true, errs: []error{timeout, timeout, timeout, timeout}}
+	q := newTestQueue(t, newTestStore(t), s)
+	policy := &RetryPolicy{MaxAttempts: 3, InitialBackoff: time.Second, MaxBackoff: time.Minute}
+	msg := enqueueTestMessage(t, q, EnqueueOptions{RetryPolicy: policy})
+
+	// Dua percobaan pertama dijadwalkan ulang dengan jeda yang berlipat
+	for attempt, want := range []time.Duration{time.Second, 2 * time.Second} {
+		q.process(msg.ID)
+
+		got := getTestMessage(t, q, msg.ID)
+		if got.Status != StatusQueued || got.Attempts != attempt+1 {
+			t.Fatalf("percobaan %d: Status = %q, Attempts = %d", attempt+1, got.Status, got.Attempts)
+		}
+		if backoff := got.NextAttemptAt.Sub(got.UpdatedAt); backoff != want {
+			t.Errorf("percobaan %d: jeda = %v, ingin %v", attempt+1, backoff, want)
+		}
+	}
+
+	q.process(msg.ID)
+
+	got := getTestMessage(t, q, msg.ID)
+	if got.Status != StatusDead || got.Attempts != 3 {
+		t.Fatalf("Status = %q, Attempts = %d, ingin %q setelah 3 percobaan", got.Status, got.Attempts, StatusDead)
+	}
+	if got.LastErrorClass != ErrorClassTimeout || got.LastError != timeout.Error() {
+		t.Errorf("error terakhir = %q (%q), ingin %q", got.LastError, got.LastErrorClass, timeout.Error())
+	}
+	if keys := pendingKeys(t, q); len(keys) != 0 {
+		t.Errorf("indeks antrean = %v, ingin kosong", keys)
+	}
+
+	dead, err := q.ListDeadLetters()
+	if err != nil {
+		t.Fatalf("gagal membaca dead-letter queue: %v", err)
+	}
+	if !reflect.DeepEqual(ids(dead), []string{msg.ID}) {
+		t.Errorf("dead-letter queue = %v, ingin [%s]", ids(dead), msg.ID)
+	}
+
+	// Pesan di dead-letter queue tidak diproses lagi
+	q.process(msg.ID)
+	if s.calls != 3 {
+		t.Errorf("jumlah pengiriman = %d, ingin 3", s.calls)
+	}
+}
+
+func TestProcessSendsAfterRetry(t *testing.T) {
+	s := &fakeSender{canSend: true, errs: []error{io.EOF}}
+	q := newTestQueue(t, newTestStore(t), s)
+	msg := enqueueTestMessage(t, q, EnqueueOptions{})
+
+	q.process(msg.ID)
+	q.process(msg.ID)
+
+	got := getTestMessage(t, q, msg.ID)
+	if got.Status != StatusSent || got.SentAt == nil {
+		t.Fatalf("Status = %q, ingin %q dengan SentAt", got.Status, StatusSent)
+	}
+	if got.Attempts != 2 || got.WhatsAppID != "WA-2" {
+		t.Errorf("Attempts = %d, WhatsAppID = %q, ingin 2 dan WA-2", got.Attempts, got.WhatsAppID)
+	}
+	if got.LastError != "" || got.LastErrorClass != "" {
+		t.Errorf("error terakhir tidak dihapus: %q (%q)", got.LastError, got.LastErrorClass)
+	}
+	if keys := pendingKeys(t, q); len(keys) != 0 {
+		t.Errorf("indeks antrean = %v, ingin kosong", keys)
+	}
+}
+
+func TestProcessWaitsWhileDisconnected(t *testing.T) {
+	s := &fakeSender{}
+	q := newTestQueue(t, newTestStore(t), s)
+	msg := enqueueTestMessage(t, q, EnqueueOptions{})
+
+	q.process(msg.ID)
+
+	got := getTestMessage(t, q, msg.ID)
+	if s.calls != 0 {
+		t.Errorf("jumlah pengiriman = %d, ingin 0", s.calls)
+	}
+	if got.Status != StatusQueued || got.Attempts != 0 {
+		t.Errorf("Status = %q, Attempts = %d, ingin %q tanpa percobaan", got.Status, got.Attempts, StatusQueued)
+	}
+	want := []string{pendingKey(msg.NextAttemptAt, msg.ID)}
+	if keys := pendingKeys(t, q); !reflect.DeepEqual(keys, want) {
+		t.Errorf("indeks antrean = %v, ingin %v", keys, want)
+	}
+}
+
+func TestProcessUnknownAccount(t *testing.T) {
+	s := &fakeSender{canSend: true}
+	q := newTestQueue(t, newTestStore(t), s)
+	msg := enqueueTestMessage(t, q, EnqueueOptions{Account: "dihapus"})
+
+	q.process(msg.ID)
+
+	got := getTestMessage(t, q, msg.ID)
+	if got.Status != StatusDead {
+		t.Errorf("Status = %q, ingin %q", got.Status, StatusDead)
+	}
+	if s.calls != 0 {
+		t.Errorf("pesan terkirim melalui akun default sebanyak %d kali", s.calls)
+	}
+}
+
+func TestProcessResendsStuckSending(t *testing.T) {
+	store := newTestStore(t)
+	before := newTestQueue(t, store, &fakeSender{canSend: true})
+	msg := enqueueTestMessage(t, before, EnqueueOptions{})
+
+	// Proses berhenti saat pesan sedang dikirim, indeks antrean masih tersimpan
+	msg.Status = StatusSending
+	msg.Attempts = 1
+	if err := before.helper.SetJSON(context.Background(), messageKey(msg.ID), msg); err != nil {
+		t.Fatalf("gagal menyimpan pesan: %v", err)
+	}
+
+	s := &fakeSender{canSend: true}
+	q := newTestQueue(t, store, s)
+
+	due, err := q.dueIDs(time.Now())
+	if err != nil {
+		t.Fatalf("gagal membaca antrean: %v", err)
+	}
+	if !reflect.DeepEqual(due, []string{msg.ID}) {
+		t.Fatalf("dueIDs = %v, ingin [%s]", due, msg.ID)
+	}
+
+	q.process(msg.ID)
+
+	got := getTestMessage(t, q, msg.ID)
+	if got.Status != StatusSent || got.Attempts != 2 {
+		t.Errorf("Status = %q, Attempts = %d, ingin %q setelah 2 percobaan", got.Status, got.Attempts, StatusSent)
+	}
+	if s.calls != 1 {
+		t.Errorf("jumlah pengiriman = %d, ingin 1", s.calls)
+	}
+}
+
+func TestDueIDs(t *testing.T) {
+	q := newTestQueue(t, newTestStore(t), &fakeSender{canSend: true})
+	now := time.Now()
+
+	later := enqueueTestMessage(t, q, EnqueueOptions{SendAt: now.Add(3 * time.Hour)})
+	soon := enqueueTestMessage(t, q, EnqueueOptions{SendAt: now.Add(time.Hour)})
+	cancelled := enqueueTestMessage(t, q, EnqueueOptions{SendAt: now.Add(90 * time.Minute)})
+	middle := enqueueTestMessage(t, q, EnqueueOptions{SendAt: now.Add(2 * time.Hour)})
+	immediate := enqueueTestMessage(t, q, EnqueueOptions{})
+
+	if _, err := q.Cancel(cancelled.ID); err != nil {
+		t.Fatalf("gagal membatalkan pesan: %v", err)
+	}
+
+	// Key yang tidak bisa diparse dilewati
+	if err := q.helper.SetJSON(context.Background(), "pending:rusak", "x"); err != nil {
+		t.Fatalf("gagal menyimpan key: %v", err)
+	}
+
+	tests := []struct {
+		name string
+		at   time.Time
+		want []string
+	}{
+		{"sebelum semua", now.Add(-time.Minute), nil},
+		{"hanya pesan langsung", now.Add(time.Second), []string{immediate.ID}},
+		{"tepat jatuh tempo", now.Add(time.Hour), []string{immediate.ID, soon.ID}},
+		{"sebagian terjadwal", now.Add(150 * time.Minute), []string{immediate.ID, soon.ID, middle.ID}},
+		{"semua", now.Add(4 * time.Hour), []string{immediate.ID, soon.ID, middle.ID, later.ID}},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := q.dueIDs(tc.at)
+			if err != nil {
+				t.Fatalf("gagal membaca antrean: %v", err)
+			}
+			if !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("dueIDs = %v, ingin %v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestListScheduled(t *testing.T) {
+	q := newTestQueue(t, newTestStore(t), &fakeSender{canSend: true})
+	ctx := context.Background()
+	now := time.Now()
+
+	second := enqueueTestMessage(t, q, EnqueueOptions{SendAt: now.Add(2 * time.Hour)})
+	first := enqueueTestMessage(t, q, EnqueueOptions{SendAt: now.Add(time.Hour)})
+	enqueueTestMessage(t, q, EnqueueOptions{})
+
+	// Indeks ganda dari jadwal yang sedang diubah dan indeks tanpa data pesan diabaikan
+	if err := q.helper.SetJSON(ctx, pendingKey(now.Add(3*time.Hour), first.ID), first.ID); err != nil {
+		t.Fatalf("gagal menyimpan indeks: %v", err)
+	}
+	if err := q.helper.SetJSON(ctx, pendingKey(now.Add(30*time.Minute), "hilang"), "hilang"); err != nil {
+		t.Fatalf("gagal menyimpan indeks: %v", err)
+	}
+
+	got, err := q.ListScheduled()
+	if err != nil {
+		t.Fatalf("gagal membaca pesan terjadwal: %v", err)
+	}
+	want := []string{first.ID, second.ID}
+	if !reflect.DeepEqual(ids(got), want) {
+		t.Errorf("ListScheduled = %v, ingin %v", ids(got), want)
+	}
+}
+
+func TestReschedule(t *testing.T) {
+	s := &fakeSender{canSend: true}
+	q := newTestQueue(t, newTestStore(t), s)
+	now := time.Now()
+	msg := enqueueTestMessage(t, q, EnqueueOptions{SendAt: now.Add(2 * time.Hour)})
+
+	sendAt := now.Add(30 * time.Minute)
+	updated, err := q.Reschedule(msg.ID, sendAt)
+	if err != nil {
+		t.Fatalf("gagal mengubah jadwal: %v", err)
+	}
+	if !updated.NextAttemptAt.Equal(sendAt) || updated.ScheduledAt == nil || !updated.ScheduledAt.Equal(sendAt) {
+		t.Errorf("jadwal = %v (%v), ingin %v", updated.NextAttemptAt, updated.ScheduledAt, sendAt)
+	}
+
+	got := getTestMessage(t, q, msg.ID)
+	if got.Status != StatusScheduled || !got.NextAttemptAt.Equal(sendAt) {
+		t.Errorf("pesan tersimpan = %q pada %v, ingin %q pada %v", got.Status, got.NextAttemptAt, StatusScheduled, sendAt)
+	}
+	want := []string{pendingKey(sendAt, msg.ID)}
+	if keys := pendingKeys(t, q); !reflect.DeepEqual(keys, want) {
+		t.Errorf("indeks antrean = %v, ingin %v", keys, want)
+	}
+
+	// Jadwal yang dimajukan ke masa lalu langsung jatuh tempo dan terkirim
+	if _, err := q.Reschedule(msg.ID, now.Add(-time.Minute)); err != nil {
+		t.Fatalf("gagal mengubah jadwal: %v", err)
+	}
+	due, err := q.dueIDs(time.Now())
+	if err != nil {
+		t.Fatalf("gagal membaca antrean: %v", err)
+	}
+	if !reflect.DeepEqual(due, []string{msg.ID}) {
+		t.Fatalf("dueIDs = %v, ingin [%s]", due, msg.ID)
+	}
+	q.process(msg.ID)
+	if got := getTestMessage(t, q, msg.ID); got.Status != StatusSent {
+		t.Errorf("Status = %q, ingin %q", got.Status, StatusSent)
+	}
+}
+
+func TestRescheduleAndCancelErrors(t *testing.T) {
+	q := newTestQueue(t, newTestStore(t), &fakeSender{canSend: true})
+	now := time.Now()
+	busy := enqueueTestMessage(t, q, EnqueueOptions{SendAt: now.Add(time.Hour)})
+	immediate := enqueueTestMessage(t, q, EnqueueOptions{})
+
+	cancelled := enqueueTestMessage(t, q, EnqueueOptions{SendAt: now.Add(time.Hour)})
+	if _, err := q.Cancel(cancelled.ID); err != nil {
+		t.Fatalf("gagal membatalkan pesan: %v", err)
+	}
+
+	// Pesan yang sedang diproses worker tidak boleh diubah
+	q.claim(busy.ID)
+	defer q.release(busy.ID)
+
+	operations := map[string]func(id string) (*OutboundMessage, error){
+		"reschedule": func(id string) (*OutboundMessage, error) { return q.Reschedule(id, now.Add(2*time.Hour)) },
+		"cancel":     q.Cancel,
+	}
+
+	tests := []struct {
+		name string
+		id   string
+		want error
+	}{
+		{"tidak ada", "tidak-ada", ErrMessageNotFound},
+		{"pesan langsung", immediate.ID, ErrNotScheduled},
+		{"sudah dibatalkan", cancelled.ID, ErrNotScheduled},
+		{"sedang diproses", busy.ID, ErrMessageBusy},
+	}
+
+	for opName, op := range operations {
+		for _, tc := range tests {
+			t.Run(opName+"/"+tc.name, func(t *testing.T) {
+				_, err := op(tc.id)
+				if !errors.Is(err, tc.want) {
+					t.Errorf("error = %v, ingin %v", err, tc.want)
+				}
+			})
+		}
+	}
+}
+
+func TestCancel(t *testing.T) {
+	s := &fakeSender{canSend: true}
+	q := newTestQueue(t, newTestStore(t), s)
+	msg := enqueueTestMessage(t, q, EnqueueOptions{SendAt: time.Now().Add(time.Hour)})
+
+	cancelled, err := q.Cancel(msg.ID)
+	if err != nil {
+		t.Fatalf("gagal membatalkan pesan: %v", err)
+	}
+	if cancelled.Status != StatusCancelled {
+		t.Errorf("Status = %q, ingin %q", cancelled.Status, StatusCancelled)
+	}
+	if got := getTestMessage(t, q, msg.ID); got.Status != StatusCancelled {
+		t.Errorf("pesan tersimpan berstatus %q, ingin %q", got.Status, StatusCancelled)
+	}
+	if keys := pendingKeys(t, q); len(keys) != 0 {
+		t.Errorf("indeks antrean = %v, ingin kosong", keys)
+	}
+
+	scheduled, err := q.ListScheduled()
+	if err != nil {
+		t.Fatalf("gagal membaca pesan terjadwal: %v", err)
+	}
+	if len(scheduled) != 0 {
+		t.Errorf("ListScheduled = %v, ingin kosong", ids(scheduled))
+	}
+
+	// Worker yang sudah menerima ID sebelum pembatalan tidak mengirim pesan
+	q.process(msg.ID)
+	if s.calls != 0 {
+		t.Errorf("pesan yang dibatalkan terkirim %d kali", s.calls)
+	}
+}
+
+func TestPrune(t *testing.T) {
+	now := time.Now()
+	old := now.Add(-48 * time.Hour)
+	recent := now.Add(-time.Hour)
+
+	stored := []struct {
+		key    string
+		status MessageStatus
+		at     time.Time
+		pruned bool
+	}{
+		{messageKey("terkirim-lama"), StatusSent, old, true},
+		{messageKey("terkirim-baru"), StatusSent, recent, false},
+		{messageKey("dibatalkan-lama"), StatusCancelled, old, true},
+		{messageKey("menunggu-lama"), StatusQueued, old, false},
+		{messageKey("terjadwal-lama"), StatusScheduled, old, false},
+		{messageKey("dikirim-lama"), StatusSending, old, false},
+		{deadKey("gagal-lama"), StatusDead, old, true},
+		{deadKey("gagal-baru"), StatusDead, recent, false},
+	}
+
+	for _, retention := range []time.Duration{24 * time.Hour, 0} {
+		t.Run(retention.String(), func(t *testing.T) {
+			q := newTestQueue(t, newTestStore(t), &fakeSender{})
+			q.SetRetention(retention)
+
+			ctx := context.Background()
+			wantPruned := 0
+			for _, s := range stored {
+				msg := &OutboundMessage{ID: s.key, Status: s.status, UpdatedAt: s.at}
+				if err := q.helper.SetJSON(ctx, s.key, msg); err != nil {
+					t.Fatalf("gagal menyimpan pesan: %v", err)
+				}
+				if s.pruned && retention > 0 {
+					wantPruned++
+				}
+			}
+
+			pruned, err := q.Prune(now)
+			if err != nil {
+				t.Fatalf("gagal menghapus pesan lama: %v", err)
+			}
+			if pruned != wantPruned {
+				t.Errorf("jumlah dihapus = %d, ingin %d", pruned, wantPruned)
+			}
+
+			for _, s := range stored {
+				var msg OutboundMessage
+				err := q.helper.GetJSON(ctx, s.key, &msg)
+				if exists := err == nil; exists == (s.pruned && retention > 0) {
+					t.Errorf("%s: tersimpan = %v, error = %v", s.key, exists, err)
+				}
+			}
+		})
+	}
+}
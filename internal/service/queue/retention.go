@@ -0,0 +1,99 @@
+package queue
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/gwenziro/bot-notify/internal/storage"
+	"github.com/gwenziro/bot-notify/internal/utils"
+)
+
+// pruneInterval adalah jarak antar penghapusan pesan lama yang sudah selesai
+const pruneInterval = time.Hour
+
+// Retention mengembalikan lama pesan yang sudah selesai disimpan, 0 berarti selamanya
+func (q *Queue) Retention() time.Duration {
+	q.policyMu.RLock()
+	defer q.policyMu.RUnlock()
+	return q.retention
+}
+
+// SetRetention mengganti lama penyimpanan pesan yang sudah selesai, misalnya setelah konfigurasi di-reload
+func (q *Queue) SetRetention(retention time.Duration) {
+	q.policyMu.Lock()
+	q.retention = retention
+	q.policyMu.Unlock()
+
+	q.logger.WithField("retention", retention).Info("Lama penyimpanan pesan antrean diperbarui")
+}
+
+// pruneLoop menghapus pesan lama yang sudah selesai secara berkala
+func (q *Queue) pruneLoop() {
+	defer q.wg.Done()
+
+	ticker := time.NewTicker(pruneInterval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := q.Prune(time.Now()); err != nil {
+			q.logger.WithError(err).Error("Gagal menghapus pesan antrean lama")
+		}
+
+		select {
+		case <-q.ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Prune menghapus pesan terkirim, dibatalkan, dan pesan di dead-letter queue yang terakhir
+// diperbarui lebih lama dari batas penyimpanan. Mengembalikan jumlah pesan yang dihapus.
+func (q *Queue) Prune(now time.Time) (int, error) {
+	retention := q.Retention()
+	if retention <= 0 {
+		return 0, nil
+	}
+	cutoff := now.Add(-retention)
+	ctx := context.Background()
+
+	var keys []string
+	collect := func(prefix string, final func(msg *OutboundMessage) bool) error {
+		return q.helper.Iterate(ctx, storage.IterateOptions{Prefix: prefix}, func(key string, value []byte) (bool, error) {
+			var msg OutboundMessage
+			if err := json.Unmarshal(value, &msg); err != nil {
+				return true, nil
+			}
+			if final(&msg) && msg.UpdatedAt.Before(cutoff) {
+				keys = append(keys, key)
+			}
+			return true, nil
+		})
+	}
+
+	// Pesan yang masih menunggu atau sedang dikirim tidak pernah dihapus
+	err := collect("msg:", func(msg *OutboundMessage) bool {
+		return msg.Status == StatusSent || msg.Status == StatusCancelled
+	})
+	if err != nil {
+		return 0, fmt.Errorf("gagal membaca antrean: %w", err)
+	}
+	if err := collect("dead:", func(*OutboundMessage) bool { return true }); err != nil {
+		return 0, fmt.Errorf("gagal membaca dead-letter queue: %w", err)
+	}
+
+	if len(keys) == 0 {
+		return 0, nil
+	}
+	if err := q.helper.DeleteBatch(ctx, keys); err != nil {
+		return 0, fmt.Errorf("gagal menghapus pesan antrean lama: %w", err)
+	}
+
+	q.logger.WithFields(utils.Fields{
+		"jumlah":    len(keys),
+		"retention": retention,
+	}).Info("Pesan antrean lama dihapus")
+	return len(keys), nil
+}
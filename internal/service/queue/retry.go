@@ -0,0 +1,195 @@
+package queue
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"time"
+
+	"github.com/gwenziro/bot-notify/internal/config"
+	"github.com/gwenziro/bot-notify/internal/service/whatsapp/client"
+	"go.mau.fi/whatsmeow"
+)
+
+// ErrorClass mengelompokkan error pengiriman untuk menentukan apakah pesan perlu dicoba ulang
+type ErrorClass string
+
+const (
+	ErrorClassNotConnected     ErrorClass = "not_connected"
+	ErrorClassTimeout          ErrorClass = "timeout"
+	ErrorClassNetwork          ErrorClass = "network"
+	ErrorClassRateLimit        ErrorClass = "rate_limit"
+	ErrorClassServer           ErrorClass = "server"
+	ErrorClassInvalidRecipient ErrorClass = "invalid_recipient"
+	ErrorClassUnknown          ErrorClass = "unknown"
+)
+
+// errInvalidRecipient dikembalikan ketika JID penerima di antrean tidak dapat diparse
+var errInvalidRecipient = errors.New("penerima tidak valid")
+
+// Nilai default kebijakan retry jika tidak diatur di konfigurasi
+const (
+	defaultMaxAttempts    = 5
+	defaultInitialBackoff = 5 * time.Second
+	defaultMaxBackoff     = 5 * time.Minute
+)
+
+// defaultRetryOn adalah kelas error yang dicoba ulang secara default
+var defaultRetryOn = []ErrorClass{
+	ErrorClassNotConnected,
+	ErrorClassTimeout,
+	ErrorClassNetwork,
+	ErrorClassRateLimit,
+	ErrorClassServer,
+}
+
+// RetryPolicy mengatur berapa kali dan seberapa cepat pesan yang gagal dicoba ulang
+type RetryPolicy struct {
+	MaxAttempts    int           `json:"max_attempts"`
+	InitialBackoff time.Duration `json:"initial_backoff"`
+	MaxBackoff     time.Duration `json:"max_backoff"`
+	RetryOn        []ErrorClass  `json:"retry_on"`
+}
+
+// NewRetryPolicy membuat kebijakan retry dari konfigurasi, dengan nilai default untuk field kosong
+func NewRetryPolicy(cfg config.SendRetryConfig) (RetryPolicy, error) {
+	policy := RetryPolicy{
+		MaxAttempts:    cfg.MaxAttempts,
+		InitialBackoff: cfg.InitialBackoff,
+		MaxBackoff:     cfg.MaxBackoff,
+	}
+
+	if len(cfg.RetryOn) > 0 {
+		classes, err := ParseErrorClasses(cfg.RetryOn)
+		if err != nil {
+			return RetryPolicy{}, err
+		}
+		policy.RetryOn = classes
+	}
+
+	return policy.withDefaults(), nil
+}
+
+// withDefaults mengisi field kebijakan yang kosong dengan nilai default
+func (p RetryPolicy) withDefaults() RetryPolicy {
+	if p.MaxAttempts <= 0 {
+		p.MaxAttempts = defaultMaxAttempts
+	}
+	if p.InitialBackoff <= 0 {
+		p.InitialBackoff = defaultInitialBackoff
+	}
+	if p.MaxBackoff <= 0 {
+		p.MaxBackoff = defaultMaxBackoff
+	}
+	if p.MaxBackoff < p.InitialBackoff {
+		p.MaxBackoff = p.InitialBackoff
+	}
+	if p.RetryOn == nil {
+		p.RetryOn = append([]ErrorClass(nil), defaultRetryOn...)
+	}
+	return p
+}
+
+// Backoff menghitung jeda sebelum percobaan berikutnya dengan exponential backoff
+func (p RetryPolicy) Backoff(attempt int) time.Duration {
+	if attempt < 1 {
+		attempt = 1
+	}
+
+	delay := p.InitialBackoff
+	for i := 1; i < attempt; i++ {
+		delay *= 2
+		// Hentikan lebih awal agar tidak overflow
+		if delay >= p.MaxBackoff || delay <= 0 {
+			return p.MaxBackoff
+		}
+	}
+
+	if delay > p.MaxBackoff {
+		return p.MaxBackoff
+	}
+	return delay
+}
+
+// Retryable memeriksa apakah kelas error termasuk yang boleh dicoba ulang
+func (p RetryPolicy) Retryable(class ErrorClass) bool {
+	for _, c := range p.RetryOn {
+		if c == class {
+			return true
+		}
+	}
+	return false
+}
+
+// ParseErrorClasses memvalidasi dan mengkonversi daftar nama kelas error
+func ParseErrorClasses(names []string) ([]ErrorClass, error) {
+	classes := make([]ErrorClass, 0, len(names))
+	for _, name := range names {
+		class := ErrorClass(name)
+		switch class {
+		case ErrorClassNotConnected, ErrorClassTimeout, ErrorClassNetwork, ErrorClassRateLimit,
+			ErrorClassServer, ErrorClassInvalidRecipient, ErrorClassUnknown:
+			classes = append(classes, class)
+		default:
+			return nil, fmt.Errorf("kelas error tidak dikenal: %s", name)
+		}
+	}
+	return classes, nil
+}
+
+// ClassifyError menentukan kelas dari error pengiriman
+func ClassifyError(err error) ErrorClass {
+	if err == nil {
+		return ""
+	}
+
+	switch {
+	case errors.Is(err, client.ErrNotConnected),
+		errors.Is(err, whatsmeow.ErrNotConnected),
+		errors.Is(err, whatsmeow.ErrNotLoggedIn):
+		return ErrorClassNotConnected
+
+	case errors.Is(err, whatsmeow.ErrIQTimedOut),
+		errors.Is(err, whatsmeow.ErrMessageTimedOut),
+		errors.Is(err, context.DeadlineExceeded):
+		return ErrorClassTimeout
+
+	case errors.Is(err, whatsmeow.ErrIQRateOverLimit),
+		errors.Is(err, whatsmeow.ErrIQResourceLimit):
+		return ErrorClassRateLimit
+
+	case errors.Is(err, errInvalidRecipient),
+		errors.Is(err, whatsmeow.ErrUnknownServer),
+		errors.Is(err, whatsmeow.ErrRecipientADJID),
+		errors.Is(err, whatsmeow.ErrBroadcastListUnsupported),
+		errors.Is(err, whatsmeow.ErrNotInGroup),
+		errors.Is(err, whatsmeow.ErrGroupNotFound),
+		errors.Is(err, whatsmeow.ErrIQNotFound):
+		return ErrorClassInvalidRecipient
+
+	case errors.Is(err, whatsmeow.ErrServerReturnedError):
+		return ErrorClassServer
+	}
+
+	// Error info query dengan kode 5xx dianggap gangguan sisi server
+	var iqErr *whatsmeow.IQError
+	if errors.As(err, &iqErr) && iqErr.Code >= 500 {
+		return ErrorClassServer
+	}
+
+	var netErr net.Error
+	if errors.As(err, &netErr) {
+		if netErr.Timeout() {
+			return ErrorClassTimeout
+		}
+		return ErrorClassNetwork
+	}
+
+	if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+		return ErrorClassNetwork
+	}
+
+	return ErrorClassUnknown
+}
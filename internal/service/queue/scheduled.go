@@ -0,0 +1,137 @@
+package queue
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/gwenziro/bot-notify/internal/storage"
+	"github.com/gwenziro/bot-notify/internal/utils"
+)
+
+var (
+	// ErrNotScheduled dikembalikan ketika pesan tidak lagi berstatus terjadwal
+	ErrNotScheduled = errors.New("pesan tidak dalam status terjadwal")
+
+	// ErrMessageBusy dikembalikan ketika pesan sedang diproses oleh worker
+	ErrMessageBusy = errors.New("pesan sedang diproses")
+)
+
+// ListScheduled mengembalikan pesan terjadwal yang belum dikirim, terurut dari waktu kirim terdekat.
+// Pesan dicari melalui indeks antrean, sehingga pesan yang sudah selesai tidak ikut dibaca.
+func (q *Queue) ListScheduled() ([]*OutboundMessage, error) {
+	var ids []string
+	seen := make(map[string]bool)
+	opts := storage.IterateOptions{Prefix: "pending:", KeysOnly: true}
+	err := q.helper.Iterate(context.Background(), opts, func(key string, _ []byte) (bool, error) {
+		// Pesan bisa sesaat memiliki dua indeks ketika jadwalnya sedang diubah
+		if _, id, ok := parsePendingKey(key); ok && !seen[id] {
+			seen[id] = true
+			ids = append(ids, id)
+		}
+		return true, nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("gagal membaca antrean: %w", err)
+	}
+
+	messages := make([]*OutboundMessage, 0)
+	for _, id := range ids {
+		msg, err := q.getActive(id)
+		if errors.Is(err, ErrMessageNotFound) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		if msg.Status == StatusScheduled {
+			messages = append(messages, msg)
+		}
+	}
+
+	// Indeks sudah terurut, urutkan ulang berdasarkan data pesan untuk indeks yang tertinggal
+	sort.SliceStable(messages, func(i, j int) bool {
+		return messages[i].NextAttemptAt.Before(messages[j].NextAttemptAt)
+	})
+
+	return messages, nil
+}
+
+// Reschedule mengubah waktu kirim pesan terjadwal
+func (q *Queue) Reschedule(id string, sendAt time.Time) (*OutboundMessage, error) {
+	if !q.claim(id) {
+		return nil, ErrMessageBusy
+	}
+	defer q.release(id)
+
+	msg, err := q.scheduledMessage(id)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx := context.Background()
+	previousDue := msg.NextAttemptAt
+
+	msg.NextAttemptAt = sendAt
+	msg.ScheduledAt = &sendAt
+	msg.UpdatedAt = time.Now()
+
+	// Tulis indeks baru sebelum menghapus yang lama agar pesan tidak pernah hilang dari antrean
+	if err := q.helper.SetJSON(ctx, messageKey(msg.ID), msg); err != nil {
+		return nil, fmt.Errorf("gagal menyimpan jadwal pesan: %w", err)
+	}
+	if err := q.helper.SetJSON(ctx, pendingKey(msg.NextAttemptAt, msg.ID), msg.ID); err != nil {
+		return nil, fmt.Errorf("gagal mendaftarkan jadwal pesan: %w", err)
+	}
+	if !previousDue.Equal(msg.NextAttemptAt) {
+		q.helper.Delete(ctx, pendingKey(previousDue, msg.ID))
+	}
+
+	q.logger.WithFields(utils.Fields{
+		"id":      msg.ID,
+		"send_at": sendAt,
+	}).Info("Jadwal pesan diubah")
+
+	q.wake()
+	return msg, nil
+}
+
+// Cancel membatalkan pesan terjadwal sehingga tidak akan dikirim
+func (q *Queue) Cancel(id string) (*OutboundMessage, error) {
+	if !q.claim(id) {
+		return nil, ErrMessageBusy
+	}
+	defer q.release(id)
+
+	msg, err := q.scheduledMessage(id)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx := context.Background()
+	msg.Status = StatusCancelled
+	msg.UpdatedAt = time.Now()
+
+	// Data pesan tetap disimpan agar statusnya masih bisa dilihat
+	if err := q.helper.SetJSON(ctx, messageKey(msg.ID), msg); err != nil {
+		return nil, fmt.Errorf("gagal membatalkan pesan: %w", err)
+	}
+	q.helper.Delete(ctx, pendingKey(msg.NextAttemptAt, msg.ID))
+
+	q.logger.WithField("id", msg.ID).Info("Pesan terjadwal dibatalkan")
+	return msg, nil
+}
+
+// scheduledMessage mengambil pesan dan memastikan statusnya masih terjadwal
+func (q *Queue) scheduledMessage(id string) (*OutboundMessage, error) {
+	msg, err := q.getActive(id)
+	if err != nil {
+		return nil, err
+	}
+	if msg.Status != StatusScheduled {
+		return nil, ErrNotScheduled
+	}
+	return msg, nil
+}
@@ -0,0 +1,186 @@
+package receipt
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/gwenziro/bot-notify/internal/storage"
+	"github.com/gwenziro/bot-notify/internal/utils"
+	"go.mau.fi/whatsmeow/types"
+	"go.mau.fi/whatsmeow/types/events"
+)
+
+const (
+	// storagePrefix adalah prefix key receipt di storage
+	storagePrefix = "receipts"
+
+	// retention adalah lama data receipt disimpan sebelum kedaluwarsa
+	retention = 30 * 24 * time.Hour
+)
+
+// ErrReceiptNotFound dikembalikan ketika tidak ada data receipt untuk ID pesan
+var ErrReceiptNotFound = errors.New("receipt pesan tidak ditemukan")
+
+// Status menunjukkan tahap terakhir yang dicapai pesan keluar
+type Status string
+
+const (
+	StatusSent      Status = "sent"
+	StatusDelivered Status = "delivered"
+	StatusRead      Status = "read"
+)
+
+// MessageReceipt menyimpan waktu kirim, diterima, dan dibaca untuk satu pesan WhatsApp
+type MessageReceipt struct {
+	MessageID   string     `json:"message_id"`
+	Recipient   string     `json:"recipient"`
+	Status      Status     `json:"status"`
+	SentAt      time.Time  `json:"sent_at"`
+	DeliveredAt *time.Time `json:"delivered_at,omitempty"`
+	ReadAt      *time.Time `json:"read_at,omitempty"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+}
+
+// Tracker mencatat receipt pesan keluar ke storage.
+// Tracker mengimplementasikan client.ReceiptRecorder.
+type Tracker struct {
+	helper *storage.Helper
+	logger utils.LogrusEntry
+	mu     sync.Mutex
+}
+
+// NewTracker membuat instance baru Tracker
+func NewTracker(store storage.Storage) *Tracker {
+	return &Tracker{
+		helper: storage.NewHelper(store, storagePrefix),
+		logger: utils.ForModule("receipt"),
+	}
+}
+
+// RecordSent mencatat pesan yang berhasil dikirim ke server WhatsApp
+func (t *Tracker) RecordSent(messageID types.MessageID, recipient types.JID, sentAt time.Time) {
+	if sentAt.IsZero() {
+		sentAt = time.Now()
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	rec := &MessageReceipt{
+		MessageID: messageID,
+		Recipient: recipient.String(),
+		Status:    StatusSent,
+		SentAt:    sentAt,
+		UpdatedAt: time.Now(),
+	}
+
+	if err := t.save(rec); err != nil {
+		t.logger.WithError(err).WithField("message_id", messageID).Error("Gagal menyimpan receipt pesan")
+	}
+}
+
+// RecordReceipt memperbarui waktu diterima atau dibaca berdasarkan event receipt WhatsApp.
+// Receipt untuk pesan yang tidak dikirim melalui aplikasi ini diabaikan.
+func (t *Tracker) RecordReceipt(evt *events.Receipt) {
+	// Receipt dari perangkat sendiri tidak menandakan pesan sampai ke penerima
+	if evt.IsFromMe {
+		return
+	}
+
+	var status Status
+	switch evt.Type {
+	case types.ReceiptTypeDelivered:
+		status = StatusDelivered
+	case types.ReceiptTypeRead, types.ReceiptTypePlayed:
+		status = StatusRead
+	default:
+		return
+	}
+
+	at := evt.Timestamp
+	if at.IsZero() {
+		at = time.Now()
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	for _, id := range evt.MessageIDs {
+		rec, err := t.get(id)
+		if errors.Is(err, ErrReceiptNotFound) {
+			continue
+		}
+		if err != nil {
+			t.logger.WithError(err).WithField("message_id", id).Warn("Gagal membaca receipt pesan")
+			continue
+		}
+
+		if !rec.apply(status, at) {
+			continue
+		}
+
+		if err := t.save(rec); err != nil {
+			t.logger.WithError(err).WithField("message_id", id).Error("Gagal memperbarui receipt pesan")
+			continue
+		}
+
+		t.logger.WithFields(utils.Fields{
+			"message_id": id,
+			"status":     rec.Status,
+			"from":       evt.Sender.String(),
+		}).Debug("Receipt pesan diperbarui")
+	}
+}
+
+// Get mengambil data receipt berdasarkan ID pesan WhatsApp
+func (t *Tracker) Get(messageID string) (*MessageReceipt, error) {
+	return t.get(messageID)
+}
+
+// get membaca receipt dari storage
+func (t *Tracker) get(messageID string) (*MessageReceipt, error) {
+	var rec MessageReceipt
+	if err := t.helper.GetJSON(context.Background(), messageID, &rec); err != nil {
+		if storage.IsNotFound(err) {
+			return nil, ErrReceiptNotFound
+		}
+		return nil, fmt.Errorf("gagal membaca receipt: %w", err)
+	}
+	return &rec, nil
+}
+
+// save menyimpan receipt ke storage dengan masa simpan terbatas
+func (t *Tracker) save(rec *MessageReceipt) error {
+	return t.helper.SetJSONWithTTL(context.Background(), rec.MessageID, rec, retention)
+}
+
+// apply mencatat waktu receipt pertama untuk status tersebut.
+// Mengembalikan false jika receipt tidak mengubah data.
+func (r *MessageReceipt) apply(status Status, at time.Time) bool {
+	changed := false
+
+	// Untuk pesan grup, waktu yang dicatat adalah receipt pertama dari anggota mana pun
+	if r.DeliveredAt == nil {
+		r.DeliveredAt = &at
+		changed = true
+	}
+	if status == StatusRead && r.ReadAt == nil {
+		r.ReadAt = &at
+		changed = true
+	}
+
+	if !changed {
+		return false
+	}
+
+	if r.ReadAt != nil {
+		r.Status = StatusRead
+	} else {
+		r.Status = StatusDelivered
+	}
+	r.UpdatedAt = time.Now()
+	return true
+}
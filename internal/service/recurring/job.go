@@ -0,0 +1,105 @@
+package recurring
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/gwenziro/bot-notify/internal/service/queue"
+	"github.com/gwenziro/bot-notify/internal/service/whatsapp/client"
+	"github.com/robfig/cron/v3"
+	"go.mau.fi/whatsmeow/types"
+)
+
+// ErrJobNotFound dikembalikan ketika job tidak ada di storage
+var ErrJobNotFound = errors.New("job pesan berulang tidak ditemukan")
+
+// cronParser menerima ekspresi cron 5 field standar dan deskriptor seperti @daily
+var cronParser = cron.NewParser(cron.Minute | cron.Hour | cron.Dom | cron.Month | cron.Dow | cron.Descriptor)
+
+// Job adalah definisi pesan berulang yang dijadwalkan dengan ekspresi cron
+type Job struct {
+	ID            string            `json:"id"`
+	Name          string            `json:"name"`
+	Schedule      string            `json:"schedule"`
+	Timezone      string            `json:"timezone"`
+	Account       string            `json:"account,omitempty"`
+	Type          queue.MessageType `json:"type"`
+	Recipient     string            `json:"recipient"`
+	Message       string            `json:"message"`
+	Enabled       bool              `json:"enabled"`
+	CreatedAt     time.Time         `json:"created_at"`
+	UpdatedAt     time.Time         `json:"updated_at"`
+	NextRunAt     *time.Time        `json:"next_run_at,omitempty"`
+	LastRunAt     *time.Time        `json:"last_run_at,omitempty"`
+	LastMessageID string            `json:"last_message_id,omitempty"`
+	LastError     string            `json:"last_error,omitempty"`
+}
+
+// JobSpec berisi field yang dapat diatur saat membuat atau mengubah job
+type JobSpec struct {
+	Name      string
+	Schedule  string
+	Timezone  string
+	Account   string // Kosong berarti akun default
+	Type      queue.MessageType
+	Recipient types.JID
+	Message   string
+	Enabled   bool
+}
+
+// Validate memeriksa kelengkapan dan validitas spesifikasi job
+func (s JobSpec) Validate() error {
+	if strings.TrimSpace(s.Name) == "" {
+		return errors.New("nama job harus diisi")
+	}
+	if strings.TrimSpace(s.Message) == "" {
+		return errors.New("pesan harus diisi")
+	}
+	if s.Recipient.IsEmpty() {
+		return errors.New("tujuan pesan harus diisi")
+	}
+	if s.Type != queue.TypePersonal && s.Type != queue.TypeGroup {
+		return fmt.Errorf("tipe pesan tidak dikenal: %s", s.Type)
+	}
+	if s.Account != "" {
+		if err := client.ValidateAccountName(s.Account); err != nil {
+			return err
+		}
+	}
+	_, _, err := parseSchedule(s.Schedule, s.Timezone)
+	return err
+}
+
+// jobKey membuat key untuk data job
+func jobKey(id string) string {
+	return "job:" + id
+}
+
+// parseSchedule mem-parse ekspresi cron dan zona waktu job
+func parseSchedule(expr, timezone string) (cron.Schedule, *time.Location, error) {
+	schedule, err := cronParser.Parse(strings.TrimSpace(expr))
+	if err != nil {
+		return nil, nil, fmt.Errorf("ekspresi cron tidak valid: %w", err)
+	}
+
+	loc := time.Local
+	if timezone != "" {
+		loc, err = time.LoadLocation(timezone)
+		if err != nil {
+			return nil, nil, fmt.Errorf("zona waktu tidak valid: %w", err)
+		}
+	}
+
+	return schedule, loc, nil
+}
+
+// nextRun menghitung waktu eksekusi berikutnya setelah waktu yang diberikan
+func (j *Job) nextRun(after time.Time) (time.Time, error) {
+	schedule, loc, err := parseSchedule(j.Schedule, j.Timezone)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return schedule.Next(after.In(loc)), nil
+}
@@ -0,0 +1,312 @@
+package recurring
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/gwenziro/bot-notify/internal/service/queue"
+	"github.com/gwenziro/bot-notify/internal/storage"
+	"github.com/gwenziro/bot-notify/internal/utils"
+	"go.mau.fi/whatsmeow/types"
+)
+
+const (
+	// storagePrefix adalah prefix key job pesan berulang di storage
+	storagePrefix = "recurring"
+
+	// tickInterval adalah interval pemeriksaan job yang jatuh tempo
+	tickInterval = 10 * time.Second
+
+	// misfireGrace adalah batas keterlambatan eksekusi. Job yang terlewat lebih
+	// lama dari ini (misalnya karena aplikasi mati) dilewati sampai jadwal berikutnya.
+	misfireGrace = 5 * time.Minute
+)
+
+// Scheduler menjalankan job pesan berulang dan memasukkan pesannya ke antrean keluar
+type Scheduler struct {
+	helper *storage.Helper
+	outbox *queue.Queue
+	logger utils.LogrusEntry
+	mu     sync.Mutex
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// NewScheduler membuat instance baru Scheduler
+func NewScheduler(store storage.Storage, outbox *queue.Queue) *Scheduler {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	return &Scheduler{
+		helper: storage.NewHelper(store, storagePrefix),
+		outbox: outbox,
+		logger: utils.ForModule("recurring"),
+		ctx:    ctx,
+		cancel: cancel,
+	}
+}
+
+// Start menjalankan loop scheduler
+func (s *Scheduler) Start() {
+	s.logger.Info("Menjalankan scheduler pesan berulang")
+
+	s.wg.Add(1)
+	go s.loop()
+}
+
+// Stop menghentikan loop scheduler
+func (s *Scheduler) Stop() {
+	s.logger.Info("Menghentikan scheduler pesan berulang")
+	s.cancel()
+	s.wg.Wait()
+}
+
+// List mengembalikan semua job, terurut dari jadwal berikutnya yang terdekat
+func (s *Scheduler) List() ([]*Job, error) {
+	data, err := s.helper.GetAllWithPrefix(context.Background(), "job:")
+	if err != nil {
+		return nil, fmt.Errorf("gagal membaca job: %w", err)
+	}
+
+	jobs := make([]*Job, 0, len(data))
+	for key, raw := range data {
+		var job Job
+		if err := json.Unmarshal(raw, &job); err != nil {
+			s.logger.WithError(err).WithField("key", key).Warn("Gagal parse job pesan berulang")
+			continue
+		}
+		jobs = append(jobs, &job)
+	}
+
+	sort.Slice(jobs, func(i, j int) bool {
+		a, b := jobs[i].NextRunAt, jobs[j].NextRunAt
+		switch {
+		case a == nil && b == nil:
+			return jobs[i].Name < jobs[j].Name
+		case a == nil:
+			return false
+		case b == nil:
+			return true
+		}
+		return a.Before(*b)
+	})
+
+	return jobs, nil
+}
+
+// Get mengambil job berdasarkan ID
+func (s *Scheduler) Get(id string) (*Job, error) {
+	var job Job
+	if err := s.helper.GetJSON(context.Background(), jobKey(id), &job); err != nil {
+		if storage.IsNotFound(err) {
+			return nil, ErrJobNotFound
+		}
+		return nil, fmt.Errorf("gagal membaca job: %w", err)
+	}
+	return &job, nil
+}
+
+// Create menyimpan job baru dan menghitung jadwal pertamanya
+func (s *Scheduler) Create(spec JobSpec) (*Job, error) {
+	if err := spec.Validate(); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	job := &Job{
+		ID:        uuid.New().String(),
+		CreatedAt: now,
+	}
+	s.applySpec(job, spec, now)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.save(job); err != nil {
+		return nil, err
+	}
+
+	s.logger.WithFields(utils.Fields{
+		"id":       job.ID,
+		"name":     job.Name,
+		"schedule": job.Schedule,
+		"timezone": job.Timezone,
+	}).Info("Job pesan berulang dibuat")
+
+	return job, nil
+}
+
+// Update mengubah definisi job dan menghitung ulang jadwal berikutnya
+func (s *Scheduler) Update(id string, spec JobSpec) (*Job, error) {
+	if err := spec.Validate(); err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	job, err := s.Get(id)
+	if err != nil {
+		return nil, err
+	}
+
+	s.applySpec(job, spec, time.Now())
+	if err := s.save(job); err != nil {
+		return nil, err
+	}
+
+	s.logger.WithFields(utils.Fields{
+		"id":   job.ID,
+		"name": job.Name,
+	}).Info("Job pesan berulang diperbarui")
+
+	return job, nil
+}
+
+// Delete menghapus job
+func (s *Scheduler) Delete(id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, err := s.Get(id); err != nil {
+		return err
+	}
+
+	if err := s.helper.Delete(context.Background(), jobKey(id)); err != nil {
+		return fmt.Errorf("gagal menghapus job: %w", err)
+	}
+
+	s.logger.WithField("id", id).Info("Job pesan berulang dihapus")
+	return nil
+}
+
+// applySpec menyalin spesifikasi ke job dan menghitung jadwal berikutnya
+func (s *Scheduler) applySpec(job *Job, spec JobSpec, now time.Time) {
+	job.Name = spec.Name
+	job.Schedule = spec.Schedule
+	job.Timezone = spec.Timezone
+	job.Account = spec.Account
+	job.Type = spec.Type
+	job.Recipient = spec.Recipient.String()
+	job.Message = spec.Message
+	job.Enabled = spec.Enabled
+	job.UpdatedAt = now
+	job.NextRunAt = nil
+
+	if job.Enabled {
+		// Spesifikasi sudah divalidasi, error tidak mungkin terjadi di sini
+		if next, err := job.nextRun(now); err == nil {
+			job.NextRunAt = &next
+		}
+	}
+}
+
+// save menyimpan job ke storage
+func (s *Scheduler) save(job *Job) error {
+	if err := s.helper.SetJSON(context.Background(), jobKey(job.ID), job); err != nil {
+		return fmt.Errorf("gagal menyimpan job: %w", err)
+	}
+	return nil
+}
+
+// loop memeriksa job yang jatuh tempo secara berkala
+func (s *Scheduler) loop() {
+	defer s.wg.Done()
+
+	ticker := time.NewTicker(tickInterval)
+	defer ticker.Stop()
+
+	for {
+		s.runDue(time.Now())
+
+		select {
+		case <-s.ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// runDue menjalankan semua job aktif yang sudah jatuh tempo
+func (s *Scheduler) runDue(now time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	jobs, err := s.List()
+	if err != nil {
+		s.logger.WithError(err).Error("Gagal membaca job pesan berulang")
+		return
+	}
+
+	for _, job := range jobs {
+		if !job.Enabled || job.NextRunAt == nil || job.NextRunAt.After(now) {
+			continue
+		}
+		s.run(job, now)
+	}
+}
+
+// run menjadwalkan eksekusi berikutnya lalu memasukkan pesan job ke antrean. Jadwal berikutnya
+// disimpan lebih dulu, sehingga job tidak dijalankan dua kali jika penyimpanan gagal.
+func (s *Scheduler) run(job *Job, now time.Time) {
+	logger := s.logger.WithFields(utils.Fields{
+		"id":   job.ID,
+		"name": job.Name,
+	})
+
+	due := *job.NextRunAt
+	missed := now.Sub(due) > misfireGrace
+	if !missed {
+		job.LastRunAt = &now
+		job.LastError = ""
+	}
+
+	job.NextRunAt = nil
+	if next, err := job.nextRun(now); err == nil {
+		job.NextRunAt = &next
+	} else {
+		job.LastError = err.Error()
+		logger.WithError(err).Error("Gagal menghitung jadwal berikutnya")
+	}
+	job.UpdatedAt = time.Now()
+
+	// Job yang jadwalnya gagal disimpan dicoba lagi pada pemeriksaan berikutnya
+	if err := s.save(job); err != nil {
+		logger.WithError(err).Error("Gagal menyimpan jadwal berikutnya, job belum dijalankan")
+		return
+	}
+
+	if missed {
+		logger.WithField("due", due).Warn("Jadwal job terlewat, dilewati sampai jadwal berikutnya")
+		return
+	}
+
+	jid, err := types.ParseJID(job.Recipient)
+	if err == nil {
+		var msg *queue.OutboundMessage
+		msg, err = s.outbox.Enqueue(job.Type, jid, job.Message, queue.EnqueueOptions{Account: job.Account})
+		if err == nil {
+			job.LastMessageID = msg.ID
+		}
+	}
+
+	if err != nil {
+		job.LastError = err.Error()
+		logger.WithError(err).Error("Gagal menjalankan job pesan berulang")
+	} else {
+		logger.WithField("message_id", job.LastMessageID).Info("Job pesan berulang dijalankan")
+	}
+	job.UpdatedAt = time.Now()
+
+	// Pesan sudah masuk antrean dan jadwal sudah maju, kegagalan di sini hanya
+	// menghilangkan catatan hasil eksekusi terakhir
+	if err := s.save(job); err != nil {
+		logger.WithError(err).Error("Gagal menyimpan status job")
+	}
+}
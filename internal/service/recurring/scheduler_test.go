@@ -0,0 +1,147 @@
+package recurring
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/gwenziro/bot-notify/internal/config"
+	"github.com/gwenziro/bot-notify/internal/service/queue"
+	"github.com/gwenziro/bot-notify/internal/storage"
+	"go.mau.fi/whatsmeow/types"
+)
+
+// failingStore adalah storage yang penulisannya dapat dibuat gagal
+type failingStore struct {
+	storage.Storage
+	failSet bool
+}
+
+func (s *failingStore) Set(ctx context.Context, key string, value []byte) error {
+	if s.failSet {
+		return errors.New("disk penuh")
+	}
+	return s.Storage.Set(ctx, key, value)
+}
+
+// newTestStore membuat storage Badger in-memory
+func newTestStore(t *testing.T) storage.Storage {
+	t.Helper()
+	store, err := storage.NewBadgerStorage(storage.StorageOptions{InMemory: true})
+	if err != nil {
+		t.Fatalf("gagal membuat storage: %v", err)
+	}
+	t.Cleanup(func() { store.Close() })
+	return store
+}
+
+// newTestScheduler membuat Scheduler dengan job setiap 5 menit dan mengembalikan storage antreannya
+func newTestScheduler(t *testing.T) (*Scheduler, *failingStore, storage.Storage, *Job) {
+	t.Helper()
+	jobs := &failingStore{Storage: newTestStore(t)}
+	outbox := newTestStore(t)
+
+	s := NewScheduler(jobs, queue.NewQueue(outbox, nil, &config.Config{}))
+	job, err := s.Create(JobSpec{
+		Name:      "laporan",
+		Schedule:  "*/5 * * * *",
+		Timezone:  "UTC",
+		Type:      queue.TypePersonal,
+		Recipient: types.NewJID("628123456789", types.DefaultUserServer),
+		Message:   "laporan berkala",
+		Enabled:   true,
+	})
+	if err != nil {
+		t.Fatalf("gagal membuat job: %v", err)
+	}
+	return s, jobs, outbox, job
+}
+
+// queuedMessages menghitung pesan di antrean
+func queuedMessages(t *testing.T, outbox storage.Storage) int {
+	t.Helper()
+	data, err := outbox.GetWithPrefix(context.Background(), "outbox:msg:")
+	if err != nil {
+		t.Fatalf("gagal membaca antrean: %v", err)
+	}
+	return len(data)
+}
+
+// getTestJob membaca job dari storage
+func getTestJob(t *testing.T, s *Scheduler, id string) *Job {
+	t.Helper()
+	job, err := s.Get(id)
+	if err != nil {
+		t.Fatalf("gagal membaca job: %v", err)
+	}
+	return job
+}
+
+func TestRunDueEnqueuesOnce(t *testing.T) {
+	s, _, outbox, job := newTestScheduler(t)
+	due := *job.NextRunAt
+	now := due.Add(time.Second)
+
+	s.runDue(now)
+	s.runDue(now)
+
+	if n := queuedMessages(t, outbox); n != 1 {
+		t.Fatalf("jumlah pesan di antrean = %d, ingin 1", n)
+	}
+
+	got := getTestJob(t, s, job.ID)
+	if got.NextRunAt == nil || !got.NextRunAt.Equal(due.Add(5*time.Minute)) {
+		t.Errorf("NextRunAt = %v, ingin %v", got.NextRunAt, due.Add(5*time.Minute))
+	}
+	if got.LastRunAt == nil || !got.LastRunAt.Equal(now) || got.LastMessageID == "" || got.LastError != "" {
+		t.Errorf("hasil eksekusi = last_run %v, message %q, error %q", got.LastRunAt, got.LastMessageID, got.LastError)
+	}
+}
+
+func TestRunDueSkipsEnqueueWhenSaveFails(t *testing.T) {
+	s, jobs, outbox, job := newTestScheduler(t)
+	due := *job.NextRunAt
+	now := due.Add(time.Second)
+
+	// Jadwal berikutnya gagal disimpan, pesan tidak boleh masuk antrean berulang kali
+	jobs.failSet = true
+	s.runDue(now)
+	s.runDue(now.Add(10 * time.Second))
+
+	if n := queuedMessages(t, outbox); n != 0 {
+		t.Fatalf("jumlah pesan di antrean = %d, ingin 0", n)
+	}
+	if got := getTestJob(t, s, job.ID); got.NextRunAt == nil || !got.NextRunAt.Equal(due) {
+		t.Errorf("NextRunAt = %v, ingin tetap %v", got.NextRunAt, due)
+	}
+
+	// Setelah storage pulih, job dijalankan sekali selama masih dalam batas keterlambatan
+	jobs.failSet = false
+	s.runDue(now.Add(20 * time.Second))
+	s.runDue(now.Add(30 * time.Second))
+
+	if n := queuedMessages(t, outbox); n != 1 {
+		t.Errorf("jumlah pesan di antrean = %d, ingin 1", n)
+	}
+}
+
+func TestRunDueSkipsMissedRun(t *testing.T) {
+	s, _, outbox, job := newTestScheduler(t)
+	due := *job.NextRunAt
+	now := due.Add(misfireGrace + time.Minute)
+
+	s.runDue(now)
+
+	if n := queuedMessages(t, outbox); n != 0 {
+		t.Errorf("jumlah pesan di antrean = %d, ingin 0", n)
+	}
+
+	got := getTestJob(t, s, job.ID)
+	if got.LastRunAt != nil {
+		t.Errorf("LastRunAt = %v, ingin kosong", got.LastRunAt)
+	}
+	if got.NextRunAt == nil || !got.NextRunAt.After(now) {
+		t.Errorf("NextRunAt = %v, ingin setelah %v", got.NextRunAt, now)
+	}
+}
@@ -0,0 +1,291 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/gwenziro/bot-notify/internal/config"
+	"github.com/gwenziro/bot-notify/internal/utils"
+	"go.mau.fi/whatsmeow/types"
+	"go.mau.fi/whatsmeow/types/events"
+)
+
+const (
+	// Header yang dikirim bersama setiap webhook
+	HeaderEvent     = "X-Webhook-Event"
+	HeaderDelivery  = "X-Webhook-Delivery"
+	HeaderTimestamp = "X-Webhook-Timestamp"
+	HeaderSignature = "X-Webhook-Signature"
+
+	// queueSize adalah kapasitas antrean pengiriman di memori
+	queueSize = 256
+
+	// Nilai default jika tidak diatur di konfigurasi
+	defaultTimeout        = 10 * time.Second
+	defaultMaxAttempts    = 5
+	defaultInitialBackoff = 2 * time.Second
+	defaultMaxBackoff     = 2 * time.Minute
+	defaultWorkers        = 2
+)
+
+// delivery adalah satu payload yang akan dikirim ke satu subscription
+type delivery struct {
+	id           string
+	subscription config.WebhookSubscription
+	body         []byte
+}
+
+// Dispatcher meneruskan pesan WhatsApp yang masuk ke webhook yang terdaftar
+type Dispatcher struct {
+	subscriptions  []config.WebhookSubscription
+	httpClient     *http.Client
+	maxAttempts    int
+	initialBackoff time.Duration
+	maxBackoff     time.Duration
+	workers        int
+	logger         utils.LogrusEntry
+
+	jobs   chan delivery
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// NewDispatcher membuat instance baru Dispatcher dari konfigurasi webhook
+func NewDispatcher(cfg *config.Config) *Dispatcher {
+	whCfg := cfg.Webhooks
+	logger := utils.ForModule("webhook")
+
+	// Abaikan subscription tanpa URL agar tidak gagal terus-menerus
+	subscriptions := make([]config.WebhookSubscription, 0, len(whCfg.Subscriptions))
+	for _, sub := range whCfg.Subscriptions {
+		if sub.URL == "" {
+			logger.WithField("name", sub.Name).Warn("Subscription webhook tanpa URL diabaikan")
+			continue
+		}
+		if sub.Secret == "" {
+			logger.WithField("name", sub.Name).Warn("Subscription webhook tanpa secret, payload tidak ditandatangani")
+		}
+		subscriptions = append(subscriptions, sub)
+	}
+
+	timeout := whCfg.Timeout
+	if timeout <= 0 {
+		timeout = defaultTimeout
+	}
+	maxAttempts := whCfg.MaxAttempts
+	if maxAttempts <= 0 {
+		maxAttempts = defaultMaxAttempts
+	}
+	initialBackoff := whCfg.InitialBackoff
+	if initialBackoff <= 0 {
+		initialBackoff = defaultInitialBackoff
+	}
+	maxBackoff := whCfg.MaxBackoff
+	if maxBackoff < initialBackoff {
+		maxBackoff = defaultMaxBackoff
+	}
+	workers := whCfg.Workers
+	if workers <= 0 {
+		workers = defaultWorkers
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	return &Dispatcher{
+		subscriptions:  subscriptions,
+		httpClient:     &http.Client{Timeout: timeout},
+		maxAttempts:    maxAttempts,
+		initialBackoff: initialBackoff,
+		maxBackoff:     maxBackoff,
+		workers:        workers,
+		logger:         logger,
+		jobs:           make(chan delivery, queueSize),
+		ctx:            ctx,
+		cancel:         cancel,
+	}
+}
+
+// Start menjalankan worker pengirim webhook
+func (d *Dispatcher) Start() {
+	if len(d.subscriptions) == 0 {
+		d.logger.Info("Tidak ada subscription webhook, dispatcher tidak dijalankan")
+		return
+	}
+
+	d.logger.WithFields(utils.Fields{
+		"subscriptions": len(d.subscriptions),
+		"workers":       d.workers,
+	}).Info("Menjalankan dispatcher webhook")
+
+	for i := 0; i < d.workers; i++ {
+		d.wg.Add(1)
+		go d.worker()
+	}
+}
+
+// Stop menghentikan worker dan menunggu pengiriman yang sedang berjalan
+func (d *Dispatcher) Stop() {
+	d.cancel()
+	d.wg.Wait()
+}
+
+// HandleEvent adalah callback untuk event pesan masuk dari klien WhatsApp
+func (d *Dispatcher) HandleEvent(evt interface{}) {
+	msg, ok := evt.(*events.Message)
+	if !ok || len(d.subscriptions) == 0 {
+		return
+	}
+
+	// Abaikan pesan yang dikirim sendiri dan pembaruan status
+	if msg.Info.IsFromMe || msg.Info.Chat == types.StatusBroadcastJID {
+		return
+	}
+
+	payload := Payload{
+		Event:     EventMessage,
+		Timestamp: time.Now(),
+		Message:   NewInboundMessage(msg),
+	}
+
+	body, err := json.Marshal(payload)
+	if err != nil {
+		d.logger.WithError(err).Error("Gagal marshal payload webhook")
+		return
+	}
+
+	for _, sub := range d.subscriptions {
+		if payload.Message.IsGroup && !sub.IncludeGroups {
+			continue
+		}
+		d.enqueue(delivery{
+			id:           uuid.New().String(),
+			subscription: sub,
+			body:         body,
+		})
+	}
+}
+
+// enqueue memasukkan pengiriman ke antrean tanpa memblokir event handler WhatsApp
+func (d *Dispatcher) enqueue(job delivery) {
+	select {
+	case d.jobs <- job:
+	default:
+		d.logger.WithFields(utils.Fields{
+			"name":     job.subscription.Name,
+			"delivery": job.id,
+		}).Warn("Antrean webhook penuh, pengiriman dibuang")
+	}
+}
+
+// worker mengambil pengiriman dari antrean dan mengirimkannya
+func (d *Dispatcher) worker() {
+	defer d.wg.Done()
+
+	for {
+		select {
+		case <-d.ctx.Done():
+			return
+		case job := <-d.jobs:
+			d.deliver(job)
+		}
+	}
+}
+
+// deliver mengirim satu payload dengan retry dan exponential backoff
+func (d *Dispatcher) deliver(job delivery) {
+	logger := d.logger.WithFields(utils.Fields{
+		"name":     job.subscription.Name,
+		"url":      job.subscription.URL,
+		"delivery": job.id,
+	})
+
+	backoff := d.initialBackoff
+	for attempt := 1; attempt <= d.maxAttempts; attempt++ {
+		retryable, err := d.post(job)
+		if err == nil {
+			logger.WithField("attempts", attempt).Debug("Webhook berhasil dikirim")
+			return
+		}
+
+		if !retryable || attempt == d.maxAttempts {
+			logger.WithFields(utils.Fields{
+				"attempts": attempt,
+				"error":    err,
+			}).Error("Gagal mengirim webhook")
+			return
+		}
+
+		logger.WithFields(utils.Fields{
+			"attempts":   attempt,
+			"next_retry": backoff,
+			"error":      err,
+		}).Warn("Gagal mengirim webhook, akan dicoba lagi")
+
+		select {
+		case <-d.ctx.Done():
+			return
+		case <-time.After(backoff):
+		}
+
+		backoff *= 2
+		if backoff > d.maxBackoff {
+			backoff = d.maxBackoff
+		}
+	}
+}
+
+// post mengirim request HTTP ke webhook. Mengembalikan apakah error boleh dicoba ulang.
+func (d *Dispatcher) post(job delivery) (bool, error) {
+	req, err := http.NewRequestWithContext(d.ctx, http.MethodPost, job.subscription.URL, bytes.NewReader(job.body))
+	if err != nil {
+		return false, fmt.Errorf("gagal membuat request: %w", err)
+	}
+
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "bot-notify-webhook")
+	req.Header.Set(HeaderEvent, EventMessage)
+	req.Header.Set(HeaderDelivery, job.id)
+	req.Header.Set(HeaderTimestamp, timestamp)
+	if job.subscription.Secret != "" {
+		req.Header.Set(HeaderSignature, Sign(job.subscription.Secret, timestamp, job.body))
+	}
+
+	resp, err := d.httpClient.Do(req)
+	if err != nil {
+		return true, err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 4096))
+
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return false, nil
+	}
+
+	// Error server dan rate limit dicoba ulang, error klien lainnya tidak
+	retryable := resp.StatusCode >= 500 || resp.StatusCode == http.StatusTooManyRequests ||
+		resp.StatusCode == http.StatusRequestTimeout
+	return retryable, fmt.Errorf("webhook merespons dengan status %d", resp.StatusCode)
+}
+
+// Sign menghitung tanda tangan HMAC-SHA256 dari "{timestamp}.{body}".
+// Penerima memverifikasi dengan menghitung ulang nilai ini memakai secret yang sama.
+func Sign(secret, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
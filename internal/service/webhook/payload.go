@@ -0,0 +1,107 @@
+package webhook
+
+import (
+	"time"
+
+	"go.mau.fi/whatsmeow/types/events"
+)
+
+// EventMessage adalah nama event untuk pesan masuk
+const EventMessage = "message"
+
+// Payload adalah body JSON yang dikirim ke setiap webhook
+type Payload struct {
+	Event     string         `json:"event"`
+	Timestamp time.Time      `json:"timestamp"`
+	Message   InboundMessage `json:"message"`
+}
+
+// InboundMessage adalah bentuk normal pesan WhatsApp yang masuk
+type InboundMessage struct {
+	ID         string     `json:"id"`
+	Timestamp  time.Time  `json:"timestamp"`
+	Sender     string     `json:"sender"`
+	SenderName string     `json:"sender_name,omitempty"`
+	Chat       string     `json:"chat"`
+	IsGroup    bool       `json:"is_group"`
+	Text       string     `json:"text,omitempty"`
+	Media      *MediaInfo `json:"media,omitempty"`
+}
+
+// MediaInfo berisi metadata media yang dilampirkan pada pesan masuk.
+// Konten media tidak ikut dikirim.
+type MediaInfo struct {
+	Type     string `json:"type"`
+	MimeType string `json:"mime_type,omitempty"`
+	FileName string `json:"file_name,omitempty"`
+	Size     uint64 `json:"size,omitempty"`
+	Caption  string `json:"caption,omitempty"`
+}
+
+// NewInboundMessage mengkonversi event pesan whatsmeow ke bentuk normal
+func NewInboundMessage(evt *events.Message) InboundMessage {
+	msg := InboundMessage{
+		ID:         evt.Info.ID,
+		Timestamp:  evt.Info.Timestamp,
+		Sender:     evt.Info.Sender.ToNonAD().String(),
+		SenderName: evt.Info.PushName,
+		Chat:       evt.Info.Chat.String(),
+		IsGroup:    evt.Info.IsGroup,
+	}
+
+	m := evt.Message
+	if m == nil {
+		return msg
+	}
+
+	switch {
+	case m.GetConversation() != "":
+		msg.Text = m.GetConversation()
+	case m.GetExtendedTextMessage() != nil:
+		msg.Text = m.GetExtendedTextMessage().GetText()
+	case m.GetImageMessage() != nil:
+		img := m.GetImageMessage()
+		msg.Text = img.GetCaption()
+		msg.Media = &MediaInfo{
+			Type:     "image",
+			MimeType: img.GetMimetype(),
+			Size:     img.GetFileLength(),
+			Caption:  img.GetCaption(),
+		}
+	case m.GetVideoMessage() != nil:
+		video := m.GetVideoMessage()
+		msg.Text = video.GetCaption()
+		msg.Media = &MediaInfo{
+			Type:     "video",
+			MimeType: video.GetMimetype(),
+			Size:     video.GetFileLength(),
+			Caption:  video.GetCaption(),
+		}
+	case m.GetAudioMessage() != nil:
+		audio := m.GetAudioMessage()
+		msg.Media = &MediaInfo{
+			Type:     "audio",
+			MimeType: audio.GetMimetype(),
+			Size:     audio.GetFileLength(),
+		}
+	case m.GetDocumentMessage() != nil:
+		doc := m.GetDocumentMessage()
+		msg.Text = doc.GetCaption()
+		msg.Media = &MediaInfo{
+			Type:     "document",
+			MimeType: doc.GetMimetype(),
+			FileName: doc.GetFileName(),
+			Size:     doc.GetFileLength(),
+			Caption:  doc.GetCaption(),
+		}
+	case m.GetStickerMessage() != nil:
+		sticker := m.GetStickerMessage()
+		msg.Media = &MediaInfo{
+			Type:     "sticker",
+			MimeType: sticker.GetMimetype(),
+			Size:     sticker.GetFileLength(),
+		}
+	}
+
+	return msg
+}
@@ -0,0 +1,437 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/gwenziro/bot-notify/internal/config"
+	"github.com/gwenziro/bot-notify/internal/storage"
+	"github.com/gwenziro/bot-notify/internal/utils"
+	"go.mau.fi/whatsmeow/store/sqlstore"
+	"go.mau.fi/whatsmeow/types"
+	"go.mau.fi/whatsmeow/types/events"
+
+	_ "modernc.org/sqlite" // Pure Go SQLite driver
+)
+
+const (
+	// DefaultAccount adalah akun yang dipakai jika request tidak menyebutkan akun
+	DefaultAccount = "default"
+
+	// accountStoragePrefix adalah prefix key data akun di storage
+	accountStoragePrefix = "accounts"
+)
+
+var (
+	// ErrAccountNotFound dikembalikan ketika akun tidak terdaftar
+	ErrAccountNotFound = errors.New("akun WhatsApp tidak ditemukan")
+
+	// ErrAccountExists dikembalikan ketika nama akun sudah dipakai
+	ErrAccountExists = errors.New("akun WhatsApp sudah ada")
+
+	// ErrInvalidAccountName dikembalikan ketika nama akun tidak sesuai format
+	ErrInvalidAccountName = errors.New("nama akun hanya boleh berisi huruf kecil, angka, - dan _ (maksimal 32 karakter)")
+
+	// ErrDefaultAccount dikembalikan ketika akun default akan dihapus
+	ErrDefaultAccount = errors.New("akun default tidak dapat dihapus")
+)
+
+// accountNamePattern adalah format nama akun yang aman dipakai di URL dan key storage
+var accountNamePattern = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]{0,31}$`)
+
+// Account adalah data akun WhatsApp yang disimpan di storage
+type Account struct {
+	Name      string     `json:"name"`
+	JID       string     `json:"jid,omitempty"`
+	CreatedAt time.Time  `json:"createdAt"`
+	PairedAt  *time.Time `json:"pairedAt,omitempty"`
+}
+
+// AccountInfo adalah data akun beserta status koneksinya
+type AccountInfo struct {
+	Account
+	Status      ClientStatus `json:"status"`
+	IsConnected bool         `json:"isConnected"`
+	LoggedIn    bool         `json:"loggedIn"`
+	PushName    string       `json:"pushName,omitempty"`
+	Default     bool         `json:"default"`
+}
+
+// AccountManager mengelola beberapa akun WhatsApp bernama dalam satu instance.
+// Setiap akun memiliki klien, status koneksi, reconnect loop, dan alur QR/pairing
+// sendiri, sementara device store sqlstore dipakai bersama.
+type AccountManager struct {
+	config      *config.Live
+	deviceStore *sqlstore.Container
+	helper      *storage.Helper
+	logger      utils.LogrusEntry
+
+	mu        sync.RWMutex
+	accounts  map[string]*Account
+	clients   map[string]*Client
+	callbacks map[string]func(interface{})
+	receipts  ReceiptRecorder
+}
+
+// NewAccountManager membuka device store bersama lalu membuat klien untuk setiap akun tersimpan.
+// Jika belum ada akun, akun default dibuat dan memakai device yang sudah dipasangkan sebelumnya.
+func NewAccountManager(cfg *config.Live, store storage.Storage) (*AccountManager, error) {
+	logger := utils.ForModule("accounts")
+
+	// Pastikan direktori penyimpanan ada
+	storeDir := cfg.Get().WhatsApp.StoreDir
+	if err := os.MkdirAll(storeDir, 0755); err != nil {
+		return nil, fmt.Errorf("gagal membuat direktori penyimpanan: %w", err)
+	}
+
+	dbPath := fmt.Sprintf("%s/store.db", storeDir)
+	deviceStore, err := sqlstore.New(context.Background(), "sqlite", fmt.Sprintf("file:%s?_pragma=foreign_keys(1)", dbPath), NewWhatsmeowLogger(logger))
+	if err != nil {
+		return nil, fmt.Errorf("gagal membuat device store: %w", err)
+	}
+
+	m := &AccountManager{
+		config:      cfg,
+		deviceStore: deviceStore,
+		helper:      storage.NewHelper(store, accountStoragePrefix),
+		logger:      logger,
+		accounts:    make(map[string]*Account),
+		clients:     make(map[string]*Client),
+		callbacks:   make(map[string]func(interface{})),
+	}
+
+	if err := m.load(); err != nil {
+		deviceStore.Close()
+		return nil, err
+	}
+
+	return m, nil
+}
+
+// accountKey membuat key untuk data akun
+func accountKey(name string) string {
+	return "account:" + name
+}
+
+// load membaca akun dari storage dan membuat klien untuk masing-masing akun
+func (m *AccountManager) load() error {
+	ctx := context.Background()
+	data, err := m.helper.GetAllWithPrefix(ctx, "account:")
+	if err != nil {
+		return fmt.Errorf("gagal membaca daftar akun: %w", err)
+	}
+
+	for key, raw := range data {
+		var account Account
+		if err := json.Unmarshal(raw, &account); err != nil {
+			m.logger.WithError(err).WithField("key", key).Warn("Gagal parse data akun")
+			continue
+		}
+		m.accounts[account.Name] = &account
+	}
+
+	if _, ok := m.accounts[DefaultAccount]; !ok {
+		if err := m.createDefault(ctx); err != nil {
+			return err
+		}
+	}
+
+	for name, account := range m.accounts {
+		m.clients[name] = m.buildClient(account)
+	}
+
+	m.logger.WithField("accounts", len(m.accounts)).Info("Akun WhatsApp dimuat")
+	return nil
+}
+
+// createDefault membuat akun default. Device pertama yang sudah dipasangkan dipakai
+// agar instance yang sebelumnya hanya mendukung satu nomor tetap memakai sesinya.
+func (m *AccountManager) createDefault(ctx context.Context) error {
+	account := &Account{Name: DefaultAccount, CreatedAt: time.Now()}
+
+	devices, err := m.deviceStore.GetAllDevices(ctx)
+	if err != nil {
+		return fmt.Errorf("gagal mendapatkan devices: %w", err)
+	}
+	if len(devices) > 0 && devices[0].ID != nil {
+		account.JID = devices[0].ID.String()
+		m.logger.WithField("id", account.JID).Info("Memakai device yang ada untuk akun default")
+	}
+
+	if err := m.helper.SetJSON(ctx, accountKey(account.Name), account); err != nil {
+		return fmt.Errorf("gagal menyimpan akun default: %w", err)
+	}
+	m.accounts[account.Name] = account
+	return nil
+}
+
+// buildClient membuat klien untuk akun dan memasang callback yang sudah terdaftar
+func (m *AccountManager) buildClient(account *Account) *Client {
+	c := newClient(account.Name, m.config, m.deviceStore)
+
+	if account.JID != "" {
+		jid, err := types.ParseJID(account.JID)
+		if err != nil {
+			m.logger.WithError(err).WithField("account", account.Name).Warn("JID akun tidak valid, akun perlu dipasangkan ulang")
+		} else {
+			c.SessionManager.SetDeviceJID(jid)
+		}
+	}
+
+	for event, callback := range m.callbacks {
+		c.RegisterCallback(event, callback)
+	}
+	if m.receipts != nil {
+		c.SetReceiptRecorder(m.receipts)
+	}
+
+	// Simpan JID device setelah pairing agar akun memakai device yang sama setelah restart
+	name := account.Name
+	c.RegisterCallback("*events.PairSuccess", func(evt interface{}) {
+		if v, ok := evt.(*events.PairSuccess); ok {
+			m.recordPaired(name, v.ID)
+		}
+	})
+
+	return c
+}
+
+// recordPaired menyimpan JID device akun yang baru dipasangkan
+func (m *AccountManager) recordPaired(name string, jid types.JID) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	account, ok := m.accounts[name]
+	if !ok {
+		return
+	}
+
+	now := time.Now()
+	account.JID = jid.String()
+	account.PairedAt = &now
+
+	if err := m.helper.SetJSON(context.Background(), accountKey(name), account); err != nil {
+		m.logger.WithError(err).WithField("account", name).Error("Gagal menyimpan device akun")
+	}
+}
+
+// ValidateAccountName memeriksa apakah nama akun sesuai format
+func ValidateAccountName(name string) error {
+	if !accountNamePattern.MatchString(name) {
+		return ErrInvalidAccountName
+	}
+	return nil
+}
+
+// Default mengembalikan klien akun default
+func (m *AccountManager) Default() *Client {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.clients[DefaultAccount]
+}
+
+// Get mengembalikan klien akun berdasarkan nama. Nama kosong berarti akun default.
+func (m *AccountManager) Get(name string) (*Client, error) {
+	if name == "" {
+		name = DefaultAccount
+	}
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	c, ok := m.clients[name]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrAccountNotFound, name)
+	}
+	return c, nil
+}
+
+// Clients mengembalikan klien semua akun, diurutkan dengan akun default di depan
+func (m *AccountManager) Clients() []*Client {
+	m.mu.RLock()
+	clients := make([]*Client, 0, len(m.clients))
+	for _, c := range m.clients {
+		clients = append(clients, c)
+	}
+	m.mu.RUnlock()
+
+	sort.Slice(clients, func(i, j int) bool {
+		if clients[i].Name() == DefaultAccount || clients[j].Name() == DefaultAccount {
+			return clients[i].Name() == DefaultAccount
+		}
+		return clients[i].Name() < clients[j].Name()
+	})
+	return clients
+}
+
+// List mengembalikan semua akun beserta status koneksinya
+func (m *AccountManager) List() []AccountInfo {
+	clients := m.Clients()
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	infos := make([]AccountInfo, 0, len(clients))
+	for _, c := range clients {
+		account, ok := m.accounts[c.Name()]
+		if !ok {
+			continue
+		}
+
+		state := c.GetConnectionState()
+		info := AccountInfo{
+			Account:     *account,
+			Status:      state.Status,
+			IsConnected: state.IsConnected,
+			LoggedIn:    c.IsLoggedIn(),
+			Default:     account.Name == DefaultAccount,
+		}
+
+		// JID tersimpan bisa tertinggal setelah logout, tampilkan device yang sedang dipakai
+		info.JID = ""
+		if jid := c.SessionManager.DeviceJID(); !jid.IsEmpty() {
+			info.JID = jid.String()
+		}
+		if wa := c.GetWhatsmeowClient(); wa != nil && wa.Store.ID != nil {
+			info.PushName = wa.Store.PushName
+		}
+		infos = append(infos, info)
+	}
+	return infos
+}
+
+// AnyConnected memeriksa apakah minimal satu akun sedang terhubung, atau diputus karena
+// idle dan akan terhubung kembali saat mengirim pesan
+func (m *AccountManager) AnyConnected() bool {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	for _, c := range m.clients {
+		if c.CanSend() {
+			return true
+		}
+	}
+	return false
+}
+
+// Add mendaftarkan akun baru. Klien belum terhubung, panggil Connect atau
+// PairPhone pada klien yang dikembalikan untuk memulai pairing.
+func (m *AccountManager) Add(name string) (*Client, error) {
+	if err := ValidateAccountName(name); err != nil {
+		return nil, err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, ok := m.accounts[name]; ok {
+		return nil, fmt.Errorf("%w: %s", ErrAccountExists, name)
+	}
+
+	account := &Account{Name: name, CreatedAt: time.Now()}
+	if err := m.helper.SetJSON(context.Background(), accountKey(name), account); err != nil {
+		return nil, fmt.Errorf("gagal menyimpan akun: %w", err)
+	}
+
+	c := m.buildClient(account)
+	m.accounts[name] = account
+	m.clients[name] = c
+
+	m.logger.WithField("account", name).Info("Akun WhatsApp ditambahkan")
+	return c, nil
+}
+
+// Remove memutus perangkat akun dari WhatsApp, menghapus sesinya, lalu menghapus akun
+func (m *AccountManager) Remove(name string) error {
+	if name == DefaultAccount {
+		return ErrDefaultAccount
+	}
+
+	c, err := m.Get(name)
+	if err != nil {
+		return err
+	}
+
+	if err := c.Logout(); err != nil {
+		return fmt.Errorf("gagal menghapus sesi akun %s: %w", name, err)
+	}
+	c.Close()
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if err := m.helper.Delete(context.Background(), accountKey(name)); err != nil {
+		return fmt.Errorf("gagal menghapus akun: %w", err)
+	}
+	delete(m.accounts, name)
+	delete(m.clients, name)
+
+	m.logger.WithField("account", name).Info("Akun WhatsApp dihapus")
+	return nil
+}
+
+// RegisterCallback mendaftarkan callback event untuk semua akun, termasuk akun yang ditambahkan kemudian
+func (m *AccountManager) RegisterCallback(eventName string, callback func(interface{})) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.callbacks[eventName] = callback
+	for _, c := range m.clients {
+		c.RegisterCallback(eventName, callback)
+	}
+}
+
+// SetReceiptRecorder mengatur pencatat receipt untuk semua akun
+func (m *AccountManager) SetReceiptRecorder(recorder ReceiptRecorder) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.receipts = recorder
+	for _, c := range m.clients {
+		c.SetReceiptRecorder(recorder)
+	}
+}
+
+// ConnectAll menghubungkan semua akun secara paralel. Akun yang belum
+// dipasangkan akan mulai menerima QR code.
+func (m *AccountManager) ConnectAll() {
+	for _, c := range m.Clients() {
+		go func(c *Client) {
+			if err := c.Connect(); err != nil {
+				m.logger.WithError(err).WithField("account", c.Name()).Error("Gagal terhubung ke WhatsApp")
+			}
+		}(c)
+	}
+}
+
+// DisconnectAll menutup koneksi WhatsApp semua akun
+func (m *AccountManager) DisconnectAll() {
+	for _, c := range m.Clients() {
+		c.Disconnect()
+	}
+}
+
+// CloseQRStreams mengakhiri semua stream QR code yang terbuka, dipanggil sebelum server dimatikan
+func (m *AccountManager) CloseQRStreams() {
+	for _, c := range m.Clients() {
+		c.SessionManager.GetQRHandler().CloseSubscribers()
+	}
+}
+
+// Close menutup semua klien dan device store bersama
+func (m *AccountManager) Close() {
+	for _, c := range m.Clients() {
+		c.Close()
+	}
+
+	if err := m.deviceStore.Close(); err != nil {
+		m.logger.WithError(err).Warn("Gagal menutup device store")
+	}
+}
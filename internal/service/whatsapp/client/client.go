@@ -2,8 +2,7 @@ package client
 
 import (
 	"context"
-	"fmt"
-	"os"
+	"errors"
 	"sync"
 	"time"
 
@@ -12,8 +11,8 @@ import (
 	"github.com/gwenziro/bot-notify/internal/utils"
 	"go.mau.fi/whatsmeow"
 	"go.mau.fi/whatsmeow/store/sqlstore"
-
-	_ "modernc.org/sqlite" // Pure Go SQLite driver
+	"go.mau.fi/whatsmeow/types"
+	"go.mau.fi/whatsmeow/types/events"
 )
 
 // ClientStatus menunjukkan status koneksi WhatsApp
@@ -24,8 +23,24 @@ const (
 	StatusConnecting   ClientStatus = "connecting"
 	StatusConnected    ClientStatus = "connected"
 	StatusLoggedOut    ClientStatus = "logged_out"
+	// StatusIdle berarti koneksi diputus karena idle dan tersambung lagi saat mengirim pesan
+	StatusIdle ClientStatus = "idle"
 )
 
+// Statuses mengembalikan semua status koneksi yang mungkin
+func Statuses() []string {
+	return []string{
+		string(StatusDisconnected),
+		string(StatusConnecting),
+		string(StatusConnected),
+		string(StatusLoggedOut),
+		string(StatusIdle),
+	}
+}
+
+// ErrNotConnected dikembalikan ketika operasi membutuhkan koneksi WhatsApp yang aktif
+var ErrNotConnected = errors.New("klien WhatsApp belum terhubung")
+
 // ConnectionState menyimpan informasi status koneksi
 type ConnectionState struct {
 	Status            ClientStatus `json:"status"`
@@ -35,27 +50,41 @@ type ConnectionState struct {
 	Timestamp         time.Time    `json:"timestamp"`
 }
 
+// ReceiptRecorder mencatat waktu kirim dan receipt (delivered/read) untuk pesan keluar
+type ReceiptRecorder interface {
+	RecordSent(messageID types.MessageID, recipient types.JID, sentAt time.Time)
+	RecordReceipt(evt *events.Receipt)
+}
+
 // EventHandlerFunc adalah tipe fungsi untuk menangani event WhatsApp
 type EventHandlerFunc func(interface{})
 
-// Client adalah wrapper untuk klien WhatsApp
+// Client adalah wrapper untuk klien WhatsApp satu akun
 type Client struct {
+	name            string
 	waClient        *whatsmeow.Client
 	eventHandler    uint32
 	deviceStore     *sqlstore.Container
 	connectionState ConnectionState
-	config          *config.WhatsAppConfig
+	config          *config.Live
 	logger          utils.LogrusEntry
 	qrChan          chan string
 	SessionManager  *session.Manager
+	receipts        ReceiptRecorder
 
 	callbackHandlers map[string]func(interface{})
 	reconnectLock    sync.Mutex
+	pairLock         sync.Mutex
 	retryTimer       *time.Timer
 	ctx              context.Context
 	cancel           context.CancelFunc
 }
 
+// Name mengembalikan nama akun yang digunakan klien ini
+func (c *Client) Name() string {
+	return c.name
+}
+
 // GetConnectionState mengembalikan state koneksi saat ini
 func (c *Client) GetConnectionState() ConnectionState {
 	return c.connectionState
@@ -89,47 +118,28 @@ func (c *Client) RegisterCallback(eventName string, callback func(interface{}))
 	c.callbackHandlers[eventName] = callback
 }
 
+// SetReceiptRecorder mengatur pencatat receipt untuk pesan yang dikirim
+func (c *Client) SetReceiptRecorder(recorder ReceiptRecorder) {
+	c.receipts = recorder
+}
+
 // UpdateLastActivity memperbarui timestamp aktivitas terakhir
 func (c *Client) UpdateLastActivity() {
 	c.connectionState.LastActivity = time.Now()
 }
 
-// NewClient membuat instance baru dari klien WhatsApp
-func NewClient(cfg *config.Config) (*Client, error) {
+// newClient membuat klien WhatsApp untuk satu akun. Device store dipakai bersama
+// oleh semua akun dan ditutup oleh AccountManager, bukan oleh klien.
+func newClient(name string, cfg *config.Live, deviceStore *sqlstore.Container) *Client {
 	ctx, cancel := context.WithCancel(context.Background())
 
 	// Gunakan nama modul yang jelas untuk WhatsApp client
-	logger := utils.ForModule("client")
-
-	// Make sure to call cancel() if we encounter an error
-	defer func() {
-		if r := recover(); r != nil {
-			cancel() // Make sure to call cancel if we panic
-			logger.Error("Panic saat membuat client", utils.Fields{"error": r})
-		}
-	}()
-
-	// Pastikan direktori penyimpanan ada
-	if err := os.MkdirAll(cfg.WhatsApp.StoreDir, 0755); err != nil {
-		cancel() // Call cancel to prevent context leak
-		return nil, fmt.Errorf("gagal membuat direktori penyimpanan: %w", err)
-	}
-
-	// Buat klien WhatsApp
-	dbPath := fmt.Sprintf("%s/store.db", cfg.WhatsApp.StoreDir)
+	logger := utils.ForModule("client").WithField("account", name)
 
-	waLogger := NewWhatsmeowLogger(logger)
-
-	deviceStore, err := sqlstore.New(ctx, "sqlite", fmt.Sprintf("file:%s?_pragma=foreign_keys(1)", dbPath), waLogger)
-	if err != nil {
-		cancel() // Call cancel to prevent context leak
-		return nil, fmt.Errorf("gagal membuat device store: %w", err)
-	}
-
-	// Create the client with dependencies injected
 	client := &Client{
+		name:             name,
 		deviceStore:      deviceStore,
-		config:           &cfg.WhatsApp,
+		config:           cfg,
 		logger:           logger,
 		qrChan:           make(chan string, 1),
 		callbackHandlers: make(map[string]func(interface{})),
@@ -145,8 +155,13 @@ func NewClient(cfg *config.Config) (*Client, error) {
 		reconnectLock: sync.Mutex{},
 	}
 
-	// Create session manager with callback
+	// Buat session manager dan daftarkan listener QR code
 	client.SessionManager = session.NewManager(cfg, logger, deviceStore)
+	client.SessionManager.SetClient(client)
+	client.SessionManager.SetupQRCodeListener()
+
+	// Putus koneksi yang idle terlalu lama sesuai whatsapp.idle_timeout
+	go client.watchIdle()
 
-	return client, nil
+	return client
 }
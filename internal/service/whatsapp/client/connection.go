@@ -1,14 +1,19 @@
 package client
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"time"
 
+	"github.com/gwenziro/bot-notify/internal/metrics"
 	"github.com/gwenziro/bot-notify/internal/utils"
 	"go.mau.fi/whatsmeow"
 )
 
+// logoutTimeout adalah batas waktu meminta server WhatsApp memutus perangkat
+const logoutTimeout = 15 * time.Second
+
 // Connect menginisialisasi klien WhatsApp dan mencoba terhubung
 func (c *Client) Connect() error {
 	c.logger.Info("Mencoba menghubungkan ke WhatsApp")
@@ -45,18 +50,15 @@ func (c *Client) Connect() error {
 			return fmt.Errorf("gagal memulai koneksi: %w", err)
 		}
 
-		// Tunggu QR code atau berhasil login
+		// Tunggu item pertama dari QR channel. QR code berikutnya (berganti sekitar
+		// setiap 20 detik), pairing berhasil, dan timeout diproses di background.
 		select {
-		case qrCode := <-qrChan:
-			// Tampilkan QR code untuk dipindai
-			qrCodeData := qrCode.Code
-			c.logger.Info("QR Code diterima, scan untuk login:")
-			c.logger.Info(fmt.Sprintf("QR Code: %s", qrCodeData))
-
-			// Jalankan callback QR Code jika ada
-			if callback, ok := c.callbackHandlers["QRCode"]; ok {
-				callback(qrCodeData)
+		case item, ok := <-qrChan:
+			if !ok {
+				return errors.New("QR channel ditutup sebelum QR code diterima")
 			}
+			c.handleQRItem(item)
+			go c.watchQRChannel(qrChan)
 
 			// Tunggu hingga terhubung atau timeout
 			for {
@@ -97,6 +99,22 @@ func (c *Client) Disconnect() {
 	c.waClient.Disconnect()
 }
 
+// Logout memutus perangkat dari akun WhatsApp di ponsel lalu menghapus sesinya.
+// Jika perangkat belum login, hanya sesi lokal yang dihapus.
+func (c *Client) Logout() error {
+	if c.waClient != nil && c.waClient.IsLoggedIn() {
+		ctx, cancel := context.WithTimeout(c.ctx, logoutTimeout)
+		defer cancel()
+
+		if err := c.waClient.Logout(ctx); err != nil {
+			c.logger.WithError(err).Warn("Gagal logout dari server WhatsApp, sesi lokal tetap dihapus")
+		}
+	}
+
+	c.Disconnect()
+	return c.SessionManager.ClearSessions()
+}
+
 // AttemptReconnect mencoba reconnect dengan exponential backoff
 func (c *Client) AttemptReconnect(reason string) {
 	c.reconnectLock.Lock()
@@ -107,21 +125,23 @@ func (c *Client) AttemptReconnect(reason string) {
 		c.retryTimer.Stop()
 	}
 
-	// Batas maksimum percobaan
-	if c.connectionState.ConnectionRetries >= c.config.MaxRetry {
+	// Batas maksimum percobaan, dibaca setiap kali karena dapat diubah saat berjalan
+	cfg := c.config.Get().WhatsApp
+	if c.connectionState.ConnectionRetries >= cfg.MaxRetry {
 		c.logger.WithFields(utils.Fields{
-			"max_retries": c.config.MaxRetry,
+			"max_retries": cfg.MaxRetry,
 			"reason":      reason,
 		}).Error("Mencapai batas maksimum percobaan reconnect")
 		return
 	}
 
 	c.connectionState.ConnectionRetries++
+	metrics.ReconnectAttempts.WithLabelValues(reason).Inc()
 
 	// Hitung waktu delay dengan exponential backoff
 	delay := time.Duration(1<<uint(c.connectionState.ConnectionRetries-1)) * time.Second
-	if delay > c.config.RetryDelay {
-		delay = c.config.RetryDelay
+	if delay > cfg.RetryDelay {
+		delay = cfg.RetryDelay
 	}
 
 	c.logger.WithFields(utils.Fields{
@@ -165,8 +185,24 @@ func (c *Client) Close() {
 		c.waClient.Disconnect()
 	}
 
-	// Tutup device store
-	if c.deviceStore != nil {
-		c.deviceStore.Close()
+}
+
+// watchQRChannel meneruskan item QR channel sampai channel ditutup oleh whatsmeow
+func (c *Client) watchQRChannel(qrChan <-chan whatsmeow.QRChannelItem) {
+	for item := range qrChan {
+		c.handleQRItem(item)
+	}
+}
+
+// handleQRItem meneruskan satu item QR channel ke callback QRCode
+func (c *Client) handleQRItem(item whatsmeow.QRChannelItem) {
+	if item.Event == whatsmeow.QRChannelEventCode {
+		c.logger.WithField("timeout", item.Timeout).Info("QR Code diterima, scan untuk login")
+	} else {
+		c.logger.WithField("event", item.Event).Info("Status pairing QR code berubah")
+	}
+
+	if callback, ok := c.callbackHandlers["QRCode"]; ok {
+		callback(item)
 	}
 }
@@ -2,11 +2,16 @@ package client
 
 import (
 	"fmt"
+	"time"
 
 	"github.com/gwenziro/bot-notify/internal/utils"
+	"go.mau.fi/whatsmeow"
 	"go.mau.fi/whatsmeow/types/events"
 )
 
+// firstQRTimeout adalah masa berlaku QR code pertama dari server WhatsApp
+const firstQRTimeout = 60 * time.Second
+
 // registerEventHandler mendaftarkan handler untuk event WhatsApp
 func (c *Client) registerEventHandler() uint32 {
 	if c.waClient == nil {
@@ -19,6 +24,8 @@ func (c *Client) registerEventHandler() uint32 {
 		switch v := evt.(type) {
 		case *events.QR:
 			c.handleQRCodeEvent(v)
+		case *events.PairSuccess:
+			c.handlePairSuccessEvent(v)
 		case *events.Connected:
 			c.handleConnectedEvent()
 		case *events.Disconnected:
@@ -27,6 +34,8 @@ func (c *Client) registerEventHandler() uint32 {
 			c.handleLoggedOutEvent(v)
 		case *events.Message:
 			c.handleMessageEvent(v)
+		case *events.Receipt:
+			c.handleReceiptEvent(v)
 		}
 
 		// Panggil callback kustom jika ada
@@ -54,12 +63,25 @@ func (c *Client) handleQRCodeEvent(evt *events.QR) {
 	// Log QR code information
 	c.logger.Info("QR code diterima, siap untuk dipindai")
 
-	// Jalankan callback QR Code jika ada
+	// Jalankan callback QR Code jika ada. Kode pertama berlaku 60 detik, sama seperti QR channel.
 	if callback, ok := c.callbackHandlers["QRCode"]; ok {
-		callback(qrCodeStr)
+		callback(whatsmeow.QRChannelItem{
+			Event:   whatsmeow.QRChannelEventCode,
+			Code:    qrCodeStr,
+			Timeout: firstQRTimeout,
+		})
 	}
 }
 
+// handlePairSuccessEvent menangani event PairSuccess, mencatat device baru milik akun ini
+func (c *Client) handlePairSuccessEvent(evt *events.PairSuccess) {
+	c.logger.WithFields(utils.Fields{
+		"id":       evt.ID.String(),
+		"platform": evt.Platform,
+	}).Info("Perangkat berhasil dipasangkan")
+	c.SessionManager.SetDeviceJID(evt.ID)
+}
+
 // handleConnectedEvent menangani event Connected
 func (c *Client) handleConnectedEvent() {
 	c.logger.Info("Terhubung ke WhatsApp")
@@ -96,3 +118,17 @@ func (c *Client) handleMessageEvent(evt *events.Message) {
 	// Update aktivitas terakhir ketika menerima pesan
 	c.UpdateLastActivity()
 }
+
+// handleReceiptEvent menangani event receipt (delivered/read) untuk pesan yang dikirim
+func (c *Client) handleReceiptEvent(evt *events.Receipt) {
+	c.logger.WithFields(utils.Fields{
+		"chat":        evt.Chat.String(),
+		"sender":      evt.Sender.String(),
+		"type":        string(evt.Type),
+		"message_ids": evt.MessageIDs,
+	}).Debug("Receipt diterima")
+
+	if c.receipts != nil {
+		c.receipts.RecordReceipt(evt)
+	}
+}
@@ -0,0 +1,111 @@
+package client
+
+import (
+	"errors"
+	"time"
+
+	"github.com/gwenziro/bot-notify/internal/utils"
+	"go.mau.fi/whatsmeow"
+)
+
+const (
+	// idleCheckInterval adalah jarak pemeriksaan koneksi yang idle
+	idleCheckInterval = time.Minute
+
+	// wakeTimeout adalah batas waktu menunggu koneksi idle terhubung kembali sebelum mengirim
+	wakeTimeout = 20 * time.Second
+)
+
+// watchIdle memutus koneksi yang tidak aktif lebih lama dari whatsapp.idle_timeout sampai klien ditutup
+func (c *Client) watchIdle() {
+	ticker := time.NewTicker(idleCheckInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-c.ctx.Done():
+			return
+		case now := <-ticker.C:
+			c.checkIdle(now)
+		}
+	}
+}
+
+// checkIdle memutus koneksi jika aktivitas terakhir lebih lama dari batas idle. Batas dibaca
+// setiap pemeriksaan karena dapat diubah saat berjalan, 0 menonaktifkan pemutusan.
+func (c *Client) checkIdle(now time.Time) {
+	timeout := c.config.Get().WhatsApp.IdleTimeout
+	if timeout <= 0 {
+		return
+	}
+
+	c.reconnectLock.Lock()
+	defer c.reconnectLock.Unlock()
+
+	idle := now.Sub(c.connectionState.LastActivity)
+	if c.waClient == nil || !c.connectionState.IsConnected || idle < timeout {
+		return
+	}
+
+	c.logger.WithFields(utils.Fields{
+		"idle":    idle.Round(time.Second),
+		"timeout": timeout,
+	}).Info("Koneksi idle, memutus koneksi WhatsApp sampai ada pesan yang dikirim")
+
+	// Disconnect yang disengaja tidak memicu event Disconnected, sehingga tidak ada reconnect otomatis
+	c.waClient.Disconnect()
+	c.SetConnectionState(StatusIdle, false, 0)
+}
+
+// CanSend memeriksa apakah klien terhubung, atau diputus karena idle dan akan
+// terhubung kembali saat mengirim pesan
+func (c *Client) CanSend() bool {
+	state := c.connectionState
+	return state.IsConnected || state.Status == StatusIdle
+}
+
+// ensureConnected menghubungkan kembali klien yang diputus karena idle dan menunggu
+// sampai terhubung. Mengembalikan false jika klien tidak terhubung.
+func (c *Client) ensureConnected() bool {
+	if c.waClient != nil && c.connectionState.IsConnected {
+		return true
+	}
+	if c.waClient == nil || c.connectionState.Status != StatusIdle {
+		return false
+	}
+
+	c.reconnectLock.Lock()
+	if c.connectionState.Status == StatusIdle {
+		c.logger.Info("Menghubungkan kembali koneksi idle untuk mengirim pesan")
+		c.connectionState.Status = StatusConnecting
+
+		err := c.waClient.Connect()
+		if err != nil && !errors.Is(err, whatsmeow.ErrAlreadyConnected) {
+			c.logger.WithError(err).Error("Gagal menghubungkan kembali koneksi idle")
+			c.connectionState.Status = StatusIdle
+			c.reconnectLock.Unlock()
+			return false
+		}
+	}
+	c.reconnectLock.Unlock()
+
+	// Status terhubung diisi oleh event Connected
+	deadline := time.NewTimer(wakeTimeout)
+	defer deadline.Stop()
+	for !c.connectionState.IsConnected {
+		select {
+		case <-c.ctx.Done():
+			return false
+		case <-deadline.C:
+			c.reconnectLock.Lock()
+			if c.connectionState.Status == StatusConnecting {
+				c.connectionState.Status = StatusIdle
+			}
+			c.reconnectLock.Unlock()
+			c.logger.WithField("timeout", wakeTimeout).Warn("Waktu habis menunggu koneksi idle terhubung kembali")
+			return false
+		case <-time.After(100 * time.Millisecond):
+		}
+	}
+	return true
+}
@@ -4,16 +4,23 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"mime"
+	"net/http"
+	"path/filepath"
+	"time"
 
+	"github.com/gwenziro/bot-notify/internal/metrics"
 	"github.com/gwenziro/bot-notify/internal/utils"
+	"go.mau.fi/whatsmeow"
 	waProto "go.mau.fi/whatsmeow/binary/proto"
 	"go.mau.fi/whatsmeow/types"
+	"google.golang.org/protobuf/proto"
 )
 
 // SendMessage mengirim pesan teks ke nomor atau grup tertentu
-func (c *Client) SendMessage(recipient types.JID, message string) error {
-	if c.waClient == nil || !c.connectionState.IsConnected {
-		return errors.New("klien WhatsApp belum terhubung")
+func (c *Client) SendMessage(recipient types.JID, message string) (types.MessageID, error) {
+	if !c.ensureConnected() {
+		return "", c.notConnected(recipient)
 	}
 
 	c.logger.WithFields(utils.Fields{
@@ -23,23 +30,26 @@ func (c *Client) SendMessage(recipient types.JID, message string) error {
 
 	// Update aktivitas
 	c.UpdateLastActivity()
+	started := time.Now()
 
 	// Kirim pesan
-	_, err := c.waClient.SendMessage(context.Background(), recipient, &waProto.Message{
+	resp, err := c.waClient.SendMessage(context.Background(), recipient, &waProto.Message{
 		Conversation: &message,
 	})
+	c.observeSend(recipient, started, err)
 
 	if err != nil {
-		return fmt.Errorf("gagal mengirim pesan: %w", err)
+		return "", fmt.Errorf("gagal mengirim pesan: %w", err)
 	}
 
-	return nil
+	c.recordSent(resp, recipient)
+	return resp.ID, nil
 }
 
 // SendFormattedMessage mengirim pesan dengan format khusus (bold, italic, dll)
-func (c *Client) SendFormattedMessage(recipient types.JID, message string) error {
-	if c.waClient == nil || !c.connectionState.IsConnected {
-		return errors.New("klien WhatsApp belum terhubung")
+func (c *Client) SendFormattedMessage(recipient types.JID, message string) (types.MessageID, error) {
+	if !c.ensureConnected() {
+		return "", c.notConnected(recipient)
 	}
 
 	c.logger.WithFields(utils.Fields{
@@ -50,20 +60,181 @@ func (c *Client) SendFormattedMessage(recipient types.JID, message string) error
 
 	// Update aktivitas
 	c.UpdateLastActivity()
+	started := time.Now()
 
 	// Konversi ke ExtendedTextMessage untuk dukungan format
-	_, err := c.waClient.SendMessage(context.Background(), recipient, &waProto.Message{
+	resp, err := c.waClient.SendMessage(context.Background(), recipient, &waProto.Message{
 		ExtendedTextMessage: &waProto.ExtendedTextMessage{
 			Text: &message,
 			// Bisa ditambahkan opsi pemformatan lainnya
 		},
 	})
+	c.observeSend(recipient, started, err)
 
 	if err != nil {
-		return fmt.Errorf("gagal mengirim pesan terformat: %w", err)
+		return "", fmt.Errorf("gagal mengirim pesan terformat: %w", err)
 	}
 
-	return nil
+	c.recordSent(resp, recipient)
+	return resp.ID, nil
+}
+
+// SendDocument mengunggah dokumen (PDF, CSV, XLSX, dll) lalu mengirimkannya dengan nama file dan caption
+func (c *Client) SendDocument(recipient types.JID, data []byte, fileName string, mimeType string, caption string) (types.MessageID, error) {
+	if !c.ensureConnected() {
+		return "", c.notConnected(recipient)
+	}
+
+	if len(data) == 0 {
+		return "", errors.New("data dokumen kosong")
+	}
+
+	if fileName == "" {
+		fileName = "document"
+	}
+
+	// Tentukan MIME type dari ekstensi file, lalu dari konten jika masih kosong
+	if mimeType == "" {
+		mimeType = mime.TypeByExtension(filepath.Ext(fileName))
+	}
+	if mimeType == "" {
+		mimeType = http.DetectContentType(data)
+	}
+
+	c.logger.WithFields(utils.Fields{
+		"to":          recipient.String(),
+		"file_name":   fileName,
+		"size":        len(data),
+		"mime_type":   mimeType,
+		"has_caption": caption != "",
+	}).Info("Mengirim dokumen")
+
+	// Update aktivitas
+	c.UpdateLastActivity()
+	started := time.Now()
+
+	// Unggah dokumen terenkripsi ke server media WhatsApp
+	uploaded, err := c.waClient.Upload(context.Background(), data, whatsmeow.MediaDocument)
+	if err != nil {
+		c.observeSend(recipient, started, err)
+		return "", fmt.Errorf("gagal mengunggah dokumen: %w", err)
+	}
+
+	documentMsg := &waProto.DocumentMessage{
+		URL:           proto.String(uploaded.URL),
+		DirectPath:    proto.String(uploaded.DirectPath),
+		MediaKey:      uploaded.MediaKey,
+		Mimetype:      proto.String(mimeType),
+		FileEncSHA256: uploaded.FileEncSHA256,
+		FileSHA256:    uploaded.FileSHA256,
+		FileLength:    proto.Uint64(uploaded.FileLength),
+		FileName:      proto.String(fileName),
+		Title:         proto.String(fileName),
+	}
+	if caption != "" {
+		documentMsg.Caption = proto.String(caption)
+	}
+
+	// Kirim pesan dokumen
+	resp, err := c.waClient.SendMessage(context.Background(), recipient, &waProto.Message{
+		DocumentMessage: documentMsg,
+	})
+	c.observeSend(recipient, started, err)
+
+	if err != nil {
+		return "", fmt.Errorf("gagal mengirim dokumen: %w", err)
+	}
+
+	c.recordSent(resp, recipient)
+	return resp.ID, nil
+}
+
+// SendImage mengunggah gambar ke server WhatsApp lalu mengirimkannya dengan caption opsional
+func (c *Client) SendImage(recipient types.JID, data []byte, mimeType string, caption string) (types.MessageID, error) {
+	if !c.ensureConnected() {
+		return "", c.notConnected(recipient)
+	}
+
+	if len(data) == 0 {
+		return "", errors.New("data gambar kosong")
+	}
+
+	// Deteksi MIME type jika tidak disediakan oleh pemanggil
+	if mimeType == "" {
+		mimeType = http.DetectContentType(data)
+	}
+
+	c.logger.WithFields(utils.Fields{
+		"to":          recipient.String(),
+		"size":        len(data),
+		"mime_type":   mimeType,
+		"has_caption": caption != "",
+	}).Info("Mengirim gambar")
+
+	// Update aktivitas
+	c.UpdateLastActivity()
+	started := time.Now()
+
+	// Unggah gambar terenkripsi ke server media WhatsApp
+	uploaded, err := c.waClient.Upload(context.Background(), data, whatsmeow.MediaImage)
+	if err != nil {
+		c.observeSend(recipient, started, err)
+		return "", fmt.Errorf("gagal mengunggah gambar: %w", err)
+	}
+
+	imageMsg := &waProto.ImageMessage{
+		URL:           proto.String(uploaded.URL),
+		DirectPath:    proto.String(uploaded.DirectPath),
+		MediaKey:      uploaded.MediaKey,
+		Mimetype:      proto.String(mimeType),
+		FileEncSHA256: uploaded.FileEncSHA256,
+		FileSHA256:    uploaded.FileSHA256,
+		FileLength:    proto.Uint64(uploaded.FileLength),
+	}
+	if caption != "" {
+		imageMsg.Caption = proto.String(caption)
+	}
+
+	// Kirim pesan gambar
+	resp, err := c.waClient.SendMessage(context.Background(), recipient, &waProto.Message{
+		ImageMessage: imageMsg,
+	})
+	c.observeSend(recipient, started, err)
+
+	if err != nil {
+		return "", fmt.Errorf("gagal mengirim gambar: %w", err)
+	}
+
+	c.recordSent(resp, recipient)
+	return resp.ID, nil
+}
+
+// observeSend mencatat metric hasil pengiriman berdasarkan tipe penerima
+func (c *Client) observeSend(recipient types.JID, started time.Time, err error) {
+	metrics.ObserveSend(sendType(recipient), started, err)
+}
+
+// notConnected mencatat pengiriman yang gagal karena klien belum terhubung, lalu
+// mengembalikan ErrNotConnected. Durasi tidak dicatat karena tidak ada yang dikirim.
+func (c *Client) notConnected(recipient types.JID) error {
+	metrics.MessagesFailed.WithLabelValues(sendType(recipient)).Inc()
+	return ErrNotConnected
+}
+
+// sendType mengembalikan label tipe penerima untuk metric pengiriman
+func sendType(recipient types.JID) string {
+	if recipient.Server == types.GroupServer {
+		return "group"
+	}
+	return "personal"
+}
+
+// recordSent meneruskan pesan yang berhasil dikirim ke pencatat receipt jika ada
+func (c *Client) recordSent(resp whatsmeow.SendResponse, recipient types.JID) {
+	if c.receipts == nil {
+		return
+	}
+	c.receipts.RecordSent(resp.ID, recipient, resp.Timestamp)
 }
 
 // BroadcastMessage mengirim pesan ke beberapa penerima sekaligus
@@ -71,7 +242,7 @@ func (c *Client) BroadcastMessage(recipients []types.JID, message string) map[st
 	results := make(map[string]error)
 
 	for _, recipient := range recipients {
-		err := c.SendMessage(recipient, message)
+		_, err := c.SendMessage(recipient, message)
 		results[recipient.String()] = err
 	}
 
@@ -0,0 +1,70 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/gwenziro/bot-notify/internal/utils"
+	"go.mau.fi/whatsmeow"
+)
+
+const (
+	// pairClientDisplayName adalah nama perangkat yang ditampilkan di ponsel.
+	// WhatsApp hanya menerima format "Browser (OS)" dengan browser dan OS yang umum.
+	pairClientDisplayName = "Chrome (Linux)"
+
+	// pairPhoneTimeout adalah batas waktu meminta kode pairing ke server WhatsApp
+	pairPhoneTimeout = 30 * time.Second
+)
+
+var (
+	// ErrAlreadyPaired dikembalikan ketika perangkat sudah dipasangkan
+	ErrAlreadyPaired = errors.New("perangkat WhatsApp sudah dipasangkan")
+
+	// ErrInvalidPhoneNumber dikembalikan ketika nomor telepon untuk pairing tidak valid
+	ErrInvalidPhoneNumber = errors.New("nomor telepon tidak valid")
+)
+
+// PairPhone meminta kode pairing 8 karakter untuk menautkan perangkat dengan nomor telepon,
+// sebagai alternatif memindai QR code. Kode dimasukkan di WhatsApp melalui
+// Perangkat tertaut > Tautkan perangkat > Tautkan dengan nomor telepon.
+func (c *Client) PairPhone(phoneNumber string) (string, error) {
+	c.pairLock.Lock()
+	defer c.pairLock.Unlock()
+
+	jid := ParsePhoneNumber(phoneNumber)
+	if len(jid.User) < 7 {
+		return "", ErrInvalidPhoneNumber
+	}
+
+	if c.IsLoggedIn() {
+		return "", ErrAlreadyPaired
+	}
+
+	// Pairing dengan kode membutuhkan websocket login yang sama dengan alur QR code.
+	// Connect menunggu QR code pertama sehingga koneksi sudah siap setelahnya.
+	if c.waClient == nil || !c.waClient.IsConnected() {
+		if err := c.Connect(); err != nil {
+			return "", fmt.Errorf("gagal terhubung untuk pairing: %w", err)
+		}
+		if c.IsLoggedIn() {
+			return "", ErrAlreadyPaired
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(c.ctx, pairPhoneTimeout)
+	defer cancel()
+
+	code, err := c.waClient.PairPhone(ctx, jid.User, true, whatsmeow.PairClientChrome, pairClientDisplayName)
+	if err != nil {
+		return "", fmt.Errorf("gagal meminta kode pairing: %w", err)
+	}
+
+	c.logger.WithFields(utils.Fields{
+		"nomor": jid.User,
+	}).Info("Kode pairing dibuat, masukkan kode di WhatsApp untuk menautkan perangkat")
+
+	return code, nil
+}
@@ -2,7 +2,6 @@ package client
 
 import (
 	"context"
-	"errors"
 	"fmt"
 
 	"go.mau.fi/whatsmeow/types"
@@ -10,8 +9,8 @@ import (
 
 // GetGroups mengembalikan daftar grup yang tersedia
 func (c *Client) GetGroups() ([]*types.GroupInfo, error) {
-	if c.waClient == nil || !c.connectionState.IsConnected {
-		return nil, errors.New("klien WhatsApp belum terhubung")
+	if !c.ensureConnected() {
+		return nil, ErrNotConnected
 	}
 
 	c.logger.Info("Mengambil daftar grup")
@@ -27,8 +26,8 @@ func (c *Client) GetGroups() ([]*types.GroupInfo, error) {
 
 // GetGroupByID mencari grup berdasarkan ID
 func (c *Client) GetGroupByID(groupID string) (*types.GroupInfo, error) {
-	if c.waClient == nil || !c.connectionState.IsConnected {
-		return nil, errors.New("klien WhatsApp belum terhubung")
+	if !c.ensureConnected() {
+		return nil, ErrNotConnected
 	}
 
 	// Konversi ID ke JID
@@ -45,8 +44,8 @@ func (c *Client) GetGroupByID(groupID string) (*types.GroupInfo, error) {
 
 // GetContactInfo mendapatkan informasi kontak
 func (c *Client) GetContactInfo(phoneNumber string) (*types.ContactInfo, error) {
-	if c.waClient == nil || !c.connectionState.IsConnected {
-		return nil, errors.New("klien WhatsApp belum terhubung")
+	if !c.ensureConnected() {
+		return nil, ErrNotConnected
 	}
 
 	// Konversi nomor telepon ke JID
@@ -3,26 +3,31 @@ package session
 import (
 	"context"
 	"fmt"
-	"path/filepath"
+	"sync"
 
 	"github.com/gwenziro/bot-notify/internal/config"
 	"github.com/gwenziro/bot-notify/internal/utils"
+	"go.mau.fi/whatsmeow"
 	"go.mau.fi/whatsmeow/store"
 	"go.mau.fi/whatsmeow/store/sqlstore"
-	waLog "go.mau.fi/whatsmeow/util/log"
+	"go.mau.fi/whatsmeow/types"
 )
 
-// Manager menangani sesi WhatsApp dan QR code
+// Manager menangani sesi dan QR code satu akun WhatsApp. Device store dipakai
+// bersama oleh semua akun, setiap akun hanya memakai device miliknya sendiri.
 type Manager struct {
-	config      *config.Config
+	config      *config.Live
 	logger      utils.LogrusEntry
 	deviceStore *sqlstore.Container
 	qrHandler   *QRHandler
 	client      interface{} // Akan disimpan referensi ke client
+
+	mu        sync.RWMutex
+	deviceJID types.JID // Kosong jika akun belum dipasangkan
 }
 
 // NewManager membuat instance baru Manager
-func NewManager(cfg *config.Config, logger utils.LogrusEntry, deviceStore *sqlstore.Container) *Manager {
+func NewManager(cfg *config.Live, logger utils.LogrusEntry, deviceStore *sqlstore.Container) *Manager {
 	manager := &Manager{
 		config:      cfg,
 		logger:      logger.WithField("component", "session-manager"),
@@ -30,8 +35,7 @@ func NewManager(cfg *config.Config, logger utils.LogrusEntry, deviceStore *sqlst
 	}
 
 	// Buat QR handler
-	qrCodePath := filepath.Join(cfg.WhatsApp.QrCodeDir, "latest-qr.png")
-	manager.qrHandler = NewQRHandler(cfg, logger, qrCodePath)
+	manager.qrHandler = NewQRHandler(logger)
 
 	return manager
 }
@@ -53,83 +57,93 @@ func (m *Manager) SetupQRCodeListener() {
 		return
 	}
 
-	// Mendaftarkan callback untuk QR code yang akan memanggil QR handler
+	// Mendaftarkan callback untuk setiap item QR channel: QR code baru,
+	// pairing berhasil, QR code habis, atau pairing gagal
 	client.RegisterCallback("QRCode", func(data interface{}) {
-		qrCode, ok := data.(string)
-		if !ok || qrCode == "" {
+		item, ok := data.(whatsmeow.QRChannelItem)
+		if !ok {
 			m.logger.Warn("QR code tidak valid")
 			return
 		}
 
-		err := m.qrHandler.ProcessQRCode(qrCode)
-		if err != nil {
-			m.logger.WithError(err).Error("Gagal memproses QR code")
+		switch item.Event {
+		case whatsmeow.QRChannelEventCode:
+			if err := m.qrHandler.ProcessQRCode(item.Code, item.Timeout); err != nil {
+				m.logger.WithError(err).Error("Gagal memproses QR code")
+			}
+		case whatsmeow.QRChannelSuccess.Event:
+			m.qrHandler.MarkSuccess()
+		case whatsmeow.QRChannelTimeout.Event:
+			m.qrHandler.MarkTimeout()
+		case whatsmeow.QRChannelEventError:
+			m.qrHandler.MarkError(fmt.Sprint(item.Error))
+		default:
+			m.qrHandler.MarkError(item.Event)
 		}
 	})
 
 	m.logger.Info("QR code listener berhasil diatur")
 }
 
-// GetDevice mengembalikan device store untuk koneksi WhatsApp
+// SetDeviceJID mengatur device yang dipakai akun ini, misalnya setelah pairing berhasil
+func (m *Manager) SetDeviceJID(jid types.JID) {
+	m.mu.Lock()
+	m.deviceJID = jid
+	m.mu.Unlock()
+}
+
+// DeviceJID mengembalikan JID device akun ini, kosong jika belum dipasangkan
+func (m *Manager) DeviceJID() types.JID {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.deviceJID
+}
+
+// GetDevice mengembalikan device store untuk koneksi WhatsApp akun ini.
+// Device baru dibuat jika akun belum dipasangkan atau device-nya sudah dihapus.
 func (m *Manager) GetDevice() (*store.Device, error) {
-	ctx := context.Background()
-	devices, err := m.deviceStore.GetAllDevices(ctx)
-	if err != nil {
-		return nil, fmt.Errorf("gagal mendapatkan devices: %w", err)
+	jid := m.DeviceJID()
+	if jid.IsEmpty() {
+		m.logger.Info("Akun belum dipasangkan, membuat device baru")
+		return m.deviceStore.NewDevice(), nil
 	}
 
-	if len(devices) == 0 {
-		m.logger.Info("Tidak ada device yang disimpan, membuat device baru")
+	device, err := m.deviceStore.GetDevice(context.Background(), jid)
+	if err != nil {
+		return nil, fmt.Errorf("gagal mendapatkan device %s: %w", jid, err)
+	}
+	if device == nil {
+		m.logger.WithField("id", jid.String()).Warn("Device tidak ditemukan di device store, membuat device baru")
 		return m.deviceStore.NewDevice(), nil
 	}
 
-	// Gunakan device pertama yang ditemukan
 	m.logger.WithFields(utils.Fields{
-		"id":   devices[0].ID.String(),
-		"name": devices[0].PushName,
+		"id":   device.ID.String(),
+		"name": device.PushName,
 	}).Info("Menggunakan device yang ada")
-	return devices[0], nil
+	return device, nil
 }
 
-// ClearSessions menghapus semua sesi yang disimpan
+// ClearSessions menghapus sesi akun ini dari device store. Device akun lain tidak terpengaruh.
 func (m *Manager) ClearSessions() error {
-	m.logger.Warn("Menghapus semua sesi WhatsApp")
+	m.logger.Warn("Menghapus sesi WhatsApp")
 
-	ctx := context.Background()
-
-	// Tutup device store saat ini
-	if m.deviceStore != nil {
-		m.deviceStore.Close()
-	}
-
-	// Buat device store baru dengan database yang sama
-	dbPath := fmt.Sprintf("%s/store.db", m.config.WhatsApp.StoreDir)
-	// Gunakan driver sqlite dari modernc.org
-	deviceStore, err := sqlstore.New(ctx, "sqlite", fmt.Sprintf("file:%s?_pragma=foreign_keys(1)", dbPath), waLog.Noop)
-	if err != nil {
-		return fmt.Errorf("gagal membuat device store baru: %w", err)
-	}
-
-	// Hapus semua device
-	devices, err := deviceStore.GetAllDevices(ctx)
-	if err != nil {
-		return fmt.Errorf("gagal mendapatkan devices: %w", err)
-	}
-
-	for _, device := range devices {
-		err := deviceStore.DeleteDevice(ctx, device)
+	jid := m.DeviceJID()
+	if !jid.IsEmpty() {
+		ctx := context.Background()
+		device, err := m.deviceStore.GetDevice(ctx, jid)
 		if err != nil {
-			m.logger.WithFields(utils.Fields{
-				"id":    device.ID.String(),
-				"error": err,
-			}).Error("Gagal menghapus device")
-		} else {
-			m.logger.WithField("id", device.ID.String()).Info("Berhasil menghapus device")
+			return fmt.Errorf("gagal mendapatkan device %s: %w", jid, err)
+		}
+		if device != nil {
+			if err := m.deviceStore.DeleteDevice(ctx, device); err != nil {
+				return fmt.Errorf("gagal menghapus device %s: %w", jid, err)
+			}
+			m.logger.WithField("id", jid.String()).Info("Berhasil menghapus device")
 		}
 	}
 
-	// Perbarui device store di SessionManager
-	m.deviceStore = deviceStore
+	m.SetDeviceJID(types.JID{})
 
 	// Hapus QR code
 	m.qrHandler.ClearQRCode()
@@ -1,209 +1,243 @@
 package session
 
 import (
-	"encoding/base64"
+	"bytes"
+	"errors"
 	"fmt"
-	"io/ioutil"
-	"os"
-	"path/filepath"
 	"strings"
+	"sync"
 	"time"
 
-	"github.com/gwenziro/bot-notify/internal/config"
 	"github.com/gwenziro/bot-notify/internal/utils"
 	"github.com/skip2/go-qrcode"
 )
 
-// QRHandler menangani operasi terkait QR code
+// Jenis event QR code yang dikirim ke subscriber
+const (
+	QREventIdle    = "idle"    // Belum ada QR code
+	QREventCode    = "qr"      // QR code baru tersedia
+	QREventSuccess = "success" // Perangkat berhasil dipasangkan
+	QREventTimeout = "timeout" // QR code habis sebelum dipindai
+	QREventError   = "error"   // Pairing gagal
+)
+
+// subscriberBuffer adalah kapasitas antrean event per subscriber
+const subscriberBuffer = 8
+
+// ErrNoQRCode dikembalikan ketika tidak ada QR code yang berlaku
+var ErrNoQRCode = errors.New("QR code belum tersedia atau sudah kedaluwarsa")
+
+// QRState adalah status QR code login saat ini
+type QRState struct {
+	Event     string    `json:"event"`
+	Code      string    `json:"code,omitempty"`
+	CreatedAt time.Time `json:"createdAt"`
+	ExpiresAt time.Time `json:"expiresAt"`
+	Error     string    `json:"error,omitempty"`
+}
+
+// Available memeriksa apakah state berisi QR code yang masih berlaku
+func (s QRState) Available(now time.Time) bool {
+	return s.Event == QREventCode && s.Code != "" && now.Before(s.ExpiresAt)
+}
+
+// QRHandler menyimpan QR code login terbaru di memori dan mengirim setiap
+// perubahan ke subscriber, misalnya halaman konektivitas melalui SSE
 type QRHandler struct {
-	config     *config.Config
-	logger     utils.LogrusEntry
-	qrCodePath string
-	timestamp  time.Time
+	logger      utils.LogrusEntry
+	mu          sync.RWMutex
+	state       QRState
+	subscribers map[chan QRState]struct{}
+	closed      bool
 }
 
 // NewQRHandler membuat instance baru QRHandler
-func NewQRHandler(cfg *config.Config, logger utils.LogrusEntry, qrCodePath string) *QRHandler {
+func NewQRHandler(logger utils.LogrusEntry) *QRHandler {
 	return &QRHandler{
-		config:     cfg,
-		logger:     logger.WithField("component", "qr-handler"),
-		qrCodePath: qrCodePath,
+		logger:      logger.WithField("component", "qr-handler"),
+		state:       QRState{Event: QREventIdle},
+		subscribers: make(map[chan QRState]struct{}),
 	}
 }
 
-// SaveQRCode menyimpan QR code dari string ke file gambar
-func (h *QRHandler) SaveQRCode(qrCode string) error {
-	// Pastikan direktori QR code ada
-	qrDir := filepath.Dir(h.qrCodePath)
-	if err := os.MkdirAll(qrDir, 0755); err != nil {
-		return fmt.Errorf("gagal membuat direktori QR code: %w", err)
-	}
-
-	// Bersihkan QR code string jika perlu
+// ProcessQRCode menyimpan QR code baru yang berlaku selama timeout dan mengirimkannya ke subscriber
+func (h *QRHandler) ProcessQRCode(qrCode string, timeout time.Duration) error {
 	qrCode = strings.TrimSpace(qrCode)
-
-	// Buat QR code gambar
-	qr, err := qrcode.New(qrCode, qrcode.Medium)
-	if err != nil {
-		return fmt.Errorf("gagal membuat QR code: %w", err)
-	}
-
-	// Simpan ke file
-	png, err := qr.PNG(256)
-	if err != nil {
-		return fmt.Errorf("gagal menghasilkan PNG QR code: %w", err)
+	if qrCode == "" {
+		return fmt.Errorf("QR code kosong")
 	}
 
-	if err := ioutil.WriteFile(h.qrCodePath, png, 0644); err != nil {
-		return fmt.Errorf("gagal menyimpan QR code ke file: %w", err)
-	}
+	h.mu.RLock()
+	duplicate := h.state.Event == QREventCode && h.state.Code == qrCode
+	h.mu.RUnlock()
 
-	// Simpan string QR code untuk debugging
-	txtPath := filepath.Join(filepath.Dir(h.qrCodePath), "latest-qr.txt")
-	if err := ioutil.WriteFile(txtPath, []byte(qrCode), 0644); err != nil {
-		h.logger.WithError(err).Warn("Gagal menyimpan teks QR code")
+	// QR code yang sama bisa diterima dari QR channel dan event QR whatsmeow
+	if duplicate {
+		return nil
 	}
 
-	// Update timestamp
-	h.timestamp = time.Now()
+	// Tampilkan QR code di terminal
+	PrintQRToTerminal(qrCode)
 
-	// Simpan timestamp ke file
-	timestampPath := filepath.Join(filepath.Dir(h.qrCodePath), "qr-timestamp.txt")
-	if err := ioutil.WriteFile(timestampPath, []byte(h.timestamp.Format(time.RFC3339)), 0644); err != nil {
-		h.logger.WithError(err).Warn("Gagal menyimpan timestamp QR code")
-	}
-
-	h.logger.WithFields(utils.Fields{
-		"path":      h.qrCodePath,
-		"timestamp": h.timestamp,
-	}).Info("QR code berhasil disimpan")
+	now := time.Now()
+	h.publish(QRState{
+		Event:     QREventCode,
+		Code:      qrCode,
+		CreatedAt: now,
+		ExpiresAt: now.Add(timeout),
+	})
 
+	h.logger.WithField("expires_in", timeout).Info("QR code baru tersedia")
 	return nil
 }
 
-// GetQRCodeImage mengembalikan QR code sebagai base64 string
-func (h *QRHandler) GetQRCodeImage() (string, time.Time, error) {
-	// Periksa apakah file QR code ada
-	if _, err := os.Stat(h.qrCodePath); os.IsNotExist(err) {
-		return "", time.Time{}, fmt.Errorf("QR code belum dibuat")
-	}
+// MarkSuccess menandai bahwa perangkat berhasil dipasangkan
+func (h *QRHandler) MarkSuccess() {
+	h.logger.Info("Perangkat WhatsApp berhasil dipasangkan")
+	h.publish(QRState{Event: QREventSuccess, CreatedAt: time.Now()})
+}
 
-	// Baca file QR code
-	data, err := ioutil.ReadFile(h.qrCodePath)
-	if err != nil {
-		return "", time.Time{}, fmt.Errorf("gagal membaca file QR code: %w", err)
-	}
+// MarkTimeout menandai bahwa QR code habis sebelum dipindai
+func (h *QRHandler) MarkTimeout() {
+	h.logger.Warn("QR code habis sebelum dipindai")
+	h.publish(QRState{Event: QREventTimeout, CreatedAt: time.Now()})
+}
 
-	// Konversi ke base64
-	b64Data := base64.StdEncoding.EncodeToString(data)
+// MarkError menandai bahwa pairing gagal
+func (h *QRHandler) MarkError(reason string) {
+	h.logger.WithField("reason", reason).Error("Pairing WhatsApp gagal")
+	h.publish(QRState{Event: QREventError, CreatedAt: time.Now(), Error: reason})
+}
 
-	return b64Data, h.timestamp, nil
+// ClearQRCode menghapus QR code yang disimpan
+func (h *QRHandler) ClearQRCode() {
+	h.publish(QRState{Event: QREventIdle, CreatedAt: time.Now()})
 }
 
-// GetQRCodeTimestamp mengembalikan waktu pembuatan QR code terakhir
-func (h *QRHandler) GetQRCodeTimestamp() time.Time {
-	// Jika timestamp sudah diset di memory, gunakan itu
-	if !h.timestamp.IsZero() {
-		return h.timestamp
-	}
+// Current mengembalikan status QR code saat ini
+func (h *QRHandler) Current() QRState {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return h.state
+}
 
-	// Jika tidak, coba baca dari file
-	timestampPath := filepath.Join(filepath.Dir(h.qrCodePath), "qr-timestamp.txt")
-	if _, err := os.Stat(timestampPath); os.IsNotExist(err) {
-		return time.Time{}
+// Subscribe mendaftarkan penerima perubahan status QR code. Status saat ini
+// langsung dikirim sebagai event pertama. Fungsi yang dikembalikan harus
+// dipanggil untuk berhenti berlangganan.
+func (h *QRHandler) Subscribe() (<-chan QRState, func()) {
+	ch := make(chan QRState, subscriberBuffer)
+
+	h.mu.Lock()
+	if h.closed {
+		close(ch)
+	} else {
+		ch <- h.state
+		h.subscribers[ch] = struct{}{}
+	}
+	h.mu.Unlock()
+
+	var once sync.Once
+	return ch, func() {
+		once.Do(func() {
+			h.mu.Lock()
+			delete(h.subscribers, ch)
+			h.mu.Unlock()
+		})
 	}
+}
 
-	data, err := ioutil.ReadFile(timestampPath)
-	if err != nil {
-		return time.Time{}
-	}
+// CloseSubscribers mengakhiri semua subscriber dengan menutup channel-nya, dipanggil
+// sebelum server dimatikan agar stream QR code tidak menahan shutdown
+func (h *QRHandler) CloseSubscribers() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
 
-	t, err := time.Parse(time.RFC3339, string(data))
-	if err != nil {
-		return time.Time{}
+	h.closed = true
+	for ch := range h.subscribers {
+		close(ch)
+		delete(h.subscribers, ch)
 	}
-
-	h.timestamp = t
-	return t
 }
 
-// IsQRCodeExpired memeriksa apakah QR code sudah kedaluwarsa
-func (h *QRHandler) IsQRCodeExpired(maxAgeMinutes int) bool {
-	timestamp := h.GetQRCodeTimestamp()
-	if timestamp.IsZero() {
-		return true
-	}
+// publish menyimpan state baru dan mengirimkannya ke semua subscriber
+func (h *QRHandler) publish(state QRState) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
 
-	return time.Since(timestamp).Minutes() > float64(maxAgeMinutes)
+	h.state = state
+	for ch := range h.subscribers {
+		select {
+		case ch <- state:
+		default:
+			// Subscriber lambat tidak boleh menahan QR code berikutnya
+			h.logger.Warn("Subscriber QR code penuh, event dilewati")
+		}
+	}
 }
 
-// ClearQRCode menghapus QR code yang disimpan
-func (h *QRHandler) ClearQRCode() {
-	// Hapus file QR code
-	if err := os.Remove(h.qrCodePath); err != nil && !os.IsNotExist(err) {
-		h.logger.WithError(err).Warn("Gagal menghapus file QR code")
+// PNG merender QR code yang berlaku sebagai gambar PNG
+func (h *QRHandler) PNG(size int) ([]byte, error) {
+	qr, err := h.current()
+	if err != nil {
+		return nil, err
 	}
 
-	// Hapus file text QR code
-	txtPath := filepath.Join(filepath.Dir(h.qrCodePath), "latest-qr.txt")
-	if err := os.Remove(txtPath); err != nil && !os.IsNotExist(err) {
-		h.logger.WithError(err).Warn("Gagal menghapus file text QR code")
+	png, err := qr.PNG(size)
+	if err != nil {
+		return nil, fmt.Errorf("gagal menghasilkan PNG QR code: %w", err)
 	}
-
-	// Reset timestamp
-	h.timestamp = time.Time{}
+	return png, nil
 }
 
-// GetQRCodeData mengembalikan QR code data saat ini dan timestamp ketika dibuat
-func (h *QRHandler) GetQRCodeData() (string, time.Time) {
-	txtPath := filepath.Join(filepath.Dir(h.qrCodePath), "latest-qr.txt")
-	if _, err := os.Stat(txtPath); os.IsNotExist(err) {
-		// File QR code tidak ada
-		return "", time.Time{}
-	}
-
-	// Baca QR code dari file
-	qrData, err := ioutil.ReadFile(txtPath)
+// SVG merender QR code yang berlaku sebagai gambar SVG
+func (h *QRHandler) SVG() ([]byte, error) {
+	qr, err := h.current()
 	if err != nil {
-		h.logger.WithError(err).Error("Gagal membaca file QR code")
-		return "", time.Time{}
+		return nil, err
 	}
 
-	// Dapatkan timestamp
-	timestamp := h.GetQRCodeTimestamp()
+	bitmap := qr.Bitmap()
+	size := len(bitmap)
 
-	return string(qrData), timestamp
-}
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, `<svg xmlns="http://www.w3.org/2000/svg" viewBox="0 0 %d %d" shape-rendering="crispEdges">`, size, size)
+	buf.WriteString(`<rect width="100%" height="100%" fill="#fff"/><path fill="#000" d="`)
 
-// ProcessQRCode menangani semua aspek pemrosesan QR code
-func (h *QRHandler) ProcessQRCode(qrCode string) error {
-	// Validasi QR code
-	qrCode = strings.TrimSpace(qrCode)
-	if qrCode == "" {
-		return fmt.Errorf("QR code kosong")
+	// Gabungkan modul hitam yang berurutan dalam satu baris menjadi satu persegi panjang
+	for y, row := range bitmap {
+		for x := 0; x < len(row); {
+			if !row[x] {
+				x++
+				continue
+			}
+			start := x
+			for x < len(row) && row[x] {
+				x++
+			}
+			fmt.Fprintf(&buf, "M%d %dh%dv1h-%dz", start, y, x-start, x-start)
+		}
 	}
 
-	// 1. Tampilkan QR code di terminal
-	printQRToTerminal(qrCode)
+	buf.WriteString(`"/></svg>`)
+	return buf.Bytes(), nil
+}
 
-	// 2. Simpan QR code ke file
-	if err := h.SaveQRCode(qrCode); err != nil {
-		return fmt.Errorf("gagal menyimpan QR code: %w", err)
+// current membuat QR code dari state saat ini jika masih berlaku
+func (h *QRHandler) current() (*qrcode.QRCode, error) {
+	state := h.Current()
+	if !state.Available(time.Now()) {
+		return nil, ErrNoQRCode
 	}
 
-	// 3. Perbarui timestamp
-	h.timestamp = time.Now()
-
-	return nil
-}
-
-// GetQRCodePath mengembalikan path file QR code
-func (h *QRHandler) GetQRCodePath() string {
-	return h.qrCodePath
+	qr, err := qrcode.New(state.Code, qrcode.Medium)
+	if err != nil {
+		return nil, fmt.Errorf("gagal membuat QR code: %w", err)
+	}
+	return qr, nil
 }
 
-// printQRToTerminal menampilkan QR code di terminal sebagai ASCII art
-func printQRToTerminal(qrCodeStr string) {
+// PrintQRToTerminal menampilkan QR code di terminal sebagai ASCII art
+func PrintQRToTerminal(qrCodeStr string) {
 
 	qr, err := qrcode.New(qrCodeStr, qrcode.Medium)
 	if err != nil {
@@ -0,0 +1,124 @@
+// Package sse menyediakan helper untuk mengirim Server-Sent Events melalui Fiber
+package sse
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+const (
+	// keepAliveInterval adalah interval komentar keep-alive agar koneksi tidak ditutup proxy
+	keepAliveInterval = 15 * time.Second
+
+	// retryMillis adalah jeda yang disarankan ke browser sebelum menyambung ulang
+	retryMillis = 2000
+)
+
+// Event adalah satu event yang dikirim ke klien. Data di-encode sebagai JSON.
+type Event struct {
+	Name string
+	// ID dikirim sebagai field id, browser mengirimnya kembali di header Last-Event-ID
+	// saat menyambung ulang. Kosong berarti tanpa ID.
+	ID   string
+	Data interface{}
+}
+
+// Identified diimplementasikan nilai stream yang memiliki ID event
+type Identified interface {
+	EventID() string
+}
+
+// LastEventID mengembalikan ID event terakhir yang diterima klien sebelum menyambung ulang
+func LastEventID(c *fiber.Ctx) string {
+	return c.Get("Last-Event-ID")
+}
+
+// IsStream memeriksa apakah request meminta stream SSE, dipakai untuk melewati
+// middleware yang membuffer respons seperti kompresi
+func IsStream(c *fiber.Ctx) bool {
+	return strings.Contains(c.Get(fiber.HeaderAccept), "text/event-stream") ||
+		strings.HasSuffix(c.Path(), "/events") ||
+		strings.HasSuffix(c.Path(), "/stream")
+}
+
+// Stream mengirim nilai dari channel ke klien sampai channel ditutup atau klien
+// terputus. name menentukan nama event untuk setiap nilai, dan unsubscribe
+// dipanggil setelah stream berakhir. Nilai yang mengimplementasikan Identified
+// dikirim beserta ID event-nya.
+func Stream[T any](c *fiber.Ctx, events <-chan T, name func(T) string, unsubscribe func()) error {
+	c.Set(fiber.HeaderContentType, "text/event-stream")
+	c.Set(fiber.HeaderCacheControl, "no-cache")
+	c.Set(fiber.HeaderConnection, "keep-alive")
+	c.Set("X-Accel-Buffering", "no") // Matikan buffering di reverse proxy seperti nginx
+
+	// WriteTimeout server berlaku untuk seluruh respons, sehingga stream akan diputus
+	// setelah timeout. Batas waktu tulis diperpanjang setiap kali event dikirim agar
+	// hanya klien yang berhenti membaca yang diputus.
+	conn := c.Context().Conn()
+	timeout := max(c.App().Config().WriteTimeout, 2*keepAliveInterval)
+	extendDeadline := func() {
+		conn.SetWriteDeadline(time.Now().Add(timeout))
+	}
+
+	c.Context().SetBodyStreamWriter(func(w *bufio.Writer) {
+		defer unsubscribe()
+
+		extendDeadline()
+		fmt.Fprintf(w, "retry: %d\n\n", retryMillis)
+		if err := w.Flush(); err != nil {
+			return
+		}
+
+		ticker := time.NewTicker(keepAliveInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case value, ok := <-events:
+				if !ok {
+					return
+				}
+				evt := Event{Name: name(value), Data: value}
+				if v, ok := any(value).(Identified); ok {
+					evt.ID = v.EventID()
+				}
+				extendDeadline()
+				if err := Write(w, evt); err != nil {
+					return
+				}
+			case <-ticker.C:
+				// Error saat flush berarti klien sudah terputus
+				extendDeadline()
+				fmt.Fprint(w, ": keep-alive\n\n")
+				if err := w.Flush(); err != nil {
+					return
+				}
+			}
+		}
+	})
+
+	return nil
+}
+
+// Write menulis satu event dalam format SSE lalu mengirimkannya ke klien
+func Write(w *bufio.Writer, evt Event) error {
+	data, err := json.Marshal(evt.Data)
+	if err != nil {
+		return fmt.Errorf("gagal encode event: %w", err)
+	}
+
+	if evt.ID != "" {
+		fmt.Fprintf(w, "id: %s\n", evt.ID)
+	}
+	if evt.Name != "" {
+		fmt.Fprintf(w, "event: %s\n", evt.Name)
+	}
+	fmt.Fprintf(w, "data: %s\n\n", data)
+
+	return w.Flush()
+}
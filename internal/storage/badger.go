@@ -3,6 +3,7 @@ package storage
 import (
 	"context"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
 	"time"
@@ -229,8 +230,88 @@ func (s *BadgerStorage) DeleteWithPrefix(ctx context.Context, prefix string) err
 	})
 }
 
+// SetBatch menyimpan beberapa key-value sekaligus dalam satu transaksi
+func (s *BadgerStorage) SetBatch(ctx context.Context, entries map[string][]byte) error {
+	return s.db.Update(func(txn *badger.Txn) error {
+		for key, value := range entries {
+			if err := txn.SetEntry(badger.NewEntry([]byte(key), value)); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// DeleteBatch menghapus beberapa key sekaligus, dipecah otomatis menjadi beberapa transaksi jika perlu
+func (s *BadgerStorage) DeleteBatch(ctx context.Context, keys []string) error {
+	wb := s.db.NewWriteBatch()
+	defer wb.Cancel()
+
+	for _, key := range keys {
+		if err := wb.Delete([]byte(key)); err != nil {
+			return err
+		}
+	}
+	return wb.Flush()
+}
+
+// Iterate menelusuri key dengan prefix secara berurutan dan memanggil fn untuk setiap key
+func (s *BadgerStorage) Iterate(ctx context.Context, opts IterateOptions, fn func(key string, value []byte) (bool, error)) error {
+	return s.db.View(func(txn *badger.Txn) error {
+		iterOpts := badger.DefaultIteratorOptions
+		iterOpts.Prefix = []byte(opts.Prefix)
+		iterOpts.Reverse = opts.Reverse
+		iterOpts.PrefetchValues = !opts.KeysOnly
+
+		it := txn.NewIterator(iterOpts)
+		defer it.Close()
+
+		// Iterasi mundur tanpa Start dimulai dari key terbesar yang masih memiliki prefix
+		start := []byte(opts.Start)
+		if len(start) == 0 {
+			start = []byte(opts.Prefix)
+			if opts.Reverse {
+				start = append(start, 0xFF)
+			}
+		}
+
+		for it.Seek(start); it.Valid(); it.Next() {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+
+			item := it.Item()
+
+			var value []byte
+			if !opts.KeysOnly {
+				var err error
+				if value, err = item.ValueCopy(nil); err != nil {
+					return err
+				}
+			}
+
+			next, err := fn(string(item.Key()), value)
+			if err != nil {
+				return err
+			}
+			if !next {
+				return nil
+			}
+		}
+		return nil
+	})
+}
+
 // Close menutup storage dan membersihkan resource
 func (s *BadgerStorage) Close() error {
 	s.logger.Info("Menutup koneksi BadgerDB")
 	return s.db.Close()
 }
+
+// Backup menulis salinan lengkap database ke writer dalam format backup BadgerDB
+func (s *BadgerStorage) Backup(w io.Writer) error {
+	if _, err := s.db.Backup(w, 0); err != nil {
+		return fmt.Errorf("gagal membuat backup BadgerDB: %w", err)
+	}
+	return nil
+}
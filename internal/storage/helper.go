@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"strings"
 	"time"
 )
 
@@ -70,3 +71,26 @@ func (h *Helper) GetAllWithPrefix(ctx context.Context, subPrefix string) (map[st
 func (h *Helper) DeleteAllWithPrefix(ctx context.Context, subPrefix string) error {
 	return h.storage.DeleteWithPrefix(ctx, h.makeKey(subPrefix))
 }
+
+// Iterate menelusuri key dengan sub-prefix opts.Prefix secara berurutan. opts.Start juga
+// berupa sub-key, dan fn menerima key tanpa prefix helper.
+func (h *Helper) Iterate(ctx context.Context, opts IterateOptions, fn func(key string, value []byte) (bool, error)) error {
+	opts.Prefix = h.makeKey(opts.Prefix)
+	if opts.Start != "" {
+		opts.Start = h.makeKey(opts.Start)
+	}
+
+	prefix := h.prefix + ":"
+	return h.storage.Iterate(ctx, opts, func(key string, value []byte) (bool, error) {
+		return fn(strings.TrimPrefix(key, prefix), value)
+	})
+}
+
+// DeleteBatch menghapus beberapa key dengan sub-key yang diberikan sekaligus
+func (h *Helper) DeleteBatch(ctx context.Context, keys []string) error {
+	full := make([]string, len(keys))
+	for i, key := range keys {
+		full[i] = h.makeKey(key)
+	}
+	return h.storage.DeleteBatch(ctx, full)
+}
@@ -28,10 +28,33 @@ type Storage interface {
 	// DeleteWithPrefix menghapus semua key-value dengan prefix yang diberikan
 	DeleteWithPrefix(ctx context.Context, prefix string) error
 
+	// SetBatch menyimpan beberapa key-value sekaligus dalam satu transaksi
+	SetBatch(ctx context.Context, entries map[string][]byte) error
+
+	// DeleteBatch menghapus beberapa key sekaligus. Jumlah key boleh melebihi batas satu transaksi.
+	DeleteBatch(ctx context.Context, keys []string) error
+
+	// Iterate menelusuri key dengan prefix secara berurutan dan memanggil fn untuk
+	// setiap key. Penelusuran berhenti jika fn mengembalikan false atau error.
+	Iterate(ctx context.Context, opts IterateOptions, fn func(key string, value []byte) (bool, error)) error
+
 	// Close menutup storage dan membersihkan resource
 	Close() error
 }
 
+// IterateOptions adalah opsi penelusuran key dengan Iterate
+type IterateOptions struct {
+	// Prefix membatasi key yang ditelusuri
+	Prefix string
+	// Start adalah key awal penelusuran. Pada penelusuran mundur, Start adalah batas atas
+	// dan key terbesar yang <= Start menjadi key pertama. Kosong berarti dari ujung prefix.
+	Start string
+	// Reverse menelusuri dari key terbesar ke terkecil
+	Reverse bool
+	// KeysOnly tidak membaca value sehingga penelusuran lebih cepat, fn menerima value nil
+	KeysOnly bool
+}
+
 // StorageOptions adalah opsi untuk inisialisasi Storage
 type StorageOptions struct {
 	Path     string
@@ -121,6 +144,18 @@ func (s *NoOpStorage) DeleteWithPrefix(_ context.Context, _ string) error {
 	return nil
 }
 
+func (s *NoOpStorage) SetBatch(_ context.Context, _ map[string][]byte) error {
+	return nil
+}
+
+func (s *NoOpStorage) DeleteBatch(_ context.Context, _ []string) error {
+	return nil
+}
+
+func (s *NoOpStorage) Iterate(_ context.Context, _ IterateOptions, _ func(string, []byte) (bool, error)) error {
+	return nil
+}
+
 func (s *NoOpStorage) Close() error {
 	return nil
 }
@@ -7,6 +7,8 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/sirupsen/logrus"
 	"gopkg.in/natefinch/lumberjack.v2"
@@ -178,6 +180,20 @@ func Setup(cfg *LogConfig) error {
 	return nil
 }
 
+// SetLevel mengubah level log saat aplikasi berjalan
+func SetLevel(level string) error {
+	lvl, err := logrus.ParseLevel(level)
+	if err != nil {
+		return fmt.Errorf("level log tidak valid: %w", err)
+	}
+
+	if Log == nil {
+		Setup(nil)
+	}
+	Log.SetLevel(lvl)
+	return nil
+}
+
 // ForModule mengembalikan logger dengan nama modul yang konsisten
 func ForModule(module string) LogrusEntry {
 	if Log == nil {
@@ -256,8 +272,270 @@ func Fatal(msg string, fields ...Fields) {
 	}
 }
 
-// Close melakukan clean-up jika diperlukan
+// Close menyimpan sisa log yang masih antre ke storage lalu menghentikan penyimpanan log
 func Close() {
-	// Tidak diperlukan implementasi khusus karena logrus
-	// akan otomatis flush ke output
+	DisablePersistence()
+}
+
+const (
+	// persistBufferSize adalah jumlah entri log yang dapat antre sebelum disimpan
+	persistBufferSize = 1024
+
+	// persistFlushTimeout adalah batas waktu menyimpan sisa antrean saat penyimpanan dihentikan
+	persistFlushTimeout = 5 * time.Second
+)
+
+// LogEntry adalah entri log yang diteruskan hook penyimpanan ke LogWriter.
+// Level dan Source sudah dipetakan ke nama yang dipakai log tersimpan.
+type LogEntry struct {
+	Timestamp time.Time
+	Level     string
+	Source    string
+	Message   string
+	Data      map[string]interface{}
+}
+
+// LogWriter menyimpan entri log ke storage, diimplementasikan oleh service log
+type LogWriter interface {
+	WriteLog(entry LogEntry) error
+}
+
+// persistHook adalah hook logrus yang menyimpan entri log ke LogWriter.
+// Entri disimpan di goroutine terpisah agar logging tidak menunggu storage,
+// dan kegagalan menyimpan tidak ikut di-log sehingga tidak terjadi loop.
+type persistHook struct {
+	writer  LogWriter
+	mu      sync.RWMutex
+	level   logrus.Level
+	entries chan LogEntry
+	done    chan struct{}
+	closed  bool
+	dropped int
+}
+
+var (
+	persistMu sync.Mutex
+	persist   *persistHook
+)
+
+// EnablePersistence mulai menyimpan log dengan level minimum tertentu ke writer
+func EnablePersistence(writer LogWriter, level string) error {
+	lvl, err := logrus.ParseLevel(level)
+	if err != nil {
+		return fmt.Errorf("level log tidak valid: %w", err)
+	}
+
+	if Log == nil {
+		Setup(nil)
+	}
+
+	persistMu.Lock()
+	defer persistMu.Unlock()
+
+	if persist != nil {
+		return fmt.Errorf("penyimpanan log sudah aktif")
+	}
+
+	hook := &persistHook{
+		writer:  writer,
+		level:   lvl,
+		entries: make(chan LogEntry, persistBufferSize),
+		done:    make(chan struct{}),
+	}
+	go hook.run()
+
+	Log.AddHook(hook)
+	persist = hook
+	return nil
+}
+
+// SetPersistLevel mengubah level minimum log yang disimpan saat aplikasi berjalan
+func SetPersistLevel(level string) error {
+	lvl, err := logrus.ParseLevel(level)
+	if err != nil {
+		return fmt.Errorf("level log tidak valid: %w", err)
+	}
+
+	persistMu.Lock()
+	defer persistMu.Unlock()
+
+	if persist != nil {
+		persist.mu.Lock()
+		persist.level = lvl
+		persist.mu.Unlock()
+	}
+	return nil
+}
+
+// DisablePersistence berhenti menyimpan log dan menunggu sisa antrean tersimpan
+func DisablePersistence() {
+	persistMu.Lock()
+	hook := persist
+	persist = nil
+	persistMu.Unlock()
+
+	if hook == nil {
+		return
+	}
+
+	// Tandai hook tertutup sebelum antrean ditutup. Fire memeriksa tanda ini sambil memegang
+	// read lock, sehingga tidak ada Fire yang sedang berjalan mengirim ke channel tertutup.
+	hook.mu.Lock()
+	hook.closed = true
+	close(hook.entries)
+	hook.mu.Unlock()
+
+	// Lepas hook dari logger. Log.Hooks hanya dibaca melalui ReplaceHooks yang memegang
+	// lock logger, sehingga tidak berlomba dengan Fire atau AddHook.
+	hooks := make(logrus.LevelHooks)
+	for level, list := range Log.ReplaceHooks(make(logrus.LevelHooks)) {
+		for _, h := range list {
+			if h != hook {
+				hooks[level] = append(hooks[level], h)
+			}
+		}
+	}
+	Log.ReplaceHooks(hooks)
+
+	select {
+	case <-hook.done:
+	case <-time.After(persistFlushTimeout):
+		fmt.Fprintln(os.Stderr, "Waktu habis saat menyimpan sisa log ke storage")
+	}
+}
+
+// Levels mengimplementasikan logrus.Hook. Filter level dilakukan di Fire
+// karena level minimum dapat diubah saat aplikasi berjalan.
+func (h *persistHook) Levels() []logrus.Level {
+	return logrus.AllLevels
+}
+
+// Fire mengimplementasikan logrus.Hook
+func (h *persistHook) Fire(entry *logrus.Entry) error {
+	h.mu.RLock()
+	level, closed := h.level, h.closed
+	h.mu.RUnlock()
+
+	if closed || entry.Level > level {
+		return nil
+	}
+
+	log := toLogEntry(entry)
+
+	// Proses akan berhenti setelah log fatal, simpan langsung agar tidak hilang
+	if entry.Level <= logrus.FatalLevel {
+		return h.writer.WriteLog(log)
+	}
+
+	h.mu.RLock()
+	queued := false
+	if !h.closed {
+		select {
+		case h.entries <- log:
+			queued = true
+		default:
+		}
+	}
+	h.mu.RUnlock()
+
+	if !queued {
+		// Storage tidak mengimbangi laju log, lebih baik entri dibuang daripada memblokir aplikasi
+		h.mu.Lock()
+		h.dropped++
+		h.mu.Unlock()
+	}
+	return nil
+}
+
+// run menyimpan entri log dari antrean sampai antrean ditutup
+func (h *persistHook) run() {
+	defer close(h.done)
+
+	for log := range h.entries {
+		if err := h.writer.WriteLog(log); err != nil {
+			fmt.Fprintf(os.Stderr, "Gagal menyimpan log ke storage: %v\n", err)
+		}
+
+		h.mu.Lock()
+		dropped := h.dropped
+		h.dropped = 0
+		h.mu.Unlock()
+
+		if dropped > 0 {
+			fmt.Fprintf(os.Stderr, "%d entri log tidak disimpan karena antrean penuh\n", dropped)
+		}
+	}
+}
+
+// toLogEntry mengubah entri logrus menjadi LogEntry. Field module dipetakan ke
+// sumber log dan tidak ikut disimpan di Data.
+func toLogEntry(entry *logrus.Entry) LogEntry {
+	module := "system"
+	var data map[string]interface{}
+
+	for k, v := range entry.Data {
+		if k == "module" {
+			module = fmt.Sprintf("%s", v)
+			continue
+		}
+		if data == nil {
+			data = make(map[string]interface{}, len(entry.Data))
+		}
+		switch val := v.(type) {
+		case error:
+			data[k] = val.Error()
+		case string, bool, int, int64, uint, uint64, float64, time.Time, time.Duration:
+			data[k] = val
+		default:
+			data[k] = fmt.Sprintf("%+v", val)
+		}
+	}
+
+	if data != nil {
+		data["module"] = module
+	} else {
+		data = map[string]interface{}{"module": module}
+	}
+
+	return LogEntry{
+		Timestamp: entry.Time,
+		Level:     LogLevelOf(entry.Level),
+		Source:    LogSourceOf(module),
+		Message:   entry.Message,
+		Data:      data,
+	}
+}
+
+// LogLevelOf memetakan level logrus ke nama level log tersimpan
+func LogLevelOf(level logrus.Level) string {
+	switch level {
+	case logrus.TraceLevel, logrus.DebugLevel:
+		return "DEBUG"
+	case logrus.InfoLevel:
+		return "INFO"
+	case logrus.WarnLevel:
+		return "WARNING"
+	case logrus.ErrorLevel:
+		return "ERROR"
+	default:
+		return "FATAL"
+	}
+}
+
+// LogSourceOf memetakan nama modul dari ForModule ke nama sumber log tersimpan
+func LogSourceOf(module string) string {
+	switch {
+	case module == "client", module == "accounts", module == "webhook", module == "receipt",
+		strings.HasPrefix(module, "whatsapp"):
+		return "WHATSAPP"
+	case module == "api", module == "apikey",
+		strings.HasPrefix(module, "api-"), strings.HasPrefix(module, "handler-"):
+		return "API"
+	case module == "web", module == "auth-middleware":
+		return "WEB"
+	case module == "badger", module == "storage":
+		return "DATABASE"
+	default:
+		return "SYSTEM"
+	}
 }
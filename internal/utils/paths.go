@@ -10,6 +10,15 @@ import (
 var (
 	// ProjectRoot adalah path absolut ke direktori root proyek
 	ProjectRoot string
+
+	// DataDir adalah direktori data aplikasi (storage, sesi, data WhatsApp)
+	DataDir string
+
+	// ConfigDir adalah direktori file konfigurasi
+	ConfigDir string
+
+	// LogDir adalah direktori file log
+	LogDir string
 )
 
 func init() {
@@ -25,10 +34,35 @@ func init() {
 	// paths.go -> utils/ -> internal/ -> root/
 	ProjectRoot = filepath.Clean(filepath.Join(dir, "..", ".."))
 
+	// Binary yang dijalankan di luar source tree tidak memiliki direktori
+	// sumber, gunakan working directory sebagai gantinya
+	if !DirectoryExists(ProjectRoot) {
+		if wd, err := os.Getwd(); err == nil {
+			ProjectRoot = wd
+		}
+	}
+
 	// Override dengan environment variable jika ada
 	if envRoot := os.Getenv("APP_ROOT"); envRoot != "" {
 		ProjectRoot = envRoot
 	}
+
+	// Direktori data, config, dan log dapat dipindahkan secara terpisah dari root
+	DataDir = dirFromEnv("APP_DATA_DIR", "data")
+	ConfigDir = dirFromEnv("APP_CONFIG_DIR", "config")
+	LogDir = dirFromEnv("APP_LOG_DIR", "logs")
+}
+
+// dirFromEnv mengembalikan path dari environment variable, atau subdirektori
+// ProjectRoot jika variable tidak diisi
+func dirFromEnv(name, fallback string) string {
+	if dir := os.Getenv(name); dir != "" {
+		if abs, err := filepath.Abs(dir); err == nil {
+			return abs
+		}
+		return dir
+	}
+	return filepath.Join(ProjectRoot, fallback)
 }
 
 // GetProjectDir mengembalikan path absolut ke direktori proyek
@@ -63,9 +97,9 @@ func ResolvePath(path string) string {
 // EnsureProjectStructure memastikan struktur direktori proyek sudah benar
 func EnsureProjectStructure() error {
 	dirs := []string{
-		filepath.Join(ProjectRoot, "data"),
-		filepath.Join(ProjectRoot, "config"),
-		filepath.Join(ProjectRoot, "logs"),
+		DataDir,
+		ConfigDir,
+		LogDir,
 		filepath.Join(ProjectRoot, "tmp"),
 	}
 
@@ -0,0 +1,42 @@
+package web
+
+import (
+	"embed"
+	"io/fs"
+	"net/http"
+
+	"github.com/gwenziro/bot-notify/static"
+)
+
+// viewFS berisi template HTML yang di-embed ke binary
+//
+//go:embed view
+var viewFS embed.FS
+
+// ViewsFS mengembalikan file system template. Jika overrideDir diisi, template
+// dibaca dari disk (berguna saat development), selain itu dari binary.
+func ViewsFS(overrideDir string) http.FileSystem {
+	if overrideDir != "" {
+		return http.Dir(overrideDir)
+	}
+	return embeddedFS(viewFS, "view")
+}
+
+// StaticFS mengembalikan file system aset statis. Jika overrideDir diisi, aset
+// dibaca dari disk, selain itu dari binary.
+func StaticFS(overrideDir string) http.FileSystem {
+	if overrideDir != "" {
+		return http.Dir(overrideDir)
+	}
+	return http.FS(static.FS)
+}
+
+// embeddedFS mengembalikan subdirektori dari embed.FS sebagai http.FileSystem
+func embeddedFS(fsys embed.FS, dir string) http.FileSystem {
+	sub, err := fs.Sub(fsys, dir)
+	if err != nil {
+		// Hanya terjadi jika direktori tidak ikut di-embed saat build
+		panic(err)
+	}
+	return http.FS(sub)
+}
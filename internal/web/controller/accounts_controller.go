@@ -0,0 +1,36 @@
+package controller
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/gwenziro/bot-notify/internal/config"
+	"github.com/gwenziro/bot-notify/internal/service/whatsapp/client"
+	"github.com/gwenziro/bot-notify/internal/utils"
+)
+
+// AccountsController menangani halaman pengelolaan akun WhatsApp
+type AccountsController struct {
+	config   *config.Live
+	accounts *client.AccountManager
+	logger   utils.LogrusEntry
+}
+
+// NewAccountsController membuat instance baru AccountsController
+func NewAccountsController(cfg *config.Live, accounts *client.AccountManager, logger utils.LogrusEntry) *AccountsController {
+	return &AccountsController{
+		config:   cfg,
+		accounts: accounts,
+		logger:   logger.WithField("component", "accounts-controller"),
+	}
+}
+
+// AccountsPage menampilkan daftar akun WhatsApp beserta status koneksinya
+func (c *AccountsController) AccountsPage(ctx *fiber.Ctx) error {
+	c.logger.Debug("Rendering halaman akun WhatsApp")
+
+	return ctx.Render("dashboard/accounts", fiber.Map{
+		"Title":       "Akun WhatsApp",
+		"Description": "Kelola akun WhatsApp yang terhubung ke Bot Notify.",
+		"ActivePage":  "accounts", // Untuk highlight menu aktif di sidebar
+		"Accounts":    c.accounts.List(),
+	}, "layouts/dashboard")
+}
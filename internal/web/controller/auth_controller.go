@@ -18,7 +18,7 @@ import (
 
 // AuthController menangani autentikasi halaman web
 type AuthController struct {
-	config       *config.Config
+	config       *config.Live
 	whatsApp     *client.Client
 	logger       utils.LogrusEntry
 	sessionStore *session.Store
@@ -26,7 +26,7 @@ type AuthController struct {
 }
 
 // NewAuthController membuat instance baru AuthController
-func NewAuthController(cfg *config.Config, whatsClient *client.Client, sessionStore *session.Store, logger utils.LogrusEntry) *AuthController {
+func NewAuthController(cfg *config.Live, whatsClient *client.Client, sessionStore *session.Store, logger utils.LogrusEntry) *AuthController {
 	// Inisialisasi AuthController
 	return &AuthController{
 		config:       cfg,
@@ -43,7 +43,7 @@ func (c *AuthController) LoginPage(ctx *fiber.Ctx) error {
 	sess, err := c.sessionStore.Get(ctx)
 	if err == nil {
 		authToken := sess.Get("auth_token")
-		if authToken != nil && authToken.(string) == c.config.Auth.AccessToken {
+		if authToken != nil && authToken.(string) == c.config.Get().Auth.AccessToken {
 			// Redirect ke dashboard jika sudah login
 			return ctx.Redirect("/dashboard")
 		}
@@ -90,7 +90,7 @@ func (c *AuthController) LoginPage(ctx *fiber.Ctx) error {
 		"RedirectTo":  redirect,
 		"Error":       errorText,
 		"CsrfToken":   csrfToken,
-		"AccessToken": c.maskToken(c.config.Auth.AccessToken),
+		"AccessToken": maskToken(c.config.Get().Auth.AccessToken),
 	})
 }
 
@@ -142,7 +142,7 @@ func (c *AuthController) ProcessLogin(ctx *fiber.Ctx) error {
 	redirect := ctx.FormValue("redirect", "/dashboard")
 
 	// Validasi token
-	if token != c.config.Auth.AccessToken {
+	if token != c.config.Get().Auth.AccessToken {
 		// Catat percobaan gagal
 		attempts++
 		// Gunakan Set karena SetTTL tidak tersedia di interface Storage
@@ -188,8 +188,8 @@ func (c *AuthController) ProcessLogin(ctx *fiber.Ctx) error {
 			Name:     "auto_login",
 			Value:    token,
 			Path:     "/",
-			MaxAge:   c.config.Auth.CookieMaxAge,
-			Secure:   c.config.Server.BaseURL != "http://localhost:8080",
+			MaxAge:   c.config.Get().Auth.CookieMaxAge,
+			Secure:   c.config.Get().Server.BaseURL != "http://localhost:8080",
 			HTTPOnly: true,
 			SameSite: "Strict",
 		})
@@ -241,7 +241,7 @@ func (c *AuthController) Logout(ctx *fiber.Ctx) error {
 		Value:    "",
 		Path:     "/",
 		MaxAge:   -1,
-		Secure:   c.config.Server.BaseURL != "http://localhost:8080",
+		Secure:   c.config.Get().Server.BaseURL != "http://localhost:8080",
 		HTTPOnly: true,
 		SameSite: "Strict",
 	})
@@ -264,7 +264,7 @@ func generateRandomToken(length int) string {
 }
 
 // maskToken menyembunyikan sebagian besar token dengan * untuk keamanan
-func (c *AuthController) maskToken(token string) string {
+func maskToken(token string) string {
 	if len(token) <= 8 {
 		return "********"
 	}
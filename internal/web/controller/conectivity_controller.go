@@ -1,26 +1,30 @@
 package controller
 
 import (
+	"errors"
+	"net/url"
 	"time"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/gwenziro/bot-notify/internal/config"
 	"github.com/gwenziro/bot-notify/internal/service/whatsapp/client"
+	"github.com/gwenziro/bot-notify/internal/service/whatsapp/session"
+	"github.com/gwenziro/bot-notify/internal/sse"
 	"github.com/gwenziro/bot-notify/internal/utils"
 )
 
 // connectivityController menangani halaman QR code
 type ConnectivityController struct {
-	config   *config.Config
-	whatsApp *client.Client
+	config   *config.Live
+	accounts *client.AccountManager
 	logger   utils.LogrusEntry
 }
 
 // NewConnectivityController membuat instance baru ConnectivityController
-func NewConnectivityController(cfg *config.Config, whatsClient *client.Client, logger utils.LogrusEntry) *ConnectivityController {
+func NewConnectivityController(cfg *config.Live, accounts *client.AccountManager, logger utils.LogrusEntry) *ConnectivityController {
 	return &ConnectivityController{
 		config:   cfg,
-		whatsApp: whatsClient,
+		accounts: accounts,
 		logger:   logger.WithField("component", "qrcode-controller"),
 	}
 }
@@ -29,25 +33,22 @@ func NewConnectivityController(cfg *config.Config, whatsClient *client.Client, l
 func (c *ConnectivityController) ConnectivityPage(ctx *fiber.Ctx) error {
 	c.logger.Debug("Rendering halaman QR code")
 
+	wa, err := c.accounts.Get(ctx.Query("account"))
+	if err != nil {
+		return ctx.Redirect("/accounts")
+	}
+
 	// Dapatkan status koneksi
-	connected := c.whatsApp.GetConnectionState().IsConnected
+	connected := wa.GetConnectionState().IsConnected
 
-	// Dapatkan QR code jika tersedia
-	var qrImage string
-	var qrTimestamp time.Time
-	var qrAvailable bool
+	// QR code disimpan di memori, gambar dirender on demand lewat /connectivity/qr.svg
+	qrState := wa.SessionManager.GetQRHandler().Current()
+	qrAvailable := !connected && qrState.Available(time.Now())
 
-	// Jika tidak terhubung, coba ambil QR code
-	if !connected {
-		qrHandler := c.whatsApp.SessionManager.GetQRHandler()
-		if qrHandler != nil {
-			image, timestamp, err := qrHandler.GetQRCodeImage()
-			if err == nil && image != "" {
-				qrImage = image
-				qrTimestamp = timestamp
-				qrAvailable = true
-			}
-		}
+	// Akun default tidak membutuhkan query agar URL lama tetap berlaku
+	query := ""
+	if wa.Name() != client.DefaultAccount {
+		query = "?account=" + url.QueryEscape(wa.Name())
 	}
 
 	// Render dengan layout dashboard
@@ -55,12 +56,47 @@ func (c *ConnectivityController) ConnectivityPage(ctx *fiber.Ctx) error {
 		"Title":            "Konektivitas WhatsApp",
 		"Description":      "Halaman untuk menghubungkan WhatsApp Bot Notify.",
 		"ActivePage":       "connectivity", // Untuk highlight menu aktif di sidebar
+		"Account":          wa.Name(),
 		"IsConnected":      connected,
-		"QRCodeImage":      qrImage,
-		"QRCodeTime":       qrTimestamp,
+		"QRCodeTime":       qrState.CreatedAt,
+		"QRCodeExpiresAt":  qrState.ExpiresAt,
 		"QRCodeAvailable":  qrAvailable,
-		"RefreshInterval":  30, // Refresh setiap 30 detik
-		"QRCodeEndpoint":   "/api/qr/image",
-		"QRStatusEndpoint": "/api/qr/status",
+		"QRCodeEndpoint":   "/connectivity/qr.svg" + query,
+		"QREventsEndpoint": "/connectivity/events" + query,
 	}, "layouts/dashboard" /* Gunakan layout dashboard */)
 }
+
+// QRCodeSVG merender QR code yang berlaku sebagai SVG
+func (c *ConnectivityController) QRCodeSVG(ctx *fiber.Ctx) error {
+	wa, err := c.accounts.Get(ctx.Query("account"))
+	if err != nil {
+		return ctx.Status(fiber.StatusNotFound).SendString("Akun WhatsApp tidak ditemukan")
+	}
+
+	svg, err := wa.SessionManager.GetQRHandler().SVG()
+	if errors.Is(err, session.ErrNoQRCode) {
+		return ctx.Status(fiber.StatusNotFound).SendString("QR code kedaluwarsa atau tidak tersedia")
+	}
+	if err != nil {
+		c.logger.WithError(err).WithField("account", wa.Name()).Error("Gagal merender QR code")
+		return ctx.Status(fiber.StatusInternalServerError).SendString("Gagal merender QR code")
+	}
+
+	// QR code berganti sekitar setiap 20 detik, jangan disimpan di cache
+	ctx.Set(fiber.HeaderCacheControl, "no-store")
+	ctx.Set(fiber.HeaderContentType, "image/svg+xml")
+	return ctx.Send(svg)
+}
+
+// QREvents mengirim QR code baru, pairing berhasil, dan timeout ke halaman melalui Server-Sent Events
+func (c *ConnectivityController) QREvents(ctx *fiber.Ctx) error {
+	wa, err := c.accounts.Get(ctx.Query("account"))
+	if err != nil {
+		return ctx.Status(fiber.StatusNotFound).SendString("Akun WhatsApp tidak ditemukan")
+	}
+
+	events, unsubscribe := wa.SessionManager.GetQRHandler().Subscribe()
+	return sse.Stream(ctx, events, func(state session.QRState) string {
+		return state.Event
+	}, unsubscribe)
+}
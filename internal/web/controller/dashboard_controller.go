@@ -9,13 +9,13 @@ import (
 
 // DashboardController menangani halaman dashboard
 type DashboardController struct {
-	config   *config.Config
+	config   *config.Live
 	whatsApp *client.Client
 	logger   utils.LogrusEntry
 }
 
 // NewDashboardController membuat instance baru DashboardController
-func NewDashboardController(cfg *config.Config, whatsClient *client.Client, logger utils.LogrusEntry) *DashboardController {
+func NewDashboardController(cfg *config.Live, whatsClient *client.Client, logger utils.LogrusEntry) *DashboardController {
 	return &DashboardController{
 		config:   cfg,
 		whatsApp: whatsClient,
@@ -9,13 +9,13 @@ import (
 
 // HomeController menangani halaman beranda web
 type HomeController struct {
-	config   *config.Config
+	config   *config.Live
 	whatsApp *client.Client
 	logger   utils.LogrusEntry
 }
 
 // NewHomeController membuat instance baru HomeController
-func NewHomeController(cfg *config.Config, whatsClient *client.Client, logger utils.LogrusEntry) *HomeController {
+func NewHomeController(cfg *config.Live, whatsClient *client.Client, logger utils.LogrusEntry) *HomeController {
 	return &HomeController{
 		config:   cfg,
 		whatsApp: whatsClient,
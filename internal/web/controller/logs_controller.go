@@ -10,14 +10,14 @@ import (
 
 // LogsController menangani tampilan untuk halaman logs
 type LogsController struct {
-	config     *config.Config
+	config     *config.Live
 	whatsapp   *client.Client
 	logger     utils.LogrusEntry
 	logService *log.LogService
 }
 
 // NewLogsController membuat instance baru logs controller
-func NewLogsController(cfg *config.Config, whatsapp *client.Client, logService *log.LogService, logger utils.LogrusEntry) *LogsController {
+func NewLogsController(cfg *config.Live, whatsapp *client.Client, logService *log.LogService, logger utils.LogrusEntry) *LogsController {
 	return &LogsController{
 		config:     cfg,
 		whatsapp:   whatsapp,
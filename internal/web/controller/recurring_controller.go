@@ -0,0 +1,45 @@
+package controller
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/gwenziro/bot-notify/internal/config"
+	"github.com/gwenziro/bot-notify/internal/service/recurring"
+	"github.com/gwenziro/bot-notify/internal/service/whatsapp/client"
+	"github.com/gwenziro/bot-notify/internal/utils"
+)
+
+// RecurringController menangani halaman job pesan berulang
+type RecurringController struct {
+	config    *config.Live
+	whatsApp  *client.Client
+	scheduler *recurring.Scheduler
+	logger    utils.LogrusEntry
+}
+
+// NewRecurringController membuat instance baru RecurringController
+func NewRecurringController(cfg *config.Live, whatsClient *client.Client, scheduler *recurring.Scheduler, logger utils.LogrusEntry) *RecurringController {
+	return &RecurringController{
+		config:    cfg,
+		whatsApp:  whatsClient,
+		scheduler: scheduler,
+		logger:    logger.WithField("component", "recurring-controller"),
+	}
+}
+
+// RecurringPage menampilkan daftar job pesan berulang beserta jadwal berikutnya dan terakhir
+func (c *RecurringController) RecurringPage(ctx *fiber.Ctx) error {
+	c.logger.Debug("Rendering halaman pesan berulang")
+
+	jobs, err := c.scheduler.List()
+	if err != nil {
+		c.logger.WithError(err).Error("Gagal mengambil daftar job pesan berulang")
+	}
+
+	return ctx.Render("dashboard/recurring", fiber.Map{
+		"Title":       "Pesan Berulang",
+		"Description": "Jadwal notifikasi berulang berbasis cron.",
+		"ActivePage":  "recurring", // Untuk highlight menu aktif di sidebar
+		"Jobs":        jobs,
+		"Error":       err,
+	}, "layouts/dashboard")
+}
@@ -1,25 +1,43 @@
 package controller
 
 import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
 	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/middleware/session"
 	"github.com/gwenziro/bot-notify/internal/config"
 	"github.com/gwenziro/bot-notify/internal/service/whatsapp/client"
 	"github.com/gwenziro/bot-notify/internal/utils"
 )
 
+const (
+	// newTokenSessionKey adalah key sesi untuk token baru yang hanya ditampilkan sekali
+	newTokenSessionKey = "new_access_token"
+
+	// settingsErrorSessionKey adalah key sesi untuk pesan validasi form pengaturan
+	settingsErrorSessionKey = "settings_errors"
+)
+
 // SettingsController menangani halaman pengaturan
 type SettingsController struct {
-	config   *config.Config
-	whatsApp *client.Client
-	logger   utils.LogrusEntry
+	config       *config.Live
+	whatsApp     *client.Client
+	sessionStore *session.Store
+	logger       utils.LogrusEntry
 }
 
 // NewSettingsController membuat instance baru SettingsController
-func NewSettingsController(cfg *config.Config, whatsClient *client.Client, logger utils.LogrusEntry) *SettingsController {
+func NewSettingsController(cfg *config.Live, whatsClient *client.Client, sessionStore *session.Store, logger utils.LogrusEntry) *SettingsController {
 	return &SettingsController{
-		config:   cfg,
-		whatsApp: whatsClient,
-		logger:   logger.WithField("component", "settings-controller"),
+		config:       cfg,
+		whatsApp:     whatsClient,
+		sessionStore: sessionStore,
+		logger:       logger.WithField("component", "settings-controller"),
 	}
 }
 
@@ -30,51 +48,368 @@ func (c *SettingsController) SettingsPage(ctx *fiber.Ctx) error {
 	// Mendapatkan informasi untuk ditampilkan di halaman pengaturan
 	connectionInfo := c.whatsApp.GetConnectionInfo()
 
+	// Token baru hasil rotasi hanya ditampilkan sekali, lalu dihapus dari sesi
+	newToken := c.takeFlash(ctx, newTokenSessionKey)
+
+	var settingsErrors []string
+	if msg := c.takeFlash(ctx, settingsErrorSessionKey); msg != "" {
+		settingsErrors = strings.Split(msg, "\n")
+	}
+
+	// Form menampilkan pengaturan tersimpan, termasuk yang masih menunggu restart
+	cfg := c.config.Saved()
+	pendingRestart := c.config.PendingRestart()
+	pending := make(map[string]bool, len(pendingRestart))
+	for _, key := range pendingRestart {
+		pending[key] = true
+	}
+
+	// Pengaturan dari env atau flag ditampilkan read-only beserta sumbernya
+	overridden := make(map[string]string, len(cfg.Overridden()))
+	for _, key := range cfg.Overridden() {
+		overridden[key], _ = cfg.OverrideSource(key)
+	}
+
+	var previousTokenExpiresAt *time.Time
+	if cfg.Auth.PreviousTokenActive(time.Now()) {
+		previousTokenExpiresAt = &cfg.Auth.PreviousTokenExpiresAt
+	}
+
 	// Render dengan layout dashboard
 	return ctx.Render("dashboard/settings", fiber.Map{
 		"Title":          "Pengaturan - WhatsApp Bot Notify",
 		"Description":    "Konfigurasi sistem Bot Notify.",
 		"ActivePage":     "settings", // Untuk highlight menu aktif di sidebar
 		"ConnectionInfo": connectionInfo,
+		"NewToken":       newToken,
+		"SettingsErrors": settingsErrors,
+		"PendingRestart": pendingRestart,
+		"Pending":        pending,
+		"Overridden":     overridden,
 		"Config": fiber.Map{
-			"ServerHost":      c.config.Server.Host,
-			"ServerPort":      c.config.Server.Port,
-			"ServerBaseURL":   c.config.Server.BaseURL,
-			"MaxRetry":        c.config.WhatsApp.MaxRetry,
-			"RetryDelay":      c.config.WhatsApp.RetryDelay.Seconds(),
-			"IdleTimeout":     c.config.WhatsApp.IdleTimeout.Minutes(),
-			"TokenExpiry":     c.config.Auth.TokenExpiry.Hours(),
-			"LoggingLevel":    c.config.Logging.Level,
-			"LoggingMaxSize":  c.config.Logging.MaxSize,
-			"StorageType":     c.config.Storage.Type,
-			"StorageInMemory": c.config.Storage.InMemory,
+			"ServerHost":             cfg.Server.Host,
+			"ServerPort":             cfg.Server.Port,
+			"ServerBaseURL":          cfg.Server.BaseURL,
+			"MaxRetry":               cfg.WhatsApp.MaxRetry,
+			"RetryDelay":             cfg.WhatsApp.RetryDelay.Seconds(),
+			"IdleTimeout":            cfg.WhatsApp.IdleTimeout.Minutes(),
+			"TokenExpiry":            cfg.Auth.TokenExpiry.Hours(),
+			"CookieMaxAge":           cfg.Auth.CookieMaxAge / secondsPerDay,
+			"LoggingLevel":           cfg.Logging.Level,
+			"LoggingMaxSize":         cfg.Logging.MaxSize,
+			"StorageType":            cfg.Storage.Type,
+			"StorageInMemory":        cfg.Storage.InMemory,
+			"AccessToken":            maskToken(cfg.Auth.AccessToken),
+			"PreviousTokenExpiresAt": previousTokenExpiresAt,
+			"TokenRotationGrace":     cfg.Auth.RotationGrace().String(),
 		},
 		"Paths": fiber.Map{
-			"StoreDir":    c.config.WhatsApp.StoreDir,
-			"QrCodeDir":   c.config.WhatsApp.QrCodeDir,
-			"SessionDir":  c.config.Auth.SessionDir,
-			"StoragePath": c.config.Storage.Path,
-			"LogFile":     c.config.Logging.File,
+			"StoreDir":    cfg.WhatsApp.StoreDir,
+			"SessionDir":  cfg.Auth.SessionDir,
+			"StoragePath": cfg.Storage.Path,
+			"LogFile":     cfg.Logging.File,
 		},
 	}, "layouts/dashboard")
 }
 
-// UpdateSettings menerima pembaruan pengaturan dari form
+// UpdateSettings menerima pembaruan pengaturan dari form, menyimpannya ke file
+// konfigurasi, dan langsung menerapkan pengaturan yang bisa diubah saat berjalan
 func (c *SettingsController) UpdateSettings(ctx *fiber.Ctx) error {
 	c.logger.Info("Menerima permintaan pembaruan pengaturan")
 
-	// Di sini implementasi untuk memproses form pengaturan
-	// Untuk sekarang, kita hanya redirect kembali dengan pesan sukses
+	// Form pengaturan dikirim per bagian, hanya field yang dikirim yang diubah
+	form, errs := parseSettingsForm(ctx)
+	if len(errs) > 0 {
+		c.logger.Warn("Form pengaturan tidak valid", utils.Fields{"errors": errs})
+		c.setFlash(ctx, settingsErrorSessionKey, strings.Join(errs, "\n"))
+		return ctx.Redirect("/settings?update_error=true")
+	}
+
+	// Simpan ke file lebih dulu, konfigurasi yang berjalan baru diubah jika berhasil
+	changes, err := c.config.Save(func(cfg *config.Config) error {
+		form.apply(cfg)
+		return nil
+	})
+	if errors.Is(err, config.ErrOverridden) {
+		c.logger.Warn("Pengaturan dari env atau flag tidak dapat diubah", utils.Fields{"error": err.Error()})
+		c.setFlash(ctx, settingsErrorSessionKey, err.Error())
+		return ctx.Redirect("/settings?update_error=true")
+	}
+	if err != nil {
+		c.logger.WithError(err).Error("Gagal menyimpan pengaturan")
+		c.setFlash(ctx, settingsErrorSessionKey, "Gagal menyimpan file konfigurasi: "+err.Error())
+		return ctx.Redirect("/settings?update_error=true")
+	}
+
+	c.logger.Info("Pengaturan berhasil diperbarui", utils.Fields{
+		"changes":         len(changes),
+		"pending_restart": c.config.PendingRestart(),
+	})
 
 	return ctx.Redirect("/settings?updated=true")
 }
 
-// UpdateToken memperbarui token akses API
+// secondsPerDay dipakai untuk konversi masa berlaku cookie di form (hari) ke konfigurasi (detik)
+const secondsPerDay = 24 * 60 * 60
+
+// settingsForm berisi field form pengaturan. Field nil berarti tidak dikirim.
+type settingsForm struct {
+	ServerHost      *string
+	ServerPort      *int
+	BaseURL         *string
+	LoggingLevel    *string
+	MaxRetry        *int
+	RetryDelay      *time.Duration
+	IdleTimeout     *time.Duration
+	TokenExpiry     *time.Duration
+	CookieMaxAge    *int
+	StorageType     *string
+	StorageInMemory *bool
+}
+
+// parseSettingsForm membaca dan memvalidasi form pengaturan.
+// Semua kesalahan validasi dikembalikan sekaligus.
+func parseSettingsForm(ctx *fiber.Ctx) (*settingsForm, []string) {
+	form := &settingsForm{}
+	var errs []string
+
+	has := func(key string) bool {
+		return ctx.Request().PostArgs().Has(key)
+	}
+	value := func(key string) string {
+		return strings.TrimSpace(ctx.FormValue(key))
+	}
+	number := func(key, label string, min, max int) *int {
+		n, err := strconv.Atoi(value(key))
+		if err != nil || n < min || n > max {
+			errs = append(errs, fmt.Sprintf("%s harus berupa angka antara %d dan %d", label, min, max))
+			return nil
+		}
+		return &n
+	}
+	duration := func(key, label string, unit time.Duration, min int) *time.Duration {
+		n := number(key, label, min, 1<<20)
+		if n == nil {
+			return nil
+		}
+		d := time.Duration(*n) * unit
+		return &d
+	}
+
+	if has("server_host") {
+		if host := value("server_host"); host == "" {
+			errs = append(errs, "Host server harus diisi")
+		} else {
+			form.ServerHost = &host
+		}
+	}
+	if has("server_port") {
+		form.ServerPort = number("server_port", "Port server", 1, 65535)
+	}
+	if has("base_url") {
+		baseURL := strings.TrimRight(value("base_url"), "/")
+		u, err := url.Parse(baseURL)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			errs = append(errs, "URL dasar harus berupa URL http atau https yang valid")
+		} else {
+			form.BaseURL = &baseURL
+		}
+	}
+	if has("logging_level") {
+		switch level := strings.ToLower(value("logging_level")); level {
+		case "debug", "info", "warn", "error":
+			form.LoggingLevel = &level
+		default:
+			errs = append(errs, "Level log harus salah satu dari debug, info, warn, atau error")
+		}
+	}
+	if has("max_retry") {
+		form.MaxRetry = number("max_retry", "Maksimum percobaan koneksi", 0, 100)
+	}
+	if has("retry_delay") {
+		form.RetryDelay = duration("retry_delay", "Jeda antar percobaan", time.Second, 1)
+	}
+	if has("idle_timeout") {
+		form.IdleTimeout = duration("idle_timeout", "Timeout idle", time.Minute, 0)
+	}
+	if has("token_expiry") {
+		form.TokenExpiry = duration("token_expiry", "Masa berlaku token", time.Hour, 1)
+	}
+	if has("cookie_max_age") {
+		if days := number("cookie_max_age", "Masa berlaku cookie", 1, 365); days != nil {
+			seconds := *days * secondsPerDay
+			form.CookieMaxAge = &seconds
+		}
+	}
+	if has("storage_type") {
+		if storageType := value("storage_type"); storageType != "badger" {
+			errs = append(errs, fmt.Sprintf("Tipe storage tidak didukung: %s", storageType))
+		} else {
+			form.StorageType = &storageType
+		}
+
+		// Checkbox tidak dikirim jika tidak dicentang, jadi hanya dibaca bersama form storage
+		inMemory := value("in_memory") == "on"
+		form.StorageInMemory = &inMemory
+	}
+
+	return form, errs
+}
+
+// apply menyalin field form yang dikirim ke konfigurasi
+func (f *settingsForm) apply(cfg *config.Config) {
+	if f.ServerHost != nil {
+		cfg.Server.Host = *f.ServerHost
+	}
+	if f.ServerPort != nil {
+		cfg.Server.Port = *f.ServerPort
+	}
+	if f.BaseURL != nil {
+		cfg.Server.BaseURL = *f.BaseURL
+	}
+	if f.LoggingLevel != nil {
+		cfg.Logging.Level = *f.LoggingLevel
+	}
+	if f.MaxRetry != nil {
+		cfg.WhatsApp.MaxRetry = *f.MaxRetry
+	}
+	if f.RetryDelay != nil {
+		cfg.WhatsApp.RetryDelay = *f.RetryDelay
+	}
+	if f.IdleTimeout != nil {
+		cfg.WhatsApp.IdleTimeout = *f.IdleTimeout
+	}
+	if f.TokenExpiry != nil {
+		cfg.Auth.TokenExpiry = *f.TokenExpiry
+	}
+	if f.CookieMaxAge != nil {
+		cfg.Auth.CookieMaxAge = *f.CookieMaxAge
+	}
+	if f.StorageType != nil {
+		cfg.Storage.Type = *f.StorageType
+	}
+	if f.StorageInMemory != nil {
+		cfg.Storage.InMemory = *f.StorageInMemory
+	}
+}
+
+// UpdateToken merotasi token akses API. Token baru disimpan ke file konfigurasi,
+// token lama tetap diterima API selama masa tenggang, dan semua sesi web serta
+// cookie auto_login yang memakai token lama menjadi tidak valid.
 func (c *SettingsController) UpdateToken(ctx *fiber.Ctx) error {
 	c.logger.Info("Menerima permintaan pembaruan token API")
 
-	// Di sini implementasi untuk regenerate token API
-	// Untuk sekarang, kita hanya redirect kembali
+	// Token dari env atau flag tidak ditulis ke file, rotasi akan kembali ke token lama saat reload
+	if source, ok := c.config.Saved().OverrideSource("auth.access_token"); ok {
+		c.logger.Warn("Token API diatur dari env atau flag, rotasi ditolak", utils.Fields{"source": source})
+		return ctx.Redirect("/settings?token_error=true")
+	}
+
+	newToken := generateRandomToken(64)
+	if newToken == "" {
+		c.logger.Error("Gagal membuat token acak")
+		return ctx.Redirect("/settings?token_error=true")
+	}
+
+	// Token baru disimpan ke file lalu langsung berlaku; konfigurasi tidak berubah jika penyimpanan gagal
+	_, err := c.config.Save(func(cfg *config.Config) error {
+		cfg.Auth.RotateAccessToken(newToken, time.Now())
+		return nil
+	})
+	if err != nil {
+		c.logger.WithError(err).Error("Gagal menyimpan token baru")
+		return ctx.Redirect("/settings?token_error=true")
+	}
+	cfg := c.config.Get()
+
+	if err := c.resetSessions(ctx, newToken); err != nil {
+		c.logger.WithError(err).Warn("Gagal memperbarui sesi setelah rotasi token")
+	}
+
+	// Hapus cookie auto_login karena berisi token lama
+	ctx.Cookie(&fiber.Cookie{
+		Name:     "auto_login",
+		Value:    "",
+		Path:     "/",
+		MaxAge:   -1,
+		Secure:   cfg.Server.BaseURL != "http://localhost:8080",
+		HTTPOnly: true,
+		SameSite: "Strict",
+	})
+
+	c.logger.Info("Token API berhasil dirotasi", utils.Fields{
+		"ip":                ctx.IP(),
+		"previous_valid_to": cfg.Auth.PreviousTokenExpiresAt,
+	})
 
 	return ctx.Redirect("/settings?token_updated=true")
 }
+
+// resetSessions menghapus semua sesi web lalu membuat sesi baru untuk pengguna
+// yang melakukan rotasi, berisi token baru yang akan ditampilkan sekali
+func (c *SettingsController) resetSessions(ctx *fiber.Ctx, newToken string) error {
+	if c.sessionStore == nil {
+		return fmt.Errorf("session store belum diinisialisasi")
+	}
+
+	if err := c.sessionStore.Reset(); err != nil {
+		return fmt.Errorf("gagal menghapus sesi: %w", err)
+	}
+
+	sess, err := c.sessionStore.Get(ctx)
+	if err != nil {
+		return fmt.Errorf("gagal membuat sesi baru: %w", err)
+	}
+
+	sess.Set("auth_token", newToken)
+	sess.Set("authenticated", true)
+	sess.Set("last_activity_time", time.Now().Unix())
+	sess.Set("device_fingerprint", fmt.Sprintf("%s|%s", ctx.IP(), ctx.Get("User-Agent")))
+	sess.Set(newTokenSessionKey, newToken)
+
+	if err := sess.Save(); err != nil {
+		return fmt.Errorf("gagal menyimpan sesi baru: %w", err)
+	}
+	return nil
+}
+
+// setFlash menyimpan pesan sekali tampil di sesi
+func (c *SettingsController) setFlash(ctx *fiber.Ctx, key, value string) {
+	if c.sessionStore == nil {
+		return
+	}
+
+	sess, err := c.sessionStore.Get(ctx)
+	if err != nil {
+		c.logger.WithError(err).Warn("Gagal mendapatkan sesi")
+		return
+	}
+
+	sess.Set(key, value)
+	if err := sess.Save(); err != nil {
+		c.logger.WithError(err).Warn("Gagal menyimpan sesi")
+	}
+}
+
+// takeFlash mengambil pesan sekali tampil dari sesi lalu menghapusnya
+func (c *SettingsController) takeFlash(ctx *fiber.Ctx, key string) string {
+	if c.sessionStore == nil {
+		return ""
+	}
+
+	sess, err := c.sessionStore.Get(ctx)
+	if err != nil {
+		return ""
+	}
+
+	value, _ := sess.Get(key).(string)
+	if value == "" {
+		return ""
+	}
+
+	sess.Delete(key)
+	if err := sess.Save(); err != nil {
+		c.logger.WithError(err).Warn("Gagal menghapus data sekali tampil dari sesi")
+	}
+	return value
+}
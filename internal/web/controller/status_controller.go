@@ -9,13 +9,13 @@ import (
 
 // StatusController menangani halaman status
 type StatusController struct {
-	config   *config.Config
+	config   *config.Live
 	whatsApp *client.Client
 	logger   utils.LogrusEntry
 }
 
 // NewStatusController membuat instance baru StatusController
-func NewStatusController(cfg *config.Config, whatsClient *client.Client, logger utils.LogrusEntry) *StatusController {
+func NewStatusController(cfg *config.Live, whatsClient *client.Client, logger utils.LogrusEntry) *StatusController {
 	return &StatusController{
 		config:   cfg,
 		whatsApp: whatsClient,
@@ -12,13 +12,13 @@ import (
 
 // AuthMiddleware mengelola autentikasi untuk web UI
 type AuthMiddleware struct {
-	config       *config.Config
+	config       *config.Live
 	sessionStore *session.Store
 	logger       utils.LogrusEntry
 }
 
 // NewAuthMiddleware membuat instance baru AuthMiddleware
-func NewAuthMiddleware(cfg *config.Config, sessionStore *session.Store) *AuthMiddleware {
+func NewAuthMiddleware(cfg *config.Live, sessionStore *session.Store) *AuthMiddleware {
 	return &AuthMiddleware{
 		config:       cfg,
 		sessionStore: sessionStore,
@@ -65,9 +65,10 @@ func (m *AuthMiddleware) RequireAuth() fiber.Handler {
 			if autoLoginToken != "" {
 				m.logger.Debug("Found auto-login cookie, attempting auto-login")
 
-				if m.secureCompare(autoLoginToken, m.config.Auth.AccessToken) {
+				accessToken := m.config.Get().Auth.AccessToken
+				if m.secureCompare(autoLoginToken, accessToken) {
 					// Auto-login berhasil, set session
-					sess.Set("auth_token", m.config.Auth.AccessToken)
+					sess.Set("auth_token", accessToken)
 					sess.Set("authenticated", true)
 					sess.Set("last_activity_time", time.Now().Unix())
 
@@ -113,7 +114,7 @@ func (m *AuthMiddleware) RequireAuth() fiber.Handler {
 
 		// Verifikasi token dengan secure compare
 		token, ok := authToken.(string)
-		if !ok || !m.secureCompare(token, m.config.Auth.AccessToken) {
+		if !ok || !m.secureCompare(token, m.config.Get().Auth.AccessToken) {
 			m.logger.Warn("Invalid token", utils.Fields{
 				"path":       c.Path(),
 				"ip":         c.IP(),
@@ -262,13 +263,14 @@ func (m *AuthMiddleware) addSecurityHeaders(c *fiber.Ctx) {
 
 // SetAutoLogin menetapkan cookie auto-login
 func (m *AuthMiddleware) SetAutoLogin(c *fiber.Ctx, enabled bool) {
+	cfg := m.config.Get()
 	if enabled {
 		c.Cookie(&fiber.Cookie{
 			Name:     "auto_login",
-			Value:    m.config.Auth.AccessToken,
+			Value:    cfg.Auth.AccessToken,
 			Path:     "/",
-			MaxAge:   m.config.Auth.CookieMaxAge,
-			Secure:   m.config.Server.BaseURL != "http://localhost:8080",
+			MaxAge:   cfg.Auth.CookieMaxAge,
+			Secure:   cfg.Server.BaseURL != "http://localhost:8080",
 			HTTPOnly: true,
 			SameSite: "Strict",
 		})
@@ -278,7 +280,7 @@ func (m *AuthMiddleware) SetAutoLogin(c *fiber.Ctx, enabled bool) {
 			Value:    "",
 			Path:     "/",
 			MaxAge:   -1,
-			Secure:   m.config.Server.BaseURL != "http://localhost:8080",
+			Secure:   cfg.Server.BaseURL != "http://localhost:8080",
 			HTTPOnly: true,
 			SameSite: "Strict",
 		})
@@ -2,6 +2,7 @@ package web
 
 import (
 	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/middleware/filesystem"
 	"github.com/gwenziro/bot-notify/internal/web/middleware"
 )
 
@@ -10,8 +11,15 @@ func (h *WebHandler) RegisterRoutes(app *fiber.App) {
 	// Initialize auth middleware
 	authMiddleware := middleware.NewAuthMiddleware(h.config, h.sessionStore)
 
-	// Serve static files
-	app.Static("/static", h.staticPath)
+	// Serve static files dari direktori override atau dari aset yang di-embed
+	if h.staticPath != "" {
+		app.Static("/static", h.staticPath)
+	} else {
+		app.Use("/static", filesystem.New(filesystem.Config{
+			Root:   StaticFS(""),
+			MaxAge: 3600,
+		}))
+	}
 
 	// Public routes
 	app.Get("/", h.homeController.HomePage)
@@ -28,6 +36,13 @@ func (h *WebHandler) RegisterRoutes(app *fiber.App) {
 	connectivity := app.Group("/connectivity")
 	connectivity.Use(authMiddleware.RequireAuth())
 	connectivity.Get("/", h.connectivityController.ConnectivityPage)
+	connectivity.Get("/qr.svg", h.connectivityController.QRCodeSVG)
+	connectivity.Get("/events", h.connectivityController.QREvents)
+
+	// Protected routes - Accounts
+	accountsPage := app.Group("/accounts")
+	accountsPage.Use(authMiddleware.RequireAuth())
+	accountsPage.Get("/", h.accountsController.AccountsPage)
 
 	// Protected routes - Status
 	status := app.Group("/status")
@@ -39,6 +54,11 @@ func (h *WebHandler) RegisterRoutes(app *fiber.App) {
 	logs.Use(authMiddleware.RequireAuth())
 	logs.Get("/", h.logsController.LogsPage)
 
+	// Protected routes - Recurring messages
+	recurringPage := app.Group("/recurring")
+	recurringPage.Use(authMiddleware.RequireAuth())
+	recurringPage.Get("/", h.recurringController.RecurringPage)
+
 	// Protected routes - Settings
 	settings := app.Group("/settings")
 	settings.Use(authMiddleware.RequireAuth())
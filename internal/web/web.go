@@ -1,11 +1,10 @@
 package web
 
 import (
-	"path/filepath"
-
 	"github.com/gofiber/fiber/v2/middleware/session"
 	"github.com/gwenziro/bot-notify/internal/config"
 	"github.com/gwenziro/bot-notify/internal/service/log"
+	"github.com/gwenziro/bot-notify/internal/service/recurring"
 	"github.com/gwenziro/bot-notify/internal/service/whatsapp/client"
 	"github.com/gwenziro/bot-notify/internal/utils"
 	"github.com/gwenziro/bot-notify/internal/web/controller"
@@ -13,8 +12,9 @@ import (
 
 // WebHandler menangani endpoint dan tampilan web
 type WebHandler struct {
-	config       *config.Config
+	config       *config.Live
 	whatsApp     *client.Client
+	accounts     *client.AccountManager
 	logger       utils.LogrusEntry
 	viewsPath    string
 	staticPath   string
@@ -28,35 +28,35 @@ type WebHandler struct {
 	settingsController     *controller.SettingsController
 	authController         *controller.AuthController
 	logsController         *controller.LogsController
+	recurringController    *controller.RecurringController
+	accountsController     *controller.AccountsController
 }
 
 // NewWebHandler membuat instance baru WebHandler
-func NewWebHandler(cfg *config.Config, whatsClient *client.Client, sessionStore *session.Store) *WebHandler {
-	// Sesuaikan path dengan struktur direktori baru
-	viewsPath := filepath.Join(utils.ProjectRoot, "internal", "web", "view")
-	staticPath := filepath.Join(utils.ProjectRoot, "static")
-
+func NewWebHandler(cfg *config.Live, accounts *client.AccountManager, sessionStore *session.Store, scheduler *recurring.Scheduler, logService *log.LogService) *WebHandler {
 	logger := utils.ForModule("web")
 
-	// Buat instance LogService
-	// Catatan: Dalam produksi, ini sebaiknya diinjeksi dari luar
-	logService := log.NewLogService(nil, utils.ForModule("log-service"))
+	// Halaman yang belum mendukung banyak akun menampilkan akun default
+	whatsClient := accounts.Default()
 
 	// Inisialisasi controller
 	homeController := controller.NewHomeController(cfg, whatsClient, logger)
 	statusController := controller.NewStatusController(cfg, whatsClient, logger)
-	connectivityController := controller.NewConnectivityController(cfg, whatsClient, logger)
+	connectivityController := controller.NewConnectivityController(cfg, accounts, logger)
 	dashboardController := controller.NewDashboardController(cfg, whatsClient, logger)
-	settingsController := controller.NewSettingsController(cfg, whatsClient, logger)
+	settingsController := controller.NewSettingsController(cfg, whatsClient, sessionStore, logger)
 	authController := controller.NewAuthController(cfg, whatsClient, sessionStore, logger)
 	logsController := controller.NewLogsController(cfg, whatsClient, logService, logger)
+	recurringController := controller.NewRecurringController(cfg, whatsClient, scheduler, logger)
+	accountsController := controller.NewAccountsController(cfg, accounts, logger)
 
 	return &WebHandler{
 		config:                 cfg,
 		whatsApp:               whatsClient,
+		accounts:               accounts,
 		logger:                 logger,
-		viewsPath:              viewsPath,
-		staticPath:             staticPath,
+		viewsPath:              cfg.Get().Server.ViewsDir,
+		staticPath:             cfg.Get().Server.StaticDir,
 		sessionStore:           sessionStore,
 		homeController:         homeController,
 		statusController:       statusController,
@@ -65,6 +65,8 @@ func NewWebHandler(cfg *config.Config, whatsClient *client.Client, sessionStore
 		settingsController:     settingsController,
 		authController:         authController,
 		logsController:         logsController,
+		recurringController:    recurringController,
+		accountsController:     accountsController,
 	}
 }
 
@@ -73,12 +75,12 @@ func (h *WebHandler) GetSessionStore() *session.Store {
 	return h.sessionStore
 }
 
-// GetViewsPath mengembalikan path direktori template
+// GetViewsPath mengembalikan path direktori override template, kosong jika memakai template yang di-embed
 func (h *WebHandler) GetViewsPath() string {
 	return h.viewsPath
 }
 
-// GetStaticPath mengembalikan path direktori aset statis
+// GetStaticPath mengembalikan path direktori override aset statis, kosong jika memakai aset yang di-embed
 func (h *WebHandler) GetStaticPath() string {
 	return h.staticPath
 }
@@ -87,6 +89,7 @@ func (h *WebHandler) GetStaticPath() string {
 func (h *WebHandler) SetSessionStore(store *session.Store) {
 	h.sessionStore = store
 
-	// Re-initialize auth controller with the new session store
+	// Re-initialize controllers that depend on the session store
 	h.authController = controller.NewAuthController(h.config, h.whatsApp, store, h.logger)
+	h.settingsController = controller.NewSettingsController(h.config, h.whatsApp, store, h.logger)
 }
@@ -0,0 +1,64 @@
+/**
+ * WhatsApp accounts page JavaScript
+ */
+document.addEventListener('DOMContentLoaded', function() {
+    const refreshBtn = document.getElementById('refresh-accounts');
+    if (refreshBtn) {
+        refreshBtn.addEventListener('click', () => window.location.reload());
+    }
+
+    document.getElementById('add-account-form')?.addEventListener('submit', addAccount);
+
+    document.querySelectorAll('.delete-account').forEach(btn => {
+        btn.addEventListener('click', () => deleteAccount(btn.dataset.name));
+    });
+});
+
+// Tambah akun lalu buka halaman konektivitas untuk menautkan perangkatnya
+async function addAccount(event) {
+    event.preventDefault();
+
+    const button = document.getElementById('add-account-btn');
+    const name = document.getElementById('account-name').value.trim();
+    button.disabled = true;
+
+    try {
+        const response = await apiRequest('/api/accounts', {
+            method: 'POST',
+            body: JSON.stringify({ name: name })
+        });
+        if (!response) return;
+
+        const data = await response.json();
+        if (!data.sukses) {
+            alert('Gagal menambah akun: ' + (data.error || data.pesan));
+            return;
+        }
+        window.location.href = '/connectivity?account=' + encodeURIComponent(data.data);
+    } catch (error) {
+        alert('Error: ' + error.message);
+    } finally {
+        button.disabled = false;
+    }
+}
+
+// Logout perangkat lalu hapus akun setelah konfirmasi
+async function deleteAccount(name) {
+    if (!confirm(`Apakah Anda yakin ingin menghapus akun ${name}? Perangkat akan di-logout dari WhatsApp.`)) {
+        return;
+    }
+
+    try {
+        const response = await apiRequest(`/api/accounts/${encodeURIComponent(name)}`, { method: 'DELETE' });
+        if (!response) return;
+
+        const data = await response.json();
+        if (!data.sukses) {
+            alert('Gagal menghapus akun: ' + (data.error || data.pesan));
+            return;
+        }
+        window.location.reload();
+    } catch (error) {
+        alert('Error: ' + error.message);
+    }
+}
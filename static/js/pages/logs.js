@@ -8,10 +8,16 @@ class LogsManager {
         // State
         this.logs = [];
         this.currentPage = 1;
+        this.cursor = null; // { before } or { after } for pages after the first
+        this.nextCursor = '';
+        this.prevCursor = '';
         this.totalPages = 0;
         this.totalLogs = 0;
         this.limit = 25;
         this.loading = false;
+        this.live = false;
+        this.eventSource = null;
+        this.streamUrl = '';
         this.filters = {
             level: 'all',
             source: 'all',
@@ -37,6 +43,7 @@ class LogsManager {
 
         // Buttons
         this.refreshBtn = document.getElementById('refresh-logs');
+        this.liveBtn = document.getElementById('live-logs');
         this.clearLogsBtn = document.getElementById('clear-logs');
         this.exportCsvBtn = document.getElementById('export-csv');
         this.exportJsonBtn = document.getElementById('export-json');
@@ -115,6 +122,14 @@ class LogsManager {
             });
         }
 
+        // Live tail toggle
+        if (this.liveBtn) {
+            this.liveBtn.addEventListener('click', () => {
+                this.setLive(!this.live);
+            });
+            window.addEventListener('beforeunload', () => this.stopLive());
+        }
+
         // Clear logs button
         if (this.clearLogsBtn) {
             this.clearLogsBtn.addEventListener('click', () => {
@@ -140,6 +155,7 @@ class LogsManager {
             this.prevPageBtn.addEventListener('click', () => {
                 if (this.currentPage > 1) {
                     this.currentPage--;
+                    this.cursor = { after: this.prevCursor };
                     this.loadLogs();
                 }
             });
@@ -149,6 +165,7 @@ class LogsManager {
             this.nextPageBtn.addEventListener('click', () => {
                 if (this.currentPage < this.totalPages) {
                     this.currentPage++;
+                    this.cursor = { before: this.nextCursor };
                     this.loadLogs();
                 }
             });
@@ -186,11 +203,22 @@ class LogsManager {
     async loadLogs() {
         this.setLoading(true);
 
+        // Filter stream ikut berubah bersama filter halaman
+        if (this.live) {
+            this.startLive();
+        }
+
         try {
-            const params = new URLSearchParams({
-                page: this.currentPage,
-                limit: this.limit
-            });
+            const params = new URLSearchParams({ limit: this.limit });
+
+            // Page through with the cursors from the previous response, the first page needs none
+            if (this.currentPage > 1 && this.cursor) {
+                if (this.cursor.before) params.append('before', this.cursor.before);
+                if (this.cursor.after) params.append('after', this.cursor.after);
+            } else {
+                this.currentPage = 1;
+                this.cursor = null;
+            }
 
             // Add filters if not default
             if (this.filters.level !== 'all') params.append('level', this.filters.level);
@@ -209,8 +237,13 @@ class LogsManager {
 
             if (data.success) {
                 this.logs = data.logs || [];
-                this.totalPages = data.totalPages || 0;
-                this.totalLogs = data.totalLogs || 0;
+                this.nextCursor = data.nextCursor || '';
+                this.prevCursor = data.prevCursor || '';
+                // Totals are only sent for the first page, cursor pages keep the previous ones
+                if (data.totalLogs !== undefined) {
+                    this.totalPages = data.totalPages || 0;
+                    this.totalLogs = data.totalLogs || 0;
+                }
 
                 this.renderLogs();
                 this.updatePagination();
@@ -295,6 +328,82 @@ class LogsManager {
         }
     }
 
+    // Turn the live tail on or off
+    setLive(enabled) {
+        this.live = enabled;
+
+        if (this.liveBtn) {
+            this.liveBtn.classList.toggle('btn-primary', enabled);
+            this.liveBtn.classList.toggle('btn-outline', !enabled);
+        }
+
+        if (enabled) {
+            this.startLive();
+        } else {
+            this.stopLive();
+        }
+    }
+
+    // Open the log stream with the level, source and search filters, reusing it if they did not change
+    startLive() {
+        const params = new URLSearchParams();
+        if (this.filters.level !== 'all') params.append('level', this.filters.level);
+        if (this.filters.source !== 'all') params.append('source', this.filters.source);
+        if (this.filters.search) params.append('search', this.filters.search);
+
+        const url = `/api/logs/stream?${params.toString()}`;
+        if (this.eventSource && this.streamUrl === url) return;
+
+        this.stopLive();
+        this.streamUrl = url;
+        this.eventSource = new EventSource(url);
+
+        this.eventSource.addEventListener('log', (e) => {
+            const data = JSON.parse(e.data);
+            if (data.log) {
+                this.prependLog(data.log);
+            }
+        });
+
+        // Log terlewat karena koneksi lambat, muat ulang halaman pertama
+        this.eventSource.addEventListener('dropped', () => {
+            if (this.currentPage === 1) {
+                this.loadLogs();
+            }
+        });
+    }
+
+    // Close the log stream
+    stopLive() {
+        if (this.eventSource) {
+            this.eventSource.close();
+            this.eventSource = null;
+        }
+        this.streamUrl = '';
+    }
+
+    // Show a streamed log at the top of the first page
+    prependLog(log) {
+        // Stream tidak mengenal filter tanggal, log di luar rentang tidak ditampilkan
+        if (this.filters.dateTo && log.timestamp.slice(0, 10) > this.filters.dateTo) return;
+
+        this.totalLogs++;
+        this.totalPages = Math.ceil(this.totalLogs / this.limit);
+
+        // Halaman lain tetap diam agar tidak bergeser saat sedang dibaca
+        if (this.currentPage === 1) {
+            this.logs.unshift(log);
+            if (this.logs.length > this.limit) {
+                this.logs.length = this.limit;
+            }
+            this.renderLogs();
+        } else if (this.logCount) {
+            this.logCount.textContent = this.totalLogs;
+        }
+
+        this.updatePagination();
+    }
+
     // Refresh logs with animation
     refreshLogs() {
         this.refreshBtn.querySelector('i').classList.add('fa-spin');
@@ -0,0 +1,74 @@
+/**
+ * Recurring messages page JavaScript
+ */
+document.addEventListener('DOMContentLoaded', function() {
+    const refreshBtn = document.getElementById('refresh-jobs');
+    if (refreshBtn) {
+        refreshBtn.addEventListener('click', () => window.location.reload());
+    }
+
+    document.querySelectorAll('.toggle-job').forEach(btn => {
+        btn.addEventListener('click', () => toggleJob(JSON.parse(btn.dataset.job)));
+    });
+
+    document.querySelectorAll('.delete-job').forEach(btn => {
+        btn.addEventListener('click', () => deleteJob(btn.dataset.id));
+    });
+});
+
+// Aktifkan atau nonaktifkan job dengan mengirim ulang definisinya
+async function toggleJob(job) {
+    const body = {
+        name: job.name,
+        schedule: job.schedule,
+        timezone: job.timezone,
+        message: job.message,
+        account: job.account,
+        enabled: !job.enabled
+    };
+
+    // Recipient disimpan sebagai JID, kirim kembali sesuai tipenya
+    if (job.type === 'group') {
+        body.groupID = job.recipient;
+    } else {
+        body.phoneNumber = job.recipient.split('@')[0];
+    }
+
+    try {
+        const response = await apiRequest(`/api/recurring/${job.id}`, {
+            method: 'PUT',
+            body: JSON.stringify(body)
+        });
+        if (!response) return;
+
+        const data = await response.json();
+        if (!data.sukses) {
+            alert('Gagal mengubah job: ' + (data.error || data.pesan));
+            return;
+        }
+        window.location.reload();
+    } catch (error) {
+        alert('Error: ' + error.message);
+    }
+}
+
+// Hapus job setelah konfirmasi
+async function deleteJob(id) {
+    if (!confirm('Apakah Anda yakin ingin menghapus job ini?')) {
+        return;
+    }
+
+    try {
+        const response = await apiRequest(`/api/recurring/${id}`, { method: 'DELETE' });
+        if (!response) return;
+
+        const data = await response.json();
+        if (!data.sukses) {
+            alert('Gagal menghapus job: ' + (data.error || data.pesan));
+            return;
+        }
+        window.location.reload();
+    } catch (error) {
+        alert('Error: ' + error.message);
+    }
+}
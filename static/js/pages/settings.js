@@ -10,6 +10,15 @@ document.addEventListener('DOMContentLoaded', function() {
     if (urlParams.get('token_updated') === 'true') {
         showSuccessMessage('token-update-success');
     }
+    if (urlParams.get('token_error') === 'true') {
+        showSuccessMessage('token-update-error');
+    }
+    
+    // Gunakan token baru untuk API calls setelah rotasi
+    const newTokenAlert = document.getElementById('new-token-alert');
+    if (newTokenAlert && newTokenAlert.dataset.newToken) {
+        localStorage.setItem('access_token', newTokenAlert.dataset.newToken);
+    }
     
     // Initialize the Bootstrap tabs
     initTabs();
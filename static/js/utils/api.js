@@ -91,9 +91,14 @@ async function getConnectionStatus() {
     }
 }
 
-async function reconnectWhatsApp() {
+// Build the ?account= query for accounts other than the default one
+function accountQuery(account) {
+    return account && account !== 'default' ? '?account=' + encodeURIComponent(account) : '';
+}
+
+async function reconnectWhatsApp(account) {
     try {
-        const response = await apiRequest('/api/reconnect', {
+        const response = await apiRequest('/api/reconnect' + accountQuery(account), {
             method: 'POST'
         });
         if (response && response.ok) {
@@ -106,9 +111,9 @@ async function reconnectWhatsApp() {
     }
 }
 
-async function disconnectWhatsApp() {
+async function disconnectWhatsApp(account) {
     try {
-        const response = await apiRequest('/api/disconnect', {
+        const response = await apiRequest('/api/disconnect' + accountQuery(account), {
             method: 'POST'
         });
         if (response && response.ok) {
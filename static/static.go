@@ -0,0 +1,9 @@
+// Package static berisi aset statis (CSS dan JavaScript) yang di-embed ke binary
+package static
+
+import "embed"
+
+// FS berisi semua aset statis, dilayani di bawah /static
+//
+//go:embed all:css all:js
+var FS embed.FS